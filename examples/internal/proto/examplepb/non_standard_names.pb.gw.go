@@ -190,7 +190,7 @@ func RegisterNonStandardServiceHandlerServer(ctx context.Context, mux *runtime.S
 
 		forward_NonStandardService_Update_0(ctx, mux, outboundMarshaler, w, req, resp, mux.GetForwardResponseOptions()...)
 
-	})
+	}, runtime.WithMessageTypes("grpc.gateway.examples.internal.examplepb.NonStandardUpdateRequest", "grpc.gateway.examples.internal.examplepb.NonStandardMessage"))
 
 	mux.Handle("PATCH", pattern_NonStandardService_UpdateWithJSONNames_0, func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {
 		ctx, cancel := context.WithCancel(req.Context())
@@ -210,7 +210,7 @@ func RegisterNonStandardServiceHandlerServer(ctx context.Context, mux *runtime.S
 
 		forward_NonStandardService_UpdateWithJSONNames_0(ctx, mux, outboundMarshaler, w, req, resp, mux.GetForwardResponseOptions()...)
 
-	})
+	}, runtime.WithMessageTypes("grpc.gateway.examples.internal.examplepb.NonStandardWithJSONNamesUpdateRequest", "grpc.gateway.examples.internal.examplepb.NonStandardMessageWithJSONNames"))
 
 	return nil
 }
@@ -271,7 +271,7 @@ func RegisterNonStandardServiceHandlerClient(ctx context.Context, mux *runtime.S
 
 		forward_NonStandardService_Update_0(ctx, mux, outboundMarshaler, w, req, resp, mux.GetForwardResponseOptions()...)
 
-	})
+	}, runtime.WithMessageTypes("grpc.gateway.examples.internal.examplepb.NonStandardUpdateRequest", "grpc.gateway.examples.internal.examplepb.NonStandardMessage"))
 
 	mux.Handle("PATCH", pattern_NonStandardService_UpdateWithJSONNames_0, func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {
 		ctx, cancel := context.WithCancel(req.Context())
@@ -291,7 +291,7 @@ func RegisterNonStandardServiceHandlerClient(ctx context.Context, mux *runtime.S
 
 		forward_NonStandardService_UpdateWithJSONNames_0(ctx, mux, outboundMarshaler, w, req, resp, mux.GetForwardResponseOptions()...)
 
-	})
+	}, runtime.WithMessageTypes("grpc.gateway.examples.internal.examplepb.NonStandardWithJSONNamesUpdateRequest", "grpc.gateway.examples.internal.examplepb.NonStandardMessageWithJSONNames"))
 
 	return nil
 }
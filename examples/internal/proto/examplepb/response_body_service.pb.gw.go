@@ -251,7 +251,7 @@ func RegisterResponseBodyServiceHandlerServer(ctx context.Context, mux *runtime.
 
 		forward_ResponseBodyService_GetResponseBody_0(ctx, mux, outboundMarshaler, w, req, response_ResponseBodyService_GetResponseBody_0{resp}, mux.GetForwardResponseOptions()...)
 
-	})
+	}, runtime.WithMessageTypes("grpc.gateway.examples.internal.examplepb.ResponseBodyIn", "grpc.gateway.examples.internal.examplepb.ResponseBodyOut"), runtime.WithNoBodyBinding())
 
 	mux.Handle("GET", pattern_ResponseBodyService_ListResponseBodies_0, func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {
 		ctx, cancel := context.WithCancel(req.Context())
@@ -271,7 +271,7 @@ func RegisterResponseBodyServiceHandlerServer(ctx context.Context, mux *runtime.
 
 		forward_ResponseBodyService_ListResponseBodies_0(ctx, mux, outboundMarshaler, w, req, response_ResponseBodyService_ListResponseBodies_0{resp}, mux.GetForwardResponseOptions()...)
 
-	})
+	}, runtime.WithMessageTypes("grpc.gateway.examples.internal.examplepb.ResponseBodyIn", "grpc.gateway.examples.internal.examplepb.RepeatedResponseBodyOut"), runtime.WithNoBodyBinding())
 
 	mux.Handle("GET", pattern_ResponseBodyService_ListResponseStrings_0, func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {
 		ctx, cancel := context.WithCancel(req.Context())
@@ -291,14 +291,14 @@ func RegisterResponseBodyServiceHandlerServer(ctx context.Context, mux *runtime.
 
 		forward_ResponseBodyService_ListResponseStrings_0(ctx, mux, outboundMarshaler, w, req, response_ResponseBodyService_ListResponseStrings_0{resp}, mux.GetForwardResponseOptions()...)
 
-	})
+	}, runtime.WithMessageTypes("grpc.gateway.examples.internal.examplepb.ResponseBodyIn", "grpc.gateway.examples.internal.examplepb.RepeatedResponseStrings"), runtime.WithNoBodyBinding())
 
 	mux.Handle("GET", pattern_ResponseBodyService_GetResponseBodyStream_0, func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {
 		err := status.Error(codes.Unimplemented, "streaming calls are not yet supported in the in-process transport")
 		_, outboundMarshaler := runtime.MarshalerForRequest(mux, req)
 		runtime.HTTPError(ctx, mux, outboundMarshaler, w, req, err)
 		return
-	})
+	}, runtime.WithMessageTypes("grpc.gateway.examples.internal.examplepb.ResponseBodyIn", "grpc.gateway.examples.internal.examplepb.ResponseBodyOut"), runtime.WithNoBodyBinding())
 
 	return nil
 }
@@ -359,7 +359,7 @@ func RegisterResponseBodyServiceHandlerClient(ctx context.Context, mux *runtime.
 
 		forward_ResponseBodyService_GetResponseBody_0(ctx, mux, outboundMarshaler, w, req, response_ResponseBodyService_GetResponseBody_0{resp}, mux.GetForwardResponseOptions()...)
 
-	})
+	}, runtime.WithMessageTypes("grpc.gateway.examples.internal.examplepb.ResponseBodyIn", "grpc.gateway.examples.internal.examplepb.ResponseBodyOut"), runtime.WithNoBodyBinding())
 
 	mux.Handle("GET", pattern_ResponseBodyService_ListResponseBodies_0, func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {
 		ctx, cancel := context.WithCancel(req.Context())
@@ -379,7 +379,7 @@ func RegisterResponseBodyServiceHandlerClient(ctx context.Context, mux *runtime.
 
 		forward_ResponseBodyService_ListResponseBodies_0(ctx, mux, outboundMarshaler, w, req, response_ResponseBodyService_ListResponseBodies_0{resp}, mux.GetForwardResponseOptions()...)
 
-	})
+	}, runtime.WithMessageTypes("grpc.gateway.examples.internal.examplepb.ResponseBodyIn", "grpc.gateway.examples.internal.examplepb.RepeatedResponseBodyOut"), runtime.WithNoBodyBinding())
 
 	mux.Handle("GET", pattern_ResponseBodyService_ListResponseStrings_0, func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {
 		ctx, cancel := context.WithCancel(req.Context())
@@ -399,7 +399,7 @@ func RegisterResponseBodyServiceHandlerClient(ctx context.Context, mux *runtime.
 
 		forward_ResponseBodyService_ListResponseStrings_0(ctx, mux, outboundMarshaler, w, req, response_ResponseBodyService_ListResponseStrings_0{resp}, mux.GetForwardResponseOptions()...)
 
-	})
+	}, runtime.WithMessageTypes("grpc.gateway.examples.internal.examplepb.ResponseBodyIn", "grpc.gateway.examples.internal.examplepb.RepeatedResponseStrings"), runtime.WithNoBodyBinding())
 
 	mux.Handle("GET", pattern_ResponseBodyService_GetResponseBodyStream_0, func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {
 		ctx, cancel := context.WithCancel(req.Context())
@@ -422,7 +422,7 @@ func RegisterResponseBodyServiceHandlerClient(ctx context.Context, mux *runtime.
 			return response_ResponseBodyService_GetResponseBodyStream_0{res}, err
 		}, mux.GetForwardResponseOptions()...)
 
-	})
+	}, runtime.WithMessageTypes("grpc.gateway.examples.internal.examplepb.ResponseBodyIn", "grpc.gateway.examples.internal.examplepb.ResponseBodyOut"), runtime.WithNoBodyBinding(), runtime.WithServerStreaming())
 
 	return nil
 }
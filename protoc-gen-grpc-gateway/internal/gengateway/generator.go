@@ -33,10 +33,11 @@ type generator struct {
 	registerFuncSuffix string
 	pathType           pathType
 	allowPatchFeature  bool
+	bulkRegistration   bool
 }
 
 // New returns a new generator which generates grpc gateway files.
-func New(reg *descriptor.Registry, useRequestContext bool, registerFuncSuffix, pathTypeString string, allowPatchFeature bool) gen.Generator {
+func New(reg *descriptor.Registry, useRequestContext bool, registerFuncSuffix, pathTypeString string, allowPatchFeature, bulkRegistration bool) gen.Generator {
 	var imports []descriptor.GoPackage
 	for _, pkgpath := range []string{
 		"context",
@@ -85,6 +86,7 @@ func New(reg *descriptor.Registry, useRequestContext bool, registerFuncSuffix, p
 		registerFuncSuffix: registerFuncSuffix,
 		pathType:           pathType,
 		allowPatchFeature:  allowPatchFeature,
+		bulkRegistration:   bulkRegistration,
 	}
 }
 
@@ -146,6 +148,7 @@ func (g *generator) generate(file *descriptor.File) (string, error) {
 		UseRequestContext:  g.useRequestContext,
 		RegisterFuncSuffix: g.registerFuncSuffix,
 		AllowPatchFeature:  g.allowPatchFeature,
+		BulkRegistration:   g.bulkRegistration,
 	}
 	return applyTemplate(params, g.reg)
 }
@@ -19,6 +19,7 @@ type param struct {
 	UseRequestContext  bool
 	RegisterFuncSuffix string
 	AllowPatchFeature  bool
+	BulkRegistration   bool
 }
 
 type binding struct {
@@ -146,6 +147,7 @@ type trailerParams struct {
 	UseRequestContext  bool
 	RegisterFuncSuffix string
 	AssumeColonVerb    bool
+	BulkRegistration   bool
 }
 
 func applyTemplate(p param, reg *descriptor.Registry) (string, error) {
@@ -204,6 +206,7 @@ func applyTemplate(p param, reg *descriptor.Registry) (string, error) {
 		UseRequestContext:  p.UseRequestContext,
 		RegisterFuncSuffix: p.RegisterFuncSuffix,
 		AssumeColonVerb:    assumeColonVerb,
+		BulkRegistration:   p.BulkRegistration,
 	}
 	// Local
 	if err := localTrailerTemplate.Execute(w, tp); err != nil {
@@ -318,6 +321,7 @@ var (
 	var protoReq {{.Method.RequestType.GoType .Method.Service.File.GoPkg.Path}}
 	var metadata runtime.ServerMetadata
 {{if .Body}}
+	{{- if and $AllowPatchFeature (eq (.HTTPMethod) "PATCH") (.FieldMaskField) (not (eq "*" .GetBodyFieldPath)) }}
 	newReader, berr := utilities.IOReaderFactory(req.Body)
 	if berr != nil {
 		return nil, metadata, status.Errorf(codes.InvalidArgument, "%v", berr)
@@ -325,7 +329,6 @@ var (
 	if err := marshaler.NewDecoder(newReader()).Decode(&{{.Body.AssignableExpr "protoReq"}}); err != nil && err != io.EOF  {
 		return nil, metadata, status.Errorf(codes.InvalidArgument, "%v", err)
 	}
-	{{- if and $AllowPatchFeature (eq (.HTTPMethod) "PATCH") (.FieldMaskField) (not (eq "*" .GetBodyFieldPath)) }}
 	if protoReq.{{.FieldMaskField}} == nil || len(protoReq.{{.FieldMaskField}}.GetPaths()) == 0 {
 			_, md := descriptor.ForMessage(protoReq.{{.GetBodyFieldStructName}})
 			if fieldMask, err := runtime.FieldMaskFromRequestBody(newReader(), md); err != nil {
@@ -334,7 +337,11 @@ var (
 				protoReq.{{.FieldMaskField}} = fieldMask
 			}
 	}
-	{{end}}
+	{{- else}}
+	if err := marshaler.NewDecoder(req.Body).Decode(&{{.Body.AssignableExpr "protoReq"}}); err != nil && err != io.EOF  {
+		return nil, metadata, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	{{- end}}
 {{end}}
 {{if .PathParams}}
 	var (
@@ -480,6 +487,7 @@ func local_request_{{.Method.Service.GetName}}_{{.Method.GetName}}_{{.Index}}(ct
 	var protoReq {{.Method.RequestType.GoType .Method.Service.File.GoPkg.Path}}
 	var metadata runtime.ServerMetadata
 {{if .Body}}
+	{{- if and $AllowPatchFeature (eq (.HTTPMethod) "PATCH") (.FieldMaskField) (not (eq "*" .GetBodyFieldPath)) }}
 	newReader, berr := utilities.IOReaderFactory(req.Body)
 	if berr != nil {
 		return nil, metadata, status.Errorf(codes.InvalidArgument, "%v", berr)
@@ -487,7 +495,6 @@ func local_request_{{.Method.Service.GetName}}_{{.Method.GetName}}_{{.Index}}(ct
 	if err := marshaler.NewDecoder(newReader()).Decode(&{{.Body.AssignableExpr "protoReq"}}); err != nil && err != io.EOF  {
 		return nil, metadata, status.Errorf(codes.InvalidArgument, "%v", err)
 	}
-	{{- if and $AllowPatchFeature (eq (.HTTPMethod) "PATCH") (.FieldMaskField) (not (eq "*" .GetBodyFieldPath)) }}
 	if protoReq.{{.FieldMaskField}} == nil || len(protoReq.{{.FieldMaskField}}.GetPaths()) == 0 {
 			_, md := descriptor.ForMessage(protoReq.{{.GetBodyFieldStructName}})
 			if fieldMask, err := runtime.FieldMaskFromRequestBody(newReader(), md); err != nil {
@@ -496,7 +503,11 @@ func local_request_{{.Method.Service.GetName}}_{{.Method.GetName}}_{{.Index}}(ct
 				protoReq.{{.FieldMaskField}} = fieldMask
 			}
 	}
-	{{end}}
+	{{- else}}
+	if err := marshaler.NewDecoder(req.Body).Decode(&{{.Body.AssignableExpr "protoReq"}}); err != nil && err != io.EOF  {
+		return nil, metadata, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	{{- end}}
 {{end}}
 {{if .PathParams}}
 	var (
@@ -573,7 +584,7 @@ func Register{{$svc.GetName}}{{$.RegisterFuncSuffix}}Server(ctx context.Context,
 		_, outboundMarshaler := runtime.MarshalerForRequest(mux, req)
 		runtime.HTTPError(ctx, mux, outboundMarshaler, w, req, err)
 		return
-	})
+	}, runtime.WithMessageTypes({{slice $m.RequestType.FQMN 1 | printf "%q"}}, {{slice $m.ResponseType.FQMN 1 | printf "%q"}}){{if not $b.Body}}, runtime.WithNoBodyBinding(){{end}})
 	{{else}}
 	mux.Handle({{$b.HTTPMethod | printf "%q"}}, pattern_{{$svc.GetName}}_{{$m.GetName}}_{{$b.Index}}, func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {
 	{{- if $UseRequestContext }}
@@ -600,7 +611,7 @@ func Register{{$svc.GetName}}{{$.RegisterFuncSuffix}}Server(ctx context.Context,
 		{{ else }}
 		forward_{{$svc.GetName}}_{{$m.GetName}}_{{$b.Index}}(ctx, mux, outboundMarshaler, w, req, resp, mux.GetForwardResponseOptions()...)
 		{{end}}
-	})
+	}, runtime.WithMessageTypes({{slice $m.RequestType.FQMN 1 | printf "%q"}}, {{slice $m.ResponseType.FQMN 1 | printf "%q"}}){{if not $b.Body}}, runtime.WithNoBodyBinding(){{end}})
 	{{end}}
 	{{end}}
 	{{end}}
@@ -648,9 +659,16 @@ func Register{{$svc.GetName}}{{$.RegisterFuncSuffix}}(ctx context.Context, mux *
 // doesn't go through the normal gRPC flow (creating a gRPC client etc.) then it will be up to the passed in
 // "{{$svc.GetName}}Client" to call the correct interceptors.
 func Register{{$svc.GetName}}{{$.RegisterFuncSuffix}}Client(ctx context.Context, mux *runtime.ServeMux, client {{$svc.GetName}}Client) error {
+	{{if $.BulkRegistration}}
+	return mux.HandleBulk([]runtime.Registration{
+	{{end}}
 	{{range $m := $svc.Methods}}
 	{{range $b := $m.Bindings}}
+	{{if $.BulkRegistration}}
+	{Method: {{$b.HTTPMethod | printf "%q"}}, Pattern: pattern_{{$svc.GetName}}_{{$m.GetName}}_{{$b.Index}}, Handler: func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {
+	{{- else}}
 	mux.Handle({{$b.HTTPMethod | printf "%q"}}, pattern_{{$svc.GetName}}_{{$m.GetName}}_{{$b.Index}}, func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {
+	{{- end}}
 	{{- if $UseRequestContext }}
 		ctx, cancel := context.WithCancel(req.Context())
 	{{- else -}}
@@ -685,10 +703,18 @@ func Register{{$svc.GetName}}{{$.RegisterFuncSuffix}}Client(ctx context.Context,
 		forward_{{$svc.GetName}}_{{$m.GetName}}_{{$b.Index}}(ctx, mux, outboundMarshaler, w, req, resp, mux.GetForwardResponseOptions()...)
 		{{end}}
 		{{end}}
-	})
+	{{if $.BulkRegistration}}
+	}, Options: []runtime.HandlerOption{runtime.WithMessageTypes({{slice $m.RequestType.FQMN 1 | printf "%q"}}, {{slice $m.ResponseType.FQMN 1 | printf "%q"}}){{if not $b.Body}}, runtime.WithNoBodyBinding(){{end}}{{if $m.GetServerStreaming}}, runtime.WithServerStreaming(){{end}}}},
+	{{else}}
+	}, runtime.WithMessageTypes({{slice $m.RequestType.FQMN 1 | printf "%q"}}, {{slice $m.ResponseType.FQMN 1 | printf "%q"}}){{if not $b.Body}}, runtime.WithNoBodyBinding(){{end}}{{if $m.GetServerStreaming}}, runtime.WithServerStreaming(){{end}})
 	{{end}}
 	{{end}}
+	{{end}}
+	{{if $.BulkRegistration}}
+	})
+	{{- else}}
 	return nil
+	{{- end}}
 }
 
 {{range $m := $svc.Methods}}
@@ -1,6 +1,7 @@
 package gengateway
 
 import (
+	"go/format"
 	"strings"
 	"testing"
 
@@ -230,7 +231,7 @@ func TestApplyTemplateRequestWithoutClientStreaming(t *testing.T) {
 		if want := spec.sigWant; !strings.Contains(got, want) {
 			t.Errorf("applyTemplate(%#v) = %s; want to contain %s", file, got, want)
 		}
-		if want := `marshaler.NewDecoder(newReader()).Decode(&protoReq.GetNested().Bool)`; !strings.Contains(got, want) {
+		if want := `marshaler.NewDecoder(req.Body).Decode(&protoReq.GetNested().Bool)`; !strings.Contains(got, want) {
 			t.Errorf("applyTemplate(%#v) = %s; want to contain %s", file, got, want)
 		}
 		if want := `val, ok = pathParams["nested.int32"]`; !strings.Contains(got, want) {
@@ -752,3 +753,339 @@ func TestIdentifierCapitalization(t *testing.T) {
 		t.Errorf("applyTemplate(%#v) = %s; want to contain %s", file, got, want)
 	}
 }
+
+func TestBulkRegistration(t *testing.T) {
+	msgdesc := &protodescriptor.DescriptorProto{
+		Name: proto.String("ExampleMessage"),
+	}
+	meth := &protodescriptor.MethodDescriptorProto{
+		Name:       proto.String("Example"),
+		InputType:  proto.String("ExampleMessage"),
+		OutputType: proto.String("ExampleMessage"),
+	}
+	svc := &protodescriptor.ServiceDescriptorProto{
+		Name:   proto.String("ExampleService"),
+		Method: []*protodescriptor.MethodDescriptorProto{meth},
+	}
+	msg := &descriptor.Message{
+		DescriptorProto: msgdesc,
+	}
+	file := descriptor.File{
+		FileDescriptorProto: &protodescriptor.FileDescriptorProto{
+			Name:        proto.String("example.proto"),
+			Package:     proto.String("example"),
+			MessageType: []*protodescriptor.DescriptorProto{msgdesc},
+			Service:     []*protodescriptor.ServiceDescriptorProto{svc},
+		},
+		GoPkg: descriptor.GoPackage{
+			Path: "example.com/path/to/example/example.pb",
+			Name: "example_pb",
+		},
+		Messages: []*descriptor.Message{msg},
+		Services: []*descriptor.Service{
+			{
+				ServiceDescriptorProto: svc,
+				Methods: []*descriptor.Method{
+					{
+						MethodDescriptorProto: meth,
+						RequestType:           msg,
+						ResponseType:          msg,
+						Bindings: []*descriptor.Binding{
+							{HTTPMethod: "GET"},
+							{HTTPMethod: "POST", Index: 1, Body: &descriptor.Body{FieldPath: nil}},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, bulkRegistration := range []bool{true, false} {
+		got, err := applyTemplate(param{File: crossLinkFixture(&file), RegisterFuncSuffix: "Handler", AllowPatchFeature: true, BulkRegistration: bulkRegistration}, descriptor.NewRegistry())
+		if err != nil {
+			t.Errorf("applyTemplate(%#v) failed with %v; want success", file, err)
+			return
+		}
+		if _, err := format.Source([]byte(got)); err != nil {
+			t.Errorf("format.Source(applyTemplate(%#v)) failed with %v; want success; source=%s", file, err, got)
+		}
+		for _, want := range []string{
+			"return mux.HandleBulk([]runtime.Registration{\n",
+			`{Method: "GET", Pattern: pattern_ExampleService_Example_0, Handler: func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {`,
+			`{Method: "POST", Pattern: pattern_ExampleService_Example_1, Handler: func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {`,
+		} {
+			if bulkRegistration && !strings.Contains(got, want) {
+				t.Errorf("applyTemplate(%#v) = %s; want to contain %s", file, got, want)
+			}
+			if !bulkRegistration && strings.Contains(got, want) {
+				t.Errorf("applyTemplate(%#v) = %s; want to _not_ contain %s", file, got, want)
+			}
+		}
+		// The in-process handlers are registered one by one regardless.
+		if want := `mux.Handle("GET", pattern_ExampleService_Example_0, func(w http.ResponseWriter, req *http.Request, pathParams map[string]string) {`; !strings.Contains(got, want) {
+			t.Errorf("applyTemplate(%#v) = %s; want to contain %s", file, got, want)
+		}
+	}
+}
+
+func TestApplyTemplateNoBodyBinding(t *testing.T) {
+	msgdesc := &protodescriptor.DescriptorProto{
+		Name: proto.String("ExampleMessage"),
+	}
+	meth := &protodescriptor.MethodDescriptorProto{
+		Name:       proto.String("Example"),
+		InputType:  proto.String("ExampleMessage"),
+		OutputType: proto.String("ExampleMessage"),
+	}
+	svc := &protodescriptor.ServiceDescriptorProto{
+		Name:   proto.String("ExampleService"),
+		Method: []*protodescriptor.MethodDescriptorProto{meth},
+	}
+	msg := &descriptor.Message{
+		DescriptorProto: msgdesc,
+	}
+	file := descriptor.File{
+		FileDescriptorProto: &protodescriptor.FileDescriptorProto{
+			Name:        proto.String("example.proto"),
+			Package:     proto.String("example"),
+			MessageType: []*protodescriptor.DescriptorProto{msgdesc},
+			Service:     []*protodescriptor.ServiceDescriptorProto{svc},
+		},
+		GoPkg: descriptor.GoPackage{
+			Path: "example.com/path/to/example/example.pb",
+			Name: "example_pb",
+		},
+		Messages: []*descriptor.Message{msg},
+		Services: []*descriptor.Service{
+			{
+				ServiceDescriptorProto: svc,
+				Methods: []*descriptor.Method{
+					{
+						MethodDescriptorProto: meth,
+						RequestType:           msg,
+						ResponseType:          msg,
+						Bindings: []*descriptor.Binding{
+							{HTTPMethod: "POST"},
+							{HTTPMethod: "POST", Index: 1, Body: &descriptor.Body{FieldPath: nil}},
+						},
+					},
+				},
+			},
+		},
+	}
+	// Only the in-process and the client handlers of the binding without body get the option.
+	for _, spec := range []struct {
+		bulkRegistration bool
+		want             []string
+	}{
+		{
+			bulkRegistration: false,
+			want:             []string{"}, runtime.WithMessageTypes(\"example.ExampleMessage\", \"example.ExampleMessage\"), runtime.WithNoBodyBinding())"},
+		},
+		{
+			bulkRegistration: true,
+			want: []string{
+				"}, runtime.WithMessageTypes(\"example.ExampleMessage\", \"example.ExampleMessage\"), runtime.WithNoBodyBinding())",
+				"}, Options: []runtime.HandlerOption{runtime.WithMessageTypes(\"example.ExampleMessage\", \"example.ExampleMessage\"), runtime.WithNoBodyBinding()}},",
+			},
+		},
+	} {
+		got, err := applyTemplate(param{File: crossLinkFixture(&file), RegisterFuncSuffix: "Handler", AllowPatchFeature: true, BulkRegistration: spec.bulkRegistration}, descriptor.NewRegistry())
+		if err != nil {
+			t.Errorf("applyTemplate(%#v) failed with %v; want success", file, err)
+			return
+		}
+		formatted, err := format.Source([]byte(got))
+		if err != nil {
+			t.Errorf("format.Source(applyTemplate(%#v)) failed with %v; want success; source=%s", file, err, got)
+			return
+		}
+		src := string(formatted)
+		if got, want := strings.Count(src, "runtime.WithNoBodyBinding()"), 2; got != want {
+			t.Errorf("BulkRegistration=%t: runtime.WithNoBodyBinding() is given %d times; want %d; source=%s", spec.bulkRegistration, got, want, src)
+		}
+		for _, want := range spec.want {
+			if !strings.Contains(src, want) {
+				t.Errorf("BulkRegistration=%t: applyTemplate(%#v) = %s; want to contain %s", spec.bulkRegistration, file, src, want)
+			}
+		}
+	}
+}
+
+func TestApplyTemplateServerStreaming(t *testing.T) {
+	msgdesc := &protodescriptor.DescriptorProto{
+		Name: proto.String("ExampleMessage"),
+	}
+	meth := &protodescriptor.MethodDescriptorProto{
+		Name:            proto.String("Example"),
+		InputType:       proto.String("ExampleMessage"),
+		OutputType:      proto.String("ExampleMessage"),
+		ServerStreaming: proto.Bool(true),
+	}
+	svc := &protodescriptor.ServiceDescriptorProto{
+		Name:   proto.String("ExampleService"),
+		Method: []*protodescriptor.MethodDescriptorProto{meth},
+	}
+	msg := &descriptor.Message{
+		DescriptorProto: msgdesc,
+	}
+	file := descriptor.File{
+		FileDescriptorProto: &protodescriptor.FileDescriptorProto{
+			Name:        proto.String("example.proto"),
+			Package:     proto.String("example"),
+			MessageType: []*protodescriptor.DescriptorProto{msgdesc},
+			Service:     []*protodescriptor.ServiceDescriptorProto{svc},
+		},
+		GoPkg: descriptor.GoPackage{
+			Path: "example.com/path/to/example/example.pb",
+			Name: "example_pb",
+		},
+		Messages: []*descriptor.Message{msg},
+		Services: []*descriptor.Service{
+			{
+				ServiceDescriptorProto: svc,
+				Methods: []*descriptor.Method{
+					{
+						MethodDescriptorProto: meth,
+						RequestType:           msg,
+						ResponseType:          msg,
+						Bindings: []*descriptor.Binding{
+							{HTTPMethod: "POST", Body: &descriptor.Body{FieldPath: nil}},
+							{HTTPMethod: "GET", Index: 1},
+						},
+					},
+				},
+			},
+		},
+	}
+	// The in-process handlers do not support streaming, so only the client handlers get the option.
+	for _, spec := range []struct {
+		bulkRegistration bool
+		want             []string
+	}{
+		{
+			bulkRegistration: false,
+			want: []string{
+				"}, runtime.WithMessageTypes(\"example.ExampleMessage\", \"example.ExampleMessage\"), runtime.WithServerStreaming())",
+				"}, runtime.WithMessageTypes(\"example.ExampleMessage\", \"example.ExampleMessage\"), runtime.WithNoBodyBinding(), runtime.WithServerStreaming())",
+			},
+		},
+		{
+			bulkRegistration: true,
+			want: []string{
+				"}, Options: []runtime.HandlerOption{runtime.WithMessageTypes(\"example.ExampleMessage\", \"example.ExampleMessage\"), runtime.WithServerStreaming()}},",
+				"}, Options: []runtime.HandlerOption{runtime.WithMessageTypes(\"example.ExampleMessage\", \"example.ExampleMessage\"), runtime.WithNoBodyBinding(), runtime.WithServerStreaming()}},",
+			},
+		},
+	} {
+		got, err := applyTemplate(param{File: crossLinkFixture(&file), RegisterFuncSuffix: "Handler", AllowPatchFeature: true, BulkRegistration: spec.bulkRegistration}, descriptor.NewRegistry())
+		if err != nil {
+			t.Errorf("applyTemplate(%#v) failed with %v; want success", file, err)
+			return
+		}
+		formatted, err := format.Source([]byte(got))
+		if err != nil {
+			t.Errorf("format.Source(applyTemplate(%#v)) failed with %v; want success; source=%s", file, err, got)
+			return
+		}
+		src := string(formatted)
+		if got, want := strings.Count(src, "runtime.WithServerStreaming()"), 2; got != want {
+			t.Errorf("BulkRegistration=%t: runtime.WithServerStreaming() is given %d times; want %d; source=%s", spec.bulkRegistration, got, want, src)
+		}
+		for _, want := range spec.want {
+			if !strings.Contains(src, want) {
+				t.Errorf("BulkRegistration=%t: applyTemplate(%#v) = %s; want to contain %s", spec.bulkRegistration, file, src, want)
+			}
+		}
+	}
+}
+
+func TestApplyTemplateMessageTypes(t *testing.T) {
+	reqdesc := &protodescriptor.DescriptorProto{
+		Name: proto.String("ExampleRequest"),
+	}
+	respdesc := &protodescriptor.DescriptorProto{
+		Name: proto.String("Response"),
+	}
+	meth := &protodescriptor.MethodDescriptorProto{
+		Name:       proto.String("Example"),
+		InputType:  proto.String("ExampleRequest"),
+		OutputType: proto.String("ExampleReply.Response"),
+	}
+	svc := &protodescriptor.ServiceDescriptorProto{
+		Name:   proto.String("ExampleService"),
+		Method: []*protodescriptor.MethodDescriptorProto{meth},
+	}
+	req := &descriptor.Message{
+		DescriptorProto: reqdesc,
+	}
+	resp := &descriptor.Message{
+		DescriptorProto: respdesc,
+		Outers:          []string{"ExampleReply"},
+	}
+	file := descriptor.File{
+		FileDescriptorProto: &protodescriptor.FileDescriptorProto{
+			Name:        proto.String("example.proto"),
+			Package:     proto.String("example"),
+			MessageType: []*protodescriptor.DescriptorProto{reqdesc},
+			Service:     []*protodescriptor.ServiceDescriptorProto{svc},
+		},
+		GoPkg: descriptor.GoPackage{
+			Path: "example.com/path/to/example/example.pb",
+			Name: "example_pb",
+		},
+		Messages: []*descriptor.Message{req, resp},
+		Services: []*descriptor.Service{
+			{
+				ServiceDescriptorProto: svc,
+				Methods: []*descriptor.Method{
+					{
+						MethodDescriptorProto: meth,
+						RequestType:           req,
+						ResponseType:          resp,
+						Bindings: []*descriptor.Binding{
+							{HTTPMethod: "POST", Body: &descriptor.Body{FieldPath: nil}},
+						},
+					},
+				},
+			},
+		},
+	}
+	const option = `runtime.WithMessageTypes("example.ExampleRequest", "example.ExampleReply.Response")`
+	for _, spec := range []struct {
+		bulkRegistration bool
+		want             []string
+	}{
+		{
+			bulkRegistration: false,
+			want:             []string{"}, " + option + ")"},
+		},
+		{
+			bulkRegistration: true,
+			want: []string{
+				"}, " + option + ")",
+				"}, Options: []runtime.HandlerOption{" + option + "}},",
+			},
+		},
+	} {
+		got, err := applyTemplate(param{File: crossLinkFixture(&file), RegisterFuncSuffix: "Handler", AllowPatchFeature: true, BulkRegistration: spec.bulkRegistration}, descriptor.NewRegistry())
+		if err != nil {
+			t.Errorf("applyTemplate(%#v) failed with %v; want success", file, err)
+			return
+		}
+		formatted, err := format.Source([]byte(got))
+		if err != nil {
+			t.Errorf("format.Source(applyTemplate(%#v)) failed with %v; want success; source=%s", file, err, got)
+			return
+		}
+		src := string(formatted)
+		// Both the in-process and the client handlers get the option.
+		if got, want := strings.Count(src, option), 2; got != want {
+			t.Errorf("BulkRegistration=%t: %s is given %d times; want %d; source=%s", spec.bulkRegistration, option, got, want, src)
+		}
+		for _, want := range spec.want {
+			if !strings.Contains(src, want) {
+				t.Errorf("BulkRegistration=%t: applyTemplate(%#v) = %s; want to contain %s", spec.bulkRegistration, file, src, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthorizationHandlerFunc validates the bearer token of a request. It returns the metadata to
+// pass to the gRPC server, e.g. the claims of the token, or an error to reject the request.
+type AuthorizationHandlerFunc func(ctx context.Context, token string) (metadata.MD, error)
+
+// WithAuthorizationHandler returns a ServeMuxOption that calls fn with the token of the requests
+// with an "Authorization: Bearer <token>" header, when the request is annotated with
+// AnnotateContext. Requests without such a header are not given to fn.
+//
+// The metadata returned by fn is added to the metadata of the request. If it has "authorization"
+// metadata, it replaces the header, e.g. to forward another token to the gRPC server. If fn returns
+// an error, the request is rejected: errors which are not gRPC errors are reported as
+// "Unauthenticated" errors.
+func WithAuthorizationHandler(fn AuthorizationHandlerFunc) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.authorizationHandler = fn
+	}
+}
+
+const bearerScheme = "bearer "
+
+// handleAuthorization calls fn with the bearer token of req, if any, and returns md with the
+// metadata returned by fn.
+func handleAuthorization(ctx context.Context, fn AuthorizationHandlerFunc, req *http.Request, md metadata.MD) (metadata.MD, error) {
+	auth := req.Header.Get("Authorization")
+	if len(auth) <= len(bearerScheme) || !strings.EqualFold(auth[:len(bearerScheme)], bearerScheme) {
+		return md, nil
+	}
+	authMD, err := fn(ctx, strings.TrimSpace(auth[len(bearerScheme):]))
+	if err != nil {
+		if _, ok := status.FromError(err); !ok {
+			err = status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, err
+	}
+	if _, ok := authMD["authorization"]; ok {
+		md = withoutMetadata(md, "authorization")
+	}
+	return metadata.Join(md, authMD), nil
+}
@@ -0,0 +1,96 @@
+package runtime_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithAuthorizationHandler(t *testing.T) {
+	handler := func(ctx context.Context, token string) (metadata.MD, error) {
+		switch token {
+		case "valid":
+			return metadata.Pairs("user-id", "alice"), nil
+		case "exchanged":
+			return metadata.Pairs("authorization", "Bearer internal"), nil
+		case "forbidden":
+			return nil, status.Error(codes.PermissionDenied, "forbidden")
+		default:
+			return nil, errors.New("invalid token")
+		}
+	}
+
+	for _, spec := range []struct {
+		name string
+		auth string
+
+		wantCode codes.Code
+		wantMD   map[string][]string
+	}{
+		{
+			name:   "valid token",
+			auth:   "Bearer valid",
+			wantMD: map[string][]string{"user-id": {"alice"}, "authorization": {"Bearer valid"}},
+		},
+		{
+			name:   "scheme case",
+			auth:   "bearer valid",
+			wantMD: map[string][]string{"user-id": {"alice"}, "authorization": {"bearer valid"}},
+		},
+		{
+			name:   "exchanged token",
+			auth:   "Bearer exchanged",
+			wantMD: map[string][]string{"authorization": {"Bearer internal"}},
+		},
+		{
+			name:     "invalid token",
+			auth:     "Bearer invalid",
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "gRPC error",
+			auth:     "Bearer forbidden",
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:   "missing header",
+			wantMD: map[string][]string{"user-id": nil, "authorization": nil},
+		},
+		{
+			name:   "other scheme",
+			auth:   "Basic dXNlcjpwYXNz",
+			wantMD: map[string][]string{"user-id": nil, "authorization": {"Basic dXNlcjpwYXNz"}},
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			r, err := http.NewRequest("GET", "http://example.com/foo", nil)
+			if err != nil {
+				t.Fatalf("http.NewRequest failed with %v; want success", err)
+			}
+			if spec.auth != "" {
+				r.Header.Set("Authorization", spec.auth)
+			}
+			mux := runtime.NewServeMux(runtime.WithAuthorizationHandler(handler))
+			ctx, err := runtime.AnnotateContext(context.Background(), mux, r)
+			if got, want := status.Code(err), spec.wantCode; got != want {
+				t.Fatalf("runtime.AnnotateContext failed with %v; want code %v", err, want)
+			}
+			if err != nil {
+				return
+			}
+			md, _ := metadata.FromOutgoingContext(ctx)
+			for key, want := range spec.wantMD {
+				if got := md.Get(key); !reflect.DeepEqual(got, want) {
+					t.Errorf("md.Get(%q) = %q; want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
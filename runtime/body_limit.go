@@ -0,0 +1,193 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errRequestBodyTooLarge is returned by the reads of request bodies beyond the limit set by
+// WithMaxRequestBodySize.
+var errRequestBodyTooLarge = errors.New("request body too large")
+
+// WithMaxRequestBodySize returns a ServeMuxOption that limits request bodies, also of
+// client-streaming calls, to n bytes. Reading beyond the limit fails, and the request is replied
+// with "413 Request Entity Too Large" and a "ResourceExhausted" error, through the error handler
+// of the ServeMux. The limit of 0, the default, means no limit.
+func WithMaxRequestBodySize(n int64) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.maxRequestBodySize = n
+	}
+}
+
+// WithRouteMaxBodySize returns a HandlerOption that limits the request bodies of the handler to
+// n bytes, like WithMaxRequestBodySize. It takes precedence over WithMaxRequestBodySize, e.g. to
+// allow large uploads to a single route.
+func WithRouteMaxBodySize(n int64) HandlerOption {
+	return func(o *handlerOptions) {
+		o.maxBodySize = n
+	}
+}
+
+// WithContentLengthValidation returns a ServeMuxOption that checks that request bodies have
+// the length declared by their Content-Length header, if any. Reading a body beyond the declared
+// length, or reaching its end before, fails, and the generated handlers reply with an
+// "InvalidArgument" error, so that malformed or truncated uploads are rejected early. A short
+// body is only caught if it is read to its end.
+func WithContentLengthValidation() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.validateContentLength = true
+	}
+}
+
+// contentLengthBody is a request body which fails to be read if its length is not the declared one.
+type contentLengthBody struct {
+	io.ReadCloser
+
+	length    int64
+	read      int64
+	remaining int64
+	err       error
+}
+
+func (b *contentLengthBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		b.err = fmt.Errorf("request body is longer than its Content-Length of %d bytes", b.length)
+		return n, b.err
+	}
+	b.remaining -= int64(n)
+	if err == io.EOF && b.remaining > 0 {
+		b.err = fmt.Errorf("request body has %d bytes but its Content-Length is %d", b.read, b.length)
+		return n, b.err
+	}
+	return n, err
+}
+
+// checkContentLength returns r with its body checked against its Content-Length if
+// WithContentLengthValidation is set.
+func (s *ServeMux) checkContentLength(r *http.Request) *http.Request {
+	if !s.validateContentLength || r.ContentLength < 0 || r.Body == nil || r.Body == http.NoBody {
+		return r
+	}
+	r.Body = &contentLengthBody{ReadCloser: r.Body, length: r.ContentLength, remaining: r.ContentLength}
+	return r
+}
+
+// limitedBody is a request body which fails to be read beyond a limit.
+type limitedBody struct {
+	io.ReadCloser
+
+	limit     int64
+	remaining int64
+	exceeded  bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, errRequestBodyTooLarge
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) <= b.remaining {
+		b.remaining -= int64(n)
+		return n, err
+	}
+	n = int(b.remaining)
+	b.remaining = 0
+	b.exceeded = true
+	return n, errRequestBodyTooLarge
+}
+
+// limitRequestBody returns r with its body limited to the size set by WithMaxRequestBodySize.
+func (s *ServeMux) limitRequestBody(r *http.Request) *http.Request {
+	if s.maxRequestBodySize <= 0 || r.Body == nil || r.Body == http.NoBody {
+		return r
+	}
+	r.Body = &limitedBody{ReadCloser: r.Body, limit: s.maxRequestBodySize, remaining: s.maxRequestBodySize}
+	return r
+}
+
+// limitRouteBody limits the body of r to the size set by WithRouteMaxBodySize for h, if any,
+// rather than the one set by WithMaxRequestBodySize.
+func limitRouteBody(r *http.Request, h handler) {
+	n := h.opts.maxBodySize
+	if n <= 0 || r.Body == nil || r.Body == http.NoBody {
+		return
+	}
+	b, ok := r.Body.(*limitedBody)
+	if !ok {
+		r.Body = &limitedBody{ReadCloser: r.Body, limit: n, remaining: n}
+		return
+	}
+	read := b.limit - b.remaining
+	b.limit = n
+	b.remaining = n - read
+	if b.remaining < 0 {
+		b.remaining = 0
+		b.exceeded = true
+	}
+}
+
+// requestBodyTooLarge returns the error for r if its body was read beyond the limit set by
+// WithMaxRequestBodySize, or nil otherwise.
+func requestBodyTooLarge(r *http.Request) error {
+	if r == nil {
+		return nil
+	}
+	b, ok := r.Body.(*limitedBody)
+	if !ok || !b.exceeded {
+		return nil
+	}
+	return status.Error(codes.ResourceExhausted, fmt.Sprintf("request body exceeds the limit of %d bytes", b.limit))
+}
+
+// statusResponseWriter replies with its code rather than the one given to WriteHeader.
+type statusResponseWriter struct {
+	http.ResponseWriter
+
+	code int
+}
+
+func (w *statusResponseWriter) WriteHeader(int) {
+	w.ResponseWriter.WriteHeader(w.code)
+}
+
+// Flush sends any buffered data to the client.
+func (w *statusResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// parseForm parses the form of r, and replies with an error if it fails.
+// It returns false if it failed.
+func (s *ServeMux) parseForm(w http.ResponseWriter, r *http.Request) bool {
+	err := r.ParseForm()
+	if err == nil {
+		return true
+	}
+	if tooLarge := requestBodyTooLarge(r); tooLarge != nil {
+		msg := status.Convert(tooLarge).Message()
+		s.routingError(&statusResponseWriter{ResponseWriter: w, code: http.StatusRequestEntityTooLarge}, r, tooLarge, msg, http.StatusRequestEntityTooLarge)
+		return false
+	}
+	sterr := status.Error(codes.InvalidArgument, err.Error())
+	s.routingError(w, r, sterr, err.Error(), http.StatusBadRequest)
+	return false
+}
@@ -0,0 +1,190 @@
+package runtime_test
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithMaxRequestBodySize(t *testing.T) {
+	pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+	small := `{"id":"foo"}`
+	large := `{"id":"` + strings.Repeat("x", 100) + `"}`
+
+	for _, spec := range []struct {
+		name    string
+		limit   int64
+		stream  bool
+		method  string
+		body    string
+		headers map[string]string
+
+		wantStatus int
+	}{
+		{name: "within the limit", limit: 64, body: small, wantStatus: http.StatusOK},
+		{name: "beyond the limit", limit: 64, body: large, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "no limit", body: large, wantStatus: http.StatusOK},
+		{
+			name:       "client stream within the limit",
+			limit:      64,
+			stream:     true,
+			body:       small + small,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "client stream beyond the limit",
+			limit:      64,
+			stream:     true,
+			body:       strings.Repeat(small, 10),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "form beyond the limit",
+			limit:      64,
+			method:     "POST",
+			body:       "id=" + strings.Repeat("x", 100),
+			headers:    map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(
+				runtime.WithMaxRequestBodySize(spec.limit),
+				runtime.WithProtoErrorHandler(runtime.DefaultHTTPProtoErrorHandler),
+			)
+			// Decodes the body like the generated handlers.
+			mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+				dec := (&runtime.JSONPb{}).NewDecoder(r.Body)
+				for {
+					var msg pb.SimpleMessage
+					err := dec.Decode(&msg)
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						runtime.HTTPError(ctx, mux, &runtime.JSONPb{}, w, r, status.Errorf(codes.InvalidArgument, "%v", err))
+						return
+					}
+					if !spec.stream {
+						break
+					}
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+
+			method := spec.method
+			if method == "" {
+				method = "GET"
+			}
+			r := httptest.NewRequest(method, "http://example.com/foo", strings.NewReader(spec.body))
+			for k, v := range spec.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.wantStatus; got != want {
+				t.Fatalf("w.Code = %d; want %d; body=%s", got, want, w.Body)
+			}
+			if spec.wantStatus != http.StatusRequestEntityTooLarge {
+				return
+			}
+			var body struct {
+				Code    codes.Code `json:"code"`
+				Message string     `json:"message"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("json.Unmarshal(%q, &body) failed with %v; want success", w.Body, err)
+			}
+			if got, want := body.Code, codes.ResourceExhausted; got != want {
+				t.Errorf("body.code = %v; want %v", got, want)
+			}
+			if got, want := body.Message, "request body exceeds the limit of 64 bytes"; got != want {
+				t.Errorf("body.message = %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestWithRouteMaxBodySize(t *testing.T) {
+	pattern := func(lit string) runtime.Pattern {
+		return runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{lit}, ""))
+	}
+	mux := runtime.NewServeMux(runtime.WithMaxRequestBodySize(16))
+	// Reads the body like the generated handlers.
+	handler := func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		if _, err := ioutil.ReadAll(r.Body); err != nil {
+			ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+			runtime.HTTPError(ctx, mux, &runtime.JSONPb{}, w, r, status.Errorf(codes.InvalidArgument, "%v", err))
+		}
+	}
+	mux.Handle("POST", pattern("upload"), handler, runtime.WithRouteMaxBodySize(256))
+	mux.Handle("POST", pattern("other"), handler)
+
+	for _, spec := range []struct {
+		path string
+		size int
+
+		wantStatus int
+	}{
+		{path: "/upload", size: 200, wantStatus: http.StatusOK},
+		{path: "/upload", size: 300, wantStatus: http.StatusRequestEntityTooLarge},
+		{path: "/other", size: 10, wantStatus: http.StatusOK},
+		{path: "/other", size: 200, wantStatus: http.StatusRequestEntityTooLarge},
+	} {
+		r := httptest.NewRequest("POST", "http://example.com"+spec.path, strings.NewReader(strings.Repeat("x", spec.size)))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		if got, want := w.Code, spec.wantStatus; got != want {
+			t.Errorf("w.Code = %d; want %d; path=%q, size=%d", got, want, spec.path, spec.size)
+		}
+	}
+}
+
+func TestWithContentLengthValidation(t *testing.T) {
+	pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+	mux := runtime.NewServeMux(runtime.WithContentLengthValidation())
+	// Reads the body like the generated handlers.
+	mux.Handle("POST", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		if _, err := ioutil.ReadAll(r.Body); err != nil {
+			ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+			runtime.HTTPError(ctx, mux, &runtime.JSONPb{}, w, r, status.Errorf(codes.InvalidArgument, "%v", err))
+		}
+	})
+
+	for _, spec := range []struct {
+		name          string
+		body          string
+		contentLength int64
+
+		wantStatus int
+	}{
+		{name: "matching", body: "0123456789", contentLength: 10, wantStatus: http.StatusOK},
+		{name: "longer body", body: "0123456789", contentLength: 5, wantStatus: http.StatusBadRequest},
+		{name: "shorter body", body: "0123456789", contentLength: 20, wantStatus: http.StatusBadRequest},
+		{name: "no Content-Length", body: "0123456789", contentLength: -1, wantStatus: http.StatusOK},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader(spec.body))
+			r.ContentLength = spec.contentLength
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.wantStatus; got != want {
+				t.Errorf("w.Code = %d; want %d; body=%s", got, want, w.Body)
+			}
+		})
+	}
+}
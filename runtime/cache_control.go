@@ -0,0 +1,63 @@
+package runtime
+
+import (
+	"net/http"
+)
+
+// WithRouteCacheControl returns a HandlerOption that sets the header Cache-Control of the
+// successful responses of the handler to value, e.g. "public, max-age=60", unless the handler
+// sets the header itself. It takes precedence over WithDefaultCacheControl.
+func WithRouteCacheControl(value string) HandlerOption {
+	return func(o *handlerOptions) {
+		o.cacheControl = value
+	}
+}
+
+// WithDefaultCacheControl returns a ServeMuxOption that sets the header Cache-Control of the
+// successful responses of the handlers which have no WithRouteCacheControl to value,
+// unless the handler sets the header itself.
+func WithDefaultCacheControl(value string) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.defaultCacheControl = value
+	}
+}
+
+// cacheControl returns the value of the header Cache-Control for the responses of h.
+func (s *ServeMux) cacheControl(h handler) string {
+	if h.opts.cacheControl != "" {
+		return h.opts.cacheControl
+	}
+	return s.defaultCacheControl
+}
+
+// cacheControlResponseWriter sets the header Cache-Control of a successful response.
+type cacheControlResponseWriter struct {
+	http.ResponseWriter
+
+	value       string
+	wroteHeader bool
+}
+
+func (w *cacheControlResponseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		h := w.Header()
+		if code >= 200 && code < 300 && h.Get("Cache-Control") == "" {
+			h.Set("Cache-Control", w.value)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *cacheControlResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *cacheControlResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,80 @@
+package runtime_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+)
+
+func TestRouteCacheControl(t *testing.T) {
+	for _, spec := range []struct {
+		name      string
+		muxOpts   []runtime.ServeMuxOption
+		path      string
+		setHeader string
+		status    int
+
+		want string
+	}{
+		{
+			name: "cacheable route",
+			path: "/cached",
+			want: "public, max-age=60",
+		},
+		{
+			name: "other route",
+			path: "/uncached",
+		},
+		{
+			name:    "other route with default",
+			muxOpts: []runtime.ServeMuxOption{runtime.WithDefaultCacheControl("no-store")},
+			path:    "/uncached",
+			want:    "no-store",
+		},
+		{
+			name:    "route option takes precedence over default",
+			muxOpts: []runtime.ServeMuxOption{runtime.WithDefaultCacheControl("no-store")},
+			path:    "/cached",
+			want:    "public, max-age=60",
+		},
+		{
+			name:      "set by handler",
+			path:      "/cached",
+			setHeader: "private",
+			want:      "private",
+		},
+		{
+			name:   "error response",
+			path:   "/cached",
+			status: http.StatusNotFound,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.muxOpts...)
+			h := func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				if spec.setHeader != "" {
+					w.Header().Set("Cache-Control", spec.setHeader)
+				}
+				if spec.status != 0 {
+					w.WriteHeader(spec.status)
+				}
+				w.Write([]byte("ok"))
+			}
+			cached := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"cached"}, ""))
+			uncached := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"uncached"}, ""))
+			mux.Handle("GET", cached, h, runtime.WithRouteCacheControl("public, max-age=60"))
+			mux.Handle("GET", uncached, h)
+
+			r := httptest.NewRequest("GET", "http://example.com"+spec.path, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got := w.Header().Get("Cache-Control"); got != spec.want {
+				t.Errorf("w.Header().Get(%q) = %q; want %q", "Cache-Control", got, spec.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ChaosLatencyFunc returns the latency to inject before the handler of r is called.
+type ChaosLatencyFunc func(r *http.Request) time.Duration
+
+// WithChaosLatency returns a ServeMuxOption that waits for the duration returned by fn, measured
+// by the clock of the ServeMux, before calling the handler matched by each request, e.g. to test
+// the resilience of clients in a staging environment. A duration of 0 or less injects no latency.
+// The request is replied with a "Canceled" error, without calling the handler, if its context is
+// done while waiting. Without the option, no latency is ever injected.
+func WithChaosLatency(fn ChaosLatencyFunc) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.chaosLatency = fn
+	}
+}
+
+// injectLatency waits for the latency returned by the chaos latency function of s for r.
+// It returns a "Canceled" error if the context of r is done before.
+func (s *ServeMux) injectLatency(r *http.Request) error {
+	return s.wait(r.Context(), s.chaosLatency(r))
+}
+
+// wait waits for d to elapse, measured by the clock of s. It returns a "Canceled" error if ctx
+// is done before.
+func (s *ServeMux) wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	elapsed := make(chan struct{})
+	t := s.clock.AfterFunc(d, func() { close(elapsed) })
+	select {
+	case <-elapsed:
+		return nil
+	case <-ctx.Done():
+		t.Stop()
+		return status.Error(codes.Canceled, context.Canceled.Error())
+	}
+}
@@ -0,0 +1,122 @@
+package runtime_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+)
+
+// schedulingClock is a fakeClock which reports the timers it schedules.
+type schedulingClock struct {
+	*fakeClock
+	scheduled chan time.Duration
+}
+
+func (c *schedulingClock) AfterFunc(d time.Duration, f func()) runtime.Timer {
+	t := c.fakeClock.AfterFunc(d, f)
+	c.scheduled <- d
+	return t
+}
+
+func TestWithChaosLatency(t *testing.T) {
+	pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+	newMux := func(clock runtime.Clock, called chan<- struct{}) *runtime.ServeMux {
+		latency := func(r *http.Request) time.Duration {
+			if r.Header.Get("X-Chaos") == "" {
+				return 0
+			}
+			return 100 * time.Millisecond
+		}
+		mux := runtime.NewServeMux(runtime.WithClock(clock), runtime.WithChaosLatency(latency))
+		mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			close(called)
+		})
+		return mux
+	}
+
+	t.Run("delay", func(t *testing.T) {
+		clock := &schedulingClock{fakeClock: newFakeClock(), scheduled: make(chan time.Duration, 1)}
+		called := make(chan struct{})
+		mux := newMux(clock, called)
+
+		r := httptest.NewRequest("GET", "http://example.com/foo", nil)
+		r.Header.Set("X-Chaos", "1")
+		w := httptest.NewRecorder()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			mux.ServeHTTP(w, r)
+		}()
+
+		if got, want := <-clock.scheduled, 100*time.Millisecond; got != want {
+			t.Errorf("injected latency = %v; want %v", got, want)
+		}
+		clock.Advance(99 * time.Millisecond)
+		select {
+		case <-called:
+			t.Fatalf("handler called before the injected latency elapsed")
+		default:
+		}
+		clock.Advance(time.Millisecond)
+		<-done
+		select {
+		case <-called:
+		default:
+			t.Errorf("handler not called after the injected latency")
+		}
+		if got, want := w.Code, http.StatusOK; got != want {
+			t.Errorf("w.Code = %d; want %d", got, want)
+		}
+	})
+
+	t.Run("no latency", func(t *testing.T) {
+		clock := &schedulingClock{fakeClock: newFakeClock(), scheduled: make(chan time.Duration, 1)}
+		called := make(chan struct{})
+		mux := newMux(clock, called)
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/foo", nil))
+		select {
+		case <-called:
+		default:
+			t.Errorf("handler not called")
+		}
+		if len(clock.scheduled) != 0 {
+			t.Errorf("latency injected for a request without latency")
+		}
+	})
+
+	t.Run("cancellation", func(t *testing.T) {
+		clock := &schedulingClock{fakeClock: newFakeClock(), scheduled: make(chan time.Duration, 1)}
+		called := make(chan struct{})
+		mux := newMux(clock, called)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		r := httptest.NewRequest("GET", "http://example.com/foo", nil).WithContext(ctx)
+		r.Header.Set("X-Chaos", "1")
+		w := httptest.NewRecorder()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			mux.ServeHTTP(w, r)
+		}()
+
+		<-clock.scheduled
+		cancel()
+		<-done
+		select {
+		case <-called:
+			t.Errorf("handler called after the request was canceled")
+		default:
+		}
+		if got, want := w.Code, http.StatusRequestTimeout; got != want {
+			t.Errorf("w.Code = %d; want %d", got, want)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package runtime
+
+import (
+	"io"
+	"mime"
+	"strings"
+)
+
+type textCharsetOptions struct {
+	charset string
+	isText  func(contentType string) bool
+}
+
+// WithTextCharset returns a ServeMuxOption that adds the parameter "charset", e.g. "utf-8", to the
+// content type of the responses of outbound marshalers which produce text, so that marshalers do
+// not have to declare it themselves. Content types which already have a charset are left as is.
+//
+// isText tells whether a content type is text. If it is nil, IsTextContentType is used.
+func WithTextCharset(charset string, isText func(contentType string) bool) ServeMuxOption {
+	if isText == nil {
+		isText = IsTextContentType
+	}
+	return func(serveMux *ServeMux) {
+		serveMux.textCharset = &textCharsetOptions{charset: charset, isText: isText}
+	}
+}
+
+// IsTextContentType returns true for the media types of text: "text/*", JSON, XML and YAML,
+// including the structured syntax suffixes like "application/problem+json".
+func IsTextContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml":
+		return true
+	}
+	for _, suffix := range []string{"+json", "+xml", "+yaml"} {
+		if strings.HasSuffix(mediaType, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// withCharset returns contentType with the charset, if it is text without a charset.
+func (o *textCharsetOptions) withCharset(contentType string) string {
+	if !o.isText(contentType) {
+		return contentType
+	}
+	if _, params, err := mime.ParseMediaType(contentType); err != nil || params["charset"] != "" {
+		return contentType
+	}
+	return contentType + "; charset=" + o.charset
+}
+
+// charsetMarshaler adds a charset to the content type of a Marshaler.
+type charsetMarshaler struct {
+	m    Marshaler
+	opts *textCharsetOptions
+}
+
+func (c *charsetMarshaler) Marshal(v interface{}) ([]byte, error)      { return c.m.Marshal(v) }
+func (c *charsetMarshaler) Unmarshal(data []byte, v interface{}) error { return c.m.Unmarshal(data, v) }
+func (c *charsetMarshaler) NewDecoder(r io.Reader) Decoder             { return c.m.NewDecoder(r) }
+func (c *charsetMarshaler) NewEncoder(w io.Writer) Encoder             { return c.m.NewEncoder(w) }
+
+func (c *charsetMarshaler) ContentType() string {
+	return c.opts.withCharset(c.m.ContentType())
+}
+
+func (c *charsetMarshaler) ContentTypeFromMessage(v interface{}) string {
+	if typeMarshaler, ok := c.m.(contentTypeMarshaler); ok {
+		return c.opts.withCharset(typeMarshaler.ContentTypeFromMessage(v))
+	}
+	return c.ContentType()
+}
+
+func (c *charsetMarshaler) Delimiter() []byte {
+	return streamDelimiter(c.m)
+}
+
+func (c *charsetMarshaler) LeadingDelimiter() bool {
+	return leadingDelimiter(c.m)
+}
+
+func (c *charsetMarshaler) StreamSupported() bool {
+	return streamSupported(c.m)
+}
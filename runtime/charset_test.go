@@ -0,0 +1,106 @@
+package runtime_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+)
+
+func TestWithTextCharset(t *testing.T) {
+	for _, spec := range []struct {
+		name   string
+		opts   []runtime.ServeMuxOption
+		accept string
+
+		wantContentType string
+	}{
+		{
+			name:            "default",
+			wantContentType: "application/json",
+		},
+		{
+			name:            "json",
+			opts:            []runtime.ServeMuxOption{runtime.WithTextCharset("utf-8", nil)},
+			wantContentType: "application/json; charset=utf-8",
+		},
+		{
+			name: "binary",
+			opts: []runtime.ServeMuxOption{
+				runtime.WithTextCharset("utf-8", nil),
+				runtime.WithMarshalerOption("application/octet-stream", &runtime.ProtoMarshaller{}),
+			},
+			accept:          "application/octet-stream",
+			wantContentType: "application/octet-stream",
+		},
+		{
+			name: "charset already set",
+			opts: []runtime.ServeMuxOption{
+				runtime.WithTextCharset("utf-8", nil),
+				runtime.WithMarshalerOption("text/plain", &contentTypeMarshaler{contentType: "text/plain; charset=us-ascii"}),
+			},
+			accept:          "text/plain",
+			wantContentType: "text/plain; charset=us-ascii",
+		},
+		{
+			name: "custom detection",
+			opts: []runtime.ServeMuxOption{
+				runtime.WithTextCharset("utf-8", func(contentType string) bool {
+					return strings.HasPrefix(contentType, "application/x-text")
+				}),
+				runtime.WithMarshalerOption("application/x-text", &contentTypeMarshaler{contentType: "application/x-text"}),
+			},
+			accept:          "application/x-text",
+			wantContentType: "application/x-text; charset=utf-8",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.opts...)
+			r := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			if spec.accept != "" {
+				r.Header.Set("Accept", spec.accept)
+			}
+			_, out := runtime.MarshalerForRequest(mux, r)
+			if got := out.ContentType(); got != spec.wantContentType {
+				t.Errorf("out.ContentType() = %q; want %q", got, spec.wantContentType)
+			}
+
+			w := httptest.NewRecorder()
+			ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+			runtime.ForwardResponseMessage(ctx, mux, out, w, r, &pb.SimpleMessage{Id: "foo"})
+			if got := w.Header().Get("Content-Type"); got != spec.wantContentType {
+				t.Errorf("w.Header().Get(%q) = %q; want %q", "Content-Type", got, spec.wantContentType)
+			}
+		})
+	}
+}
+
+func TestIsTextContentType(t *testing.T) {
+	for _, spec := range []struct {
+		contentType string
+		want        bool
+	}{
+		{contentType: "text/plain", want: true},
+		{contentType: "application/json", want: true},
+		{contentType: "application/problem+json", want: true},
+		{contentType: "application/xml", want: true},
+		{contentType: "application/x-yaml", want: true},
+		{contentType: "application/octet-stream"},
+		{contentType: "image/png"},
+		{contentType: ""},
+	} {
+		if got := runtime.IsTextContentType(spec.contentType); got != spec.want {
+			t.Errorf("runtime.IsTextContentType(%q) = %v; want %v", spec.contentType, got, spec.want)
+		}
+	}
+}
+
+// contentTypeMarshaler is a JSONPb with a custom content type.
+type contentTypeMarshaler struct {
+	runtime.JSONPb
+	contentType string
+}
+
+func (m *contentTypeMarshaler) ContentType() string { return m.contentType }
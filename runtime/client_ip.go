@@ -0,0 +1,96 @@
+package runtime
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/grpclog"
+)
+
+// ClientIPMetadataKey is the gRPC metadata key carrying the IP address of the client,
+// as computed with WithClientIPMetadata.
+const ClientIPMetadataKey = MetadataPrefix + "client-ip"
+
+const xRealIP = "X-Real-Ip"
+
+type clientIPOptions struct {
+	trustedProxies []*net.IPNet
+}
+
+// WithClientIPMetadata returns a ServeMuxOption that passes the IP address of the client to the
+// gRPC server as metadata under ClientIPMetadataKey.
+//
+// The address is the one in the RemoteAddr of the request, unless it belongs to one of
+// trustedProxyCIDRs, e.g. "10.0.0.0/8". Then the "X-Forwarded-For" header is read from the right
+// and the first address which is not a trusted proxy is the client. If all of them are trusted,
+// the leftmost one is. Without "X-Forwarded-For", the "X-Real-IP" header set by a trusted proxy
+// is used. Headers set by clients which are not trusted proxies are ignored, so that clients
+// cannot spoof their address.
+//
+// It panics if one of trustedProxyCIDRs is not a valid CIDR.
+func WithClientIPMetadata(trustedProxyCIDRs []string) ServeMuxOption {
+	opts := &clientIPOptions{}
+	for _, cidr := range trustedProxyCIDRs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		opts.trustedProxies = append(opts.trustedProxies, ipNet)
+	}
+	return func(serveMux *ServeMux) {
+		serveMux.clientIP = opts
+	}
+}
+
+// clientIP returns the IP address of the client which sent r, or "" if it is unknown.
+func (o *clientIPOptions) clientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	ip := net.ParseIP(remote)
+	if ip == nil {
+		grpclog.Infof("invalid remote addr: %s", r.RemoteAddr)
+		return ""
+	}
+	if !o.trusted(ip) {
+		return ip.String()
+	}
+
+	var hops []string
+	for _, v := range r.Header.Values(xForwardedFor) {
+		for _, hop := range strings.Split(v, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	if len(hops) == 0 {
+		if realIP := net.ParseIP(strings.TrimSpace(r.Header.Get(xRealIP))); realIP != nil {
+			return realIP.String()
+		}
+		return ip.String()
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(hops[i])
+		if hop == nil {
+			// What comes before is not reliable.
+			break
+		}
+		ip = hop
+		if !o.trusted(ip) {
+			break
+		}
+	}
+	return ip.String()
+}
+
+func (o *clientIPOptions) trusted(ip net.IP) bool {
+	for _, n := range o.trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
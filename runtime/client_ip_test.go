@@ -0,0 +1,132 @@
+package runtime_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestWithClientIPMetadata(t *testing.T) {
+	trusted := []string{"10.0.0.0/8", "2001:db8::/32"}
+	for _, spec := range []struct {
+		name       string
+		remoteAddr string
+		headers    map[string][]string
+		want       []string
+	}{
+		{
+			name:       "direct client",
+			remoteAddr: "192.0.2.1:1234",
+			want:       []string{"192.0.2.1"},
+		},
+		{
+			name:       "untrusted proxy",
+			remoteAddr: "192.0.2.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.7"}},
+			want:       []string{"192.0.2.1"},
+		},
+		{
+			name:       "trusted proxy",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.7"}},
+			want:       []string{"198.51.100.7"},
+		},
+		{
+			name:       "chain of trusted proxies",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.9, 198.51.100.7, 10.0.0.2"}},
+			want:       []string{"198.51.100.7"},
+		},
+		{
+			name:       "chain in several headers",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.9", "10.0.0.3, 10.0.0.2"}},
+			want:       []string{"203.0.113.9"},
+		},
+		{
+			name:       "only trusted proxies",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}},
+			want:       []string{"10.0.0.3"},
+		},
+		{
+			name:       "invalid hop",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.9, garbage, 10.0.0.2"}},
+			want:       []string{"10.0.0.2"},
+		},
+		{
+			name:       "IPv6 trusted proxy",
+			remoteAddr: "[2001:db8::1]:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"2001:db9::5"}},
+			want:       []string{"2001:db9::5"},
+		},
+		{
+			name:       "X-Real-IP from trusted proxy",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Real-IP": {"198.51.100.7"}},
+			want:       []string{"198.51.100.7"},
+		},
+		{
+			name:       "X-Real-IP from untrusted proxy",
+			remoteAddr: "192.0.2.1:1234",
+			headers:    map[string][]string{"X-Real-IP": {"198.51.100.7"}},
+			want:       []string{"192.0.2.1"},
+		},
+		{
+			name:       "X-Forwarded-For takes precedence over X-Real-IP",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"X-Forwarded-For": {"203.0.113.9"},
+				"X-Real-IP":       {"198.51.100.7"},
+			},
+			want: []string{"203.0.113.9"},
+		},
+		{
+			name:       "invalid remote address",
+			remoteAddr: "pipe",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			r, err := http.NewRequest("GET", "http://example.com/foo", nil)
+			if err != nil {
+				t.Fatalf("http.NewRequest failed with %v; want success", err)
+			}
+			r.RemoteAddr = spec.remoteAddr
+			for k, vs := range spec.headers {
+				for _, v := range vs {
+					r.Header.Add(k, v)
+				}
+			}
+			mux := runtime.NewServeMux(runtime.WithClientIPMetadata(trusted))
+			ctx, err := runtime.AnnotateContext(context.Background(), mux, r)
+			if err != nil {
+				t.Fatalf("runtime.AnnotateContext failed with %v; want success", err)
+			}
+			md, _ := metadata.FromOutgoingContext(ctx)
+			if got := md[runtime.ClientIPMetadataKey]; !reflect.DeepEqual(got, spec.want) {
+				t.Errorf("md[%q] = %q; want %q", runtime.ClientIPMetadataKey, got, spec.want)
+			}
+		})
+	}
+}
+
+func TestWithClientIPMetadataNotSet(t *testing.T) {
+	r, err := http.NewRequest("GET", "http://example.com/foo", nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest failed with %v; want success", err)
+	}
+	r.RemoteAddr = "192.0.2.1:1234"
+	ctx, err := runtime.AnnotateContext(context.Background(), runtime.NewServeMux(), r)
+	if err != nil {
+		t.Fatalf("runtime.AnnotateContext failed with %v; want success", err)
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if got, ok := md[runtime.ClientIPMetadataKey]; ok {
+		t.Errorf("md[%q] = %q; want no value", runtime.ClientIPMetadataKey, got)
+	}
+}
@@ -0,0 +1,108 @@
+package runtime
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time used by a ServeMux to apply timeouts, such as the
+// one requested by a "Grpc-Timeout" header.
+// The default Clock uses the wall clock; WithClock replaces it, e.g. with a fake
+// clock which makes timeouts deterministic in tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc waits for the duration to elapse and then calls f in its own goroutine.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending call of a function created with Clock.AfterFunc.
+type Timer interface {
+	// Stop prevents the function from being called. It returns false if
+	// the function has already been called or the timer has been stopped.
+	Stop() bool
+}
+
+// WithClock returns a ServeMuxOption that makes the mux measure time with clock.
+func WithClock(clock Clock) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.clock = clock
+	}
+}
+
+type wallClock struct{}
+
+func (wallClock) Now() time.Time { return time.Now() }
+
+func (wallClock) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
+
+// withTimeout returns a copy of ctx which is done once timeout has elapsed according to clock,
+// or when ctx is done, whichever happens first, and a function which releases its resources
+// once called.
+//
+// With a Clock other than the wall clock, the deadline reported by the returned context is still
+// timeout from now on the wall clock, since gRPC sends it to the server as the "grpc-timeout" of
+// the call; only the moment the context is done is measured by clock.
+func withTimeout(ctx context.Context, clock Clock, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if clock == nil {
+		clock = wallClock{}
+	}
+	if _, ok := clock.(wallClock); ok {
+		return context.WithTimeout(ctx, timeout)
+	}
+
+	c := &clockContext{
+		Context:  ctx,
+		deadline: time.Now().Add(timeout),
+		done:     make(chan struct{}),
+	}
+	t := clock.AfterFunc(timeout, func() { c.cancel(context.DeadlineExceeded) })
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.cancel(ctx.Err())
+		case <-c.done:
+		}
+		t.Stop()
+	}()
+	return c, func() { c.cancel(context.Canceled) }
+}
+
+// clockContext is a context.Context which is done according to a Clock other than
+// the wall clock.
+type clockContext struct {
+	context.Context
+	deadline time.Time
+
+	mu   sync.Mutex
+	done chan struct{}
+	err  error
+}
+
+func (c *clockContext) Deadline() (time.Time, bool) {
+	if d, ok := c.Context.Deadline(); ok && d.Before(c.deadline) {
+		return d, true
+	}
+	return c.deadline, true
+}
+
+func (c *clockContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *clockContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *clockContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.done)
+}
@@ -0,0 +1,149 @@
+package runtime_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+)
+
+// fakeClock is a runtime.Clock whose time only moves with Advance.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock   *fakeClock
+	when    time.Time
+	f       func()
+	stopped bool
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) runtime.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, when: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by d and synchronously calls the functions
+// of the timers which expire.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var expired, pending []*fakeTimer
+	for _, t := range c.timers {
+		if t.stopped {
+			continue
+		}
+		if t.when.After(c.now) {
+			pending = append(pending, t)
+		} else {
+			t.stopped = true
+			expired = append(expired, t)
+		}
+	}
+	c.timers = pending
+	c.mu.Unlock()
+
+	for _, t := range expired {
+		t.f()
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	wasActive := !t.stopped
+	t.stopped = true
+	return wasActive
+}
+
+func TestAnnotateContext_TimeoutWithClock(t *testing.T) {
+	clock := newFakeClock()
+	mux := runtime.NewServeMux(runtime.WithClock(clock))
+
+	request, err := http.NewRequest("GET", "http://example.com", nil)
+	if err != nil {
+		t.Fatalf(`http.NewRequest("GET", "http://example.com", nil) failed with %v; want success`, err)
+	}
+	request.Header.Set("Grpc-Timeout", "10S")
+
+	before := time.Now()
+	annotated, err := runtime.AnnotateContext(context.Background(), mux, request)
+	if err != nil {
+		t.Fatalf("runtime.AnnotateContext(ctx, %#v) failed with %v; want success", request, err)
+	}
+	after := time.Now()
+	// The deadline is sent to the gRPC server, so it is measured by the wall clock.
+	deadline, ok := annotated.Deadline()
+	if !ok {
+		t.Errorf("annotated.Deadline() = _, false; want _, true")
+	}
+	if deadline.Before(before.Add(10*time.Second)) || deadline.After(after.Add(10*time.Second)) {
+		t.Errorf("annotated.Deadline() = %v; want 10s after %v on the wall clock", deadline, before)
+	}
+
+	clock.Advance(9 * time.Second)
+	select {
+	case <-annotated.Done():
+		t.Fatalf("annotated is done before its deadline with %v", annotated.Err())
+	default:
+	}
+	if err := annotated.Err(); err != nil {
+		t.Errorf("annotated.Err() = %v; want nil", err)
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case <-annotated.Done():
+	default:
+		t.Fatalf("annotated is not done after its deadline")
+	}
+	if got, want := annotated.Err(), context.DeadlineExceeded; got != want {
+		t.Errorf("annotated.Err() = %v; want %v", got, want)
+	}
+}
+
+func TestAnnotateContext_TimeoutWithClockParentCanceled(t *testing.T) {
+	clock := newFakeClock()
+	mux := runtime.NewServeMux(runtime.WithClock(clock))
+
+	request, err := http.NewRequest("GET", "http://example.com", nil)
+	if err != nil {
+		t.Fatalf(`http.NewRequest("GET", "http://example.com", nil) failed with %v; want success`, err)
+	}
+	request.Header.Set("Grpc-Timeout", "10S")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	annotated, err := runtime.AnnotateContext(ctx, mux, request)
+	if err != nil {
+		t.Fatalf("runtime.AnnotateContext(ctx, %#v) failed with %v; want success", request, err)
+	}
+
+	cancel()
+	select {
+	case <-annotated.Done():
+	case <-time.After(time.Second):
+		t.Fatalf("annotated is not done after its parent was canceled")
+	}
+	if got, want := annotated.Err(), context.Canceled; got != want {
+		t.Errorf("annotated.Err() = %v; want %v", got, want)
+	}
+}
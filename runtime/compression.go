@@ -0,0 +1,165 @@
+package runtime
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CompressionWriter compresses what is written to it. Flush writes out the data compressed so far,
+// so that it can be decompressed by the client without waiting for the rest of the response,
+// and Close writes out the remaining data.
+type CompressionWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// CompressorFunc returns a CompressionWriter which writes compressed data into w.
+type CompressorFunc func(w io.Writer) (CompressionWriter, error)
+
+type responseCompressor struct {
+	encoding  string
+	newWriter CompressorFunc
+}
+
+// WithResponseCompression returns a ServeMuxOption that compresses responses with newWriter
+// when the client accepts the content coding encoding, such as "gzip", in its Accept-Encoding header.
+// If several are given, the client's quality values decide which one is used, and the order of
+// the options otherwise, so that e.g. "br" can be preferred to "gzip" by giving it first.
+//
+// Compression works with streaming responses: the compressor is flushed together with the
+// response after each message of a stream, so that clients receive each message as it is sent.
+func WithResponseCompression(encoding string, newWriter CompressorFunc) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.compressors = append(serveMux.compressors, responseCompressor{
+			encoding:  strings.ToLower(encoding),
+			newWriter: newWriter,
+		})
+	}
+}
+
+// GzipCompressor returns a CompressorFunc which compresses with gzip at the given level,
+// as defined in the package compress/gzip.
+func GzipCompressor(level int) CompressorFunc {
+	return func(w io.Writer) (CompressionWriter, error) {
+		return gzip.NewWriterLevel(w, level)
+	}
+}
+
+// responseCompressor returns the compressor to use for the response to r, or nil if the
+// response must not be compressed. It is the one of the content codings accepted by r with the
+// highest quality value, and the first registered one among those with the same quality.
+func (s *ServeMux) responseCompressor(r *http.Request) *responseCompressor {
+	accepted := acceptedEncodings(r.Header["Accept-Encoding"])
+	var best *responseCompressor
+	var bestQ float64
+	for i, c := range s.compressors {
+		q, ok := accepted[c.encoding]
+		if !ok {
+			q = accepted["*"]
+		}
+		if q > bestQ {
+			best, bestQ = &s.compressors[i], q
+		}
+	}
+	return best
+}
+
+// acceptedEncodings returns the quality values of the content codings listed in the
+// Accept-Encoding header values. Codings with an invalid quality value are not acceptable,
+// like the ones with the quality value 0.
+func acceptedEncodings(values []string) map[string]float64 {
+	accepted := make(map[string]float64)
+	for _, v := range values {
+		for _, coding := range strings.Split(v, ",") {
+			q := 1.0
+			if i := strings.Index(coding, ";"); i >= 0 {
+				q = qualityValue(coding[i+1:])
+				coding = coding[:i]
+			}
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding == "" {
+				continue
+			}
+			accepted[coding] = q
+		}
+	}
+	return accepted
+}
+
+// qualityValue returns the value of the "q" parameter in params, 1 if there is none, or 0 if it
+// is invalid.
+func qualityValue(params string) float64 {
+	for _, p := range strings.Split(params, ";") {
+		p = strings.TrimSpace(p)
+		if len(p) < 2 || (p[0] != 'q' && p[0] != 'Q') || p[1] != '=' {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(p[2:]), 64)
+		if err != nil || q < 0 || q > 1 {
+			return 0
+		}
+		return q
+	}
+	return 1
+}
+
+// compressResponseWriter compresses the body written to an http.ResponseWriter.
+type compressResponseWriter struct {
+	http.ResponseWriter
+
+	compressor  *responseCompressor
+	cw          CompressionWriter
+	wroteHeader bool
+	err         error
+}
+
+func newCompressResponseWriter(w http.ResponseWriter, c *responseCompressor) *compressResponseWriter {
+	w.Header().Add("Vary", "Accept-Encoding")
+	return &compressResponseWriter{ResponseWriter: w, compressor: c}
+}
+
+func (w *compressResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	h := w.Header()
+	if code != http.StatusNoContent && code != http.StatusNotModified && h.Get("Content-Encoding") == "" {
+		h.Set("Content-Encoding", w.compressor.encoding)
+		h.Del("Content-Length")
+		w.cw, w.err = w.compressor.newWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *compressResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.cw == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.cw.Write(b)
+}
+
+// Flush sends the data compressed so far to the client.
+func (w *compressResponseWriter) Flush() {
+	if w.cw != nil && w.err == nil {
+		w.err = w.cw.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// close writes out the end of the compressed body.
+func (w *compressResponseWriter) close() error {
+	if w.cw == nil || w.err != nil {
+		return w.err
+	}
+	return w.cw.Close()
+}
@@ -0,0 +1,190 @@
+package runtime_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+)
+
+func TestResponseCompression(t *testing.T) {
+	for _, spec := range []struct {
+		name           string
+		acceptEncoding string
+		wantEncoding   string
+	}{
+		{
+			name:           "gzip accepted",
+			acceptEncoding: "gzip",
+			wantEncoding:   "gzip",
+		},
+		{
+			name:           "gzip among others",
+			acceptEncoding: "deflate, gzip;q=0.5",
+			wantEncoding:   "gzip",
+		},
+		{
+			name:           "not accepted",
+			acceptEncoding: "deflate",
+		},
+		{
+			name: "no Accept-Encoding",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(runtime.WithResponseCompression("gzip", runtime.GzipCompressor(gzip.DefaultCompression)))
+			pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+			mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+				runtime.ForwardResponseMessage(ctx, mux, &runtime.JSONPb{}, w, r, &pb.SimpleMessage{Id: "foo"})
+			})
+
+			r := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			if spec.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", spec.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got := w.Header().Get("Content-Encoding"); got != spec.wantEncoding {
+				t.Errorf("w.Header().Get(%q) = %q; want %q", "Content-Encoding", got, spec.wantEncoding)
+			}
+			var body io.Reader = w.Body
+			if spec.wantEncoding != "" {
+				zr, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader(w.Body) failed with %v; want success", err)
+				}
+				body = zr
+			}
+			b, err := ioutil.ReadAll(body)
+			if err != nil {
+				t.Fatalf("ioutil.ReadAll(body) failed with %v; want success", err)
+			}
+			if got, want := string(b), `{"id":"foo"}`; got != want {
+				t.Errorf("body = %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestResponseCompressionStream(t *testing.T) {
+	msgs := []proto.Message{
+		&pb.SimpleMessage{Id: "One"},
+		&pb.SimpleMessage{Id: "Two"},
+		&pb.SimpleMessage{Id: "Three"},
+	}
+	// Each message is only sent once the client has read the previous one.
+	next := make(chan struct{})
+	defer close(next)
+
+	mux := runtime.NewServeMux(runtime.WithResponseCompression("gzip", runtime.GzipCompressor(gzip.DefaultCompression)))
+	pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+	mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+		count := 0
+		recv := func() (proto.Message, error) {
+			if count > 0 {
+				if _, ok := <-next; !ok {
+					return nil, context.Canceled
+				}
+			}
+			if count == len(msgs) {
+				return nil, io.EOF
+			}
+			count++
+			return msgs[count-1], nil
+		}
+		runtime.ForwardResponseStream(ctx, mux, &runtime.JSONPb{}, w, r, recv)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	req, err := http.NewRequest("GET", srv.URL+"/foo", nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest failed with %v; want success", err)
+	}
+	// Setting the header disables the transparent decompression of the client.
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("http.DefaultClient.Do(req) failed with %v; want success", err)
+	}
+	defer resp.Body.Close()
+	if got, want := resp.Header.Get("Content-Encoding"), "gzip"; got != want {
+		t.Fatalf("resp.Header.Get(%q) = %q; want %q", "Content-Encoding", got, want)
+	}
+
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader(resp.Body) failed with %v; want success", err)
+	}
+	lines := bufio.NewReader(zr)
+	for i, msg := range msgs {
+		line, err := lines.ReadString('\n')
+		if err != nil {
+			t.Fatalf("lines.ReadString('\\n') failed with %v for message %d; want success", err, i)
+		}
+		want, err := (&runtime.JSONPb{}).Marshal(map[string]interface{}{"result": msg})
+		if err != nil {
+			t.Fatalf("Marshal failed with %v", err)
+		}
+		if got := line; got != string(want)+"\n" {
+			t.Errorf("message %d = %q; want %q", i, got, string(want)+"\n")
+		}
+		next <- struct{}{}
+	}
+	if rest, err := ioutil.ReadAll(zr); err != nil || len(rest) != 0 {
+		t.Errorf("ioutil.ReadAll(zr) = %q, %v; want an empty rest of the stream", rest, err)
+	}
+}
+
+func TestResponseCompressionPreference(t *testing.T) {
+	for _, spec := range []struct {
+		acceptEncoding string
+		wantEncoding   string
+	}{
+		{acceptEncoding: "gzip, br", wantEncoding: "br"},
+		{acceptEncoding: "gzip", wantEncoding: "gzip"},
+		{acceptEncoding: "br;q=0.5, gzip", wantEncoding: "gzip"},
+		{acceptEncoding: "br;q=0.8, gzip;q=0.8", wantEncoding: "br"},
+		{acceptEncoding: "BR;Q=0.9, gzip;q=0.1", wantEncoding: "br"},
+		{acceptEncoding: "zstd, gzip;q=0.5", wantEncoding: "gzip"},
+		{acceptEncoding: "*", wantEncoding: "br"},
+		{acceptEncoding: "br;q=0, *", wantEncoding: "gzip"},
+		{acceptEncoding: "*;q=0.5, gzip", wantEncoding: "gzip"},
+		{acceptEncoding: "br;q=0, gzip;q=0"},
+		{acceptEncoding: "br;q=invalid, gzip;q=2"},
+		{acceptEncoding: "zstd, deflate"},
+	} {
+		t.Run(spec.acceptEncoding, func(t *testing.T) {
+			mux := runtime.NewServeMux(
+				runtime.WithResponseCompression("br", runtime.GzipCompressor(gzip.DefaultCompression)),
+				runtime.WithResponseCompression("gzip", runtime.GzipCompressor(gzip.DefaultCompression)),
+			)
+			pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+			mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+				runtime.ForwardResponseMessage(ctx, mux, &runtime.JSONPb{}, w, r, &pb.SimpleMessage{Id: "foo"})
+			})
+
+			r := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			r.Header.Set("Accept-Encoding", spec.acceptEncoding)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got := w.Header().Get("Content-Encoding"); got != spec.wantEncoding {
+				t.Errorf("w.Header().Get(%q) = %q; want %q", "Content-Encoding", got, spec.wantEncoding)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package runtime
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrUnsupportedMediaType is returned by NegotiateMarshalers when no marshaler is registered
+	// for the Content-Type of a request. The request is replied with "415 Unsupported Media Type".
+	ErrUnsupportedMediaType = status.Error(codes.InvalidArgument, http.StatusText(http.StatusUnsupportedMediaType))
+	// ErrNotAcceptable is returned by NegotiateMarshalers when no marshaler is registered for the
+	// media types of the Accept header of a request. The request is replied with "406 Not Acceptable".
+	ErrNotAcceptable = status.Error(codes.InvalidArgument, http.StatusText(http.StatusNotAcceptable))
+)
+
+// WithStrictContentNegotiation returns a ServeMuxOption that rejects the requests whose
+// Content-Type has no registered marshaler with "415 Unsupported Media Type", and the requests
+// whose Accept header names no media type with a registered marshaler with "406 Not Acceptable",
+// rather than using the marshaler registered for "*". The errors are encoded with the marshaler
+// registered for "*", through the error handler of the ServeMux.
+//
+// Media types are then matched without their parameters too, e.g. "application/json" for
+// "application/json; charset=utf-8", and the Accept header can list several of them, the first
+// registered one being used. The marshaler registered for "*" is also used for its own content
+// type, and for "*/*". The routes with a marshaler set by WithRouteMarshaler are not checked.
+func WithStrictContentNegotiation() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.strictContentNegotiation = true
+	}
+}
+
+// negotiate returns the marshalers registered for the Content-Type and the Accept header of r,
+// as described in WithStrictContentNegotiation. On error, the marshaler registered for "*" is
+// returned instead.
+func (m marshalerRegistry) negotiate(r *http.Request) (inbound Marshaler, outbound Marshaler, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	def := m.mimeMap[MIMEWildcard]
+	inbound = def
+	var contentTypes []string
+	for _, v := range r.Header[contentTypeHeader] {
+		if v != "" {
+			contentTypes = append(contentTypes, v)
+		}
+	}
+	if len(contentTypes) > 0 {
+		marshaler, ok := m.lookup(contentTypes)
+		if !ok {
+			return def, def, ErrUnsupportedMediaType
+		}
+		inbound = marshaler
+	}
+
+	outbound = inbound
+	var ranges []string
+	for _, v := range r.Header[acceptHeader] {
+		for _, mediaRange := range strings.Split(v, ",") {
+			if mediaRange = strings.TrimSpace(mediaRange); mediaRange != "" {
+				ranges = append(ranges, mediaRange)
+			}
+		}
+	}
+	if len(ranges) == 0 {
+		if len(r.Header[acceptHeader]) > 0 {
+			// A blank Accept header asks for the default marshaler, as with MarshalerForRequest.
+			outbound = def
+		}
+		return inbound, outbound, nil
+	}
+	for _, mediaRange := range ranges {
+		if marshaler, ok := m.lookup([]string{mediaRange}); ok {
+			return inbound, marshaler, nil
+		}
+		if mediaType, _, err := mime.ParseMediaType(mediaRange); mediaRange == MIMEWildcard || err == nil && mediaType == "*/*" {
+			return inbound, outbound, nil
+		}
+	}
+	return def, def, ErrNotAcceptable
+}
+
+// lookup returns the marshaler registered for the first of the media types which has one,
+// with or without its parameters, or by its structured syntax suffix if the registry matches
+// suffixes. The marshaler registered for "*" is registered for its own content type too.
+func (m marshalerRegistry) lookup(mediaTypes []string) (Marshaler, bool) {
+	def := m.mimeMap[MIMEWildcard]
+	var defType string
+	if def != nil {
+		defType, _, _ = mime.ParseMediaType(def.ContentType())
+	}
+	for _, v := range mediaTypes {
+		if marshaler, ok := m.mimeMap[v]; ok && v != MIMEWildcard {
+			return marshaler, true
+		}
+		mediaType, _, err := mime.ParseMediaType(v)
+		if err != nil {
+			continue
+		}
+		if marshaler, ok := m.mimeMap[mediaType]; ok {
+			return marshaler, true
+		}
+		if def != nil && mediaType == defType {
+			return def, true
+		}
+		if !m.matchSuffixes {
+			continue
+		}
+		for _, t := range suffixTypes(mediaType) {
+			if marshaler, ok := m.mimeMap[t]; ok {
+				return marshaler, true
+			}
+			if def != nil && t == defType {
+				return def, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// negotiationError replies to r with err, returned by NegotiateMarshalers, encoded with marshaler.
+func (s *ServeMux) negotiationError(w http.ResponseWriter, r *http.Request, marshaler Marshaler, err error) {
+	code := http.StatusNotAcceptable
+	if err == ErrUnsupportedMediaType {
+		code = http.StatusUnsupportedMediaType
+	}
+	ctx := NewServerMetadataContext(r.Context(), ServerMetadata{})
+	HTTPError(ctx, s, marshaler, &statusResponseWriter{ResponseWriter: w, code: code}, r, err)
+}
@@ -0,0 +1,80 @@
+package runtime_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+)
+
+func TestWithStrictContentNegotiation(t *testing.T) {
+	pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+	for _, spec := range []struct {
+		name        string
+		lenient     bool
+		suffixes    bool
+		contentType string
+		accept      string
+
+		wantStatus      int
+		wantContentType string
+	}{
+		{name: "no headers", wantStatus: http.StatusOK, wantContentType: "application/json"},
+		{name: "default content type", contentType: "application/json; charset=utf-8", wantStatus: http.StatusOK, wantContentType: "application/json"},
+		{name: "registered content type", contentType: "application/octet-stream", wantStatus: http.StatusOK, wantContentType: "application/octet-stream"},
+		{name: "unsupported content type", contentType: "application/xml", wantStatus: http.StatusUnsupportedMediaType, wantContentType: "application/json"},
+		{name: "acceptable type", accept: "application/xml, application/octet-stream", wantStatus: http.StatusOK, wantContentType: "application/octet-stream"},
+		{name: "wildcard", accept: "*/*", wantStatus: http.StatusOK, wantContentType: "application/json"},
+		{name: "blank accept", accept: " ", wantStatus: http.StatusOK, wantContentType: "application/json"},
+		{name: "not acceptable", accept: "application/xml", wantStatus: http.StatusNotAcceptable, wantContentType: "application/json"},
+		{name: "structured suffix", suffixes: true, contentType: "application/vnd.myco.v1+json", accept: "application/vnd.myco.v1+json", wantStatus: http.StatusOK, wantContentType: "application/json"},
+		{name: "structured suffix not matched", contentType: "application/vnd.myco.v1+json", wantStatus: http.StatusUnsupportedMediaType, wantContentType: "application/json"},
+		{name: "lenient", lenient: true, contentType: "application/xml", accept: "application/xml", wantStatus: http.StatusOK, wantContentType: "application/json"},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			opts := []runtime.ServeMuxOption{
+				runtime.WithMarshalerOption("application/octet-stream", &runtime.ProtoMarshaller{}),
+			}
+			if !spec.lenient {
+				opts = append(opts, runtime.WithStrictContentNegotiation())
+			}
+			if spec.suffixes {
+				opts = append(opts, runtime.WithStructuredSuffixMatching())
+			}
+			mux := runtime.NewServeMux(opts...)
+			mux.Handle("POST", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				_, outbound := runtime.MarshalerForRequest(mux, r)
+				ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+				runtime.ForwardResponseMessage(ctx, mux, outbound, w, r, &pb.SimpleMessage{Id: "foo"})
+			})
+
+			r := httptest.NewRequest("POST", "http://example.com/foo", nil)
+			if spec.contentType != "" {
+				r.Header.Set("Content-Type", spec.contentType)
+			}
+			if spec.accept != "" {
+				r.Header.Set("Accept", spec.accept)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.wantStatus; got != want {
+				t.Errorf("w.Code = %d; want %d; body=%s", got, want, w.Body)
+			}
+			if got, want := w.Header().Get("Content-Type"), spec.wantContentType; got != want {
+				t.Errorf("Content-Type = %q; want %q", got, want)
+			}
+			if spec.wantStatus == http.StatusOK {
+				return
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Errorf("json.Unmarshal(%q, &body) failed with %v; want a JSON error", w.Body, err)
+			}
+		})
+	}
+}
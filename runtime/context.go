@@ -92,21 +92,46 @@ func annotateContext(ctx context.Context, mux *ServeMux, req *http.Request) (con
 			return nil, nil, status.Errorf(codes.InvalidArgument, "invalid grpc-timeout: %s", tm)
 		}
 	}
+	if name := mux.requestTimeoutHeader; name != "" {
+		if tm := req.Header.Get(name); tm != "" {
+			d, err := time.ParseDuration(tm)
+			if err != nil || d <= 0 {
+				return nil, nil, status.Errorf(codes.InvalidArgument, "invalid %s: %s", name, tm)
+			}
+			if timeout == 0 || d < timeout {
+				timeout = d
+			}
+		}
+	}
 
+	connectionHeaders := connectionHeaders(req.Header)
 	for key, vals := range req.Header {
 		for _, val := range vals {
 			key = textproto.CanonicalMIMEHeaderKey(key)
+			if connectionHeaders[key] {
+				continue
+			}
 			// For backwards-compatibility, pass through 'authorization' header with no prefix.
 			if key == "Authorization" {
 				pairs = append(pairs, "authorization", val)
 			}
-			if h, ok := mux.incomingHeaderMatcher(key); ok {
+			names := []string{key}
+			if mux.incomingHeaderMapper != nil {
+				names = mux.incomingHeaderMapper(key)
+			}
+			for _, name := range names {
+				name = textproto.CanonicalMIMEHeaderKey(name)
+				h, ok := mux.incomingHeaderMatcher(name)
+				if !ok {
+					continue
+				}
+				val := val
 				// Handles "-bin" metadata in grpc, since grpc will do another base64
 				// encode before sending to server, we need to decode it first.
-				if strings.HasSuffix(key, metadataHeaderBinarySuffix) {
+				if strings.HasSuffix(name, metadataHeaderBinarySuffix) {
 					b, err := decodeBinHeader(val)
 					if err != nil {
-						return nil, nil, status.Errorf(codes.InvalidArgument, "invalid binary header %s: %s", key, err)
+						return nil, nil, status.Errorf(codes.InvalidArgument, "invalid binary header %s: %s", name, err)
 					}
 
 					val = string(b)
@@ -115,6 +140,14 @@ func annotateContext(ctx context.Context, mux *ServeMux, req *http.Request) (con
 			}
 		}
 	}
+	if mux.requestID != nil {
+		if id, ok := RequestIDFromContext(req.Context()); ok {
+			pairs = append(pairs, strings.ToLower(mux.requestID.header), id)
+		}
+	}
+	if id := req.Header.Get(lastEventID); id != "" && mux.serverSentEvents {
+		pairs = append(pairs, strings.ToLower(lastEventID), id)
+	}
 	if host := req.Header.Get(xForwardedHost); host != "" {
 		pairs = append(pairs, strings.ToLower(xForwardedHost), host)
 	} else if req.Host != "" {
@@ -123,7 +156,8 @@ func annotateContext(ctx context.Context, mux *ServeMux, req *http.Request) (con
 
 	if addr := req.RemoteAddr; addr != "" {
 		if remoteIP, _, err := net.SplitHostPort(addr); err == nil {
-			if fwd := req.Header.Get(xForwardedFor); fwd == "" {
+			// Repeated X-Forwarded-For headers are parts of the same list.
+			if fwd := strings.Join(req.Header[xForwardedFor], ", "); fwd == "" {
 				pairs = append(pairs, strings.ToLower(xForwardedFor), remoteIP)
 			} else {
 				pairs = append(pairs, strings.ToLower(xForwardedFor), fmt.Sprintf("%s, %s", fwd, remoteIP))
@@ -133,8 +167,16 @@ func annotateContext(ctx context.Context, mux *ServeMux, req *http.Request) (con
 		}
 	}
 
+	if mux.clientIP != nil {
+		if ip := mux.clientIP.clientIP(req); ip != "" {
+			pairs = append(pairs, ClientIPMetadataKey, ip)
+		}
+	}
+
 	if timeout != 0 {
-		ctx, _ = context.WithTimeout(ctx, timeout)
+		// The timeout is released when ctx is done: the generated handlers cancel the context
+		// they annotate once they return.
+		ctx, _ = withTimeout(ctx, mux.clock, timeout)
 	}
 	if len(pairs) == 0 {
 		return ctx, nil, nil
@@ -143,6 +185,12 @@ func annotateContext(ctx context.Context, mux *ServeMux, req *http.Request) (con
 	for _, mda := range mux.metadataAnnotators {
 		md = metadata.Join(md, mda(ctx, req))
 	}
+	if mux.authorizationHandler != nil {
+		var err error
+		if md, err = handleAuthorization(ctx, mux.authorizationHandler, req, md); err != nil {
+			return nil, nil, err
+		}
+	}
 	return ctx, md, nil
 }
 
@@ -200,6 +248,34 @@ func timeoutUnitToDuration(u uint8) (d time.Duration, ok bool) {
 	return
 }
 
+// isHopByHopHeader checks whether hdr, in its canonical form, is a hop-by-hop header (RFC 7230),
+// which is only meant for the proxy the request came through.
+func isHopByHopHeader(hdr string) bool {
+	switch hdr {
+	case "Connection", "Keep-Alive", "Te", "Trailer", "Transfer-Encoding", "Upgrade":
+		return true
+	}
+	return strings.HasPrefix(hdr, "Proxy-")
+}
+
+// connectionHeaders returns the canonical names of the headers listed in the Connection header
+// of h, which are hop-by-hop headers too.
+func connectionHeaders(h http.Header) map[string]bool {
+	var names map[string]bool
+	for _, val := range h["Connection"] {
+		for _, name := range strings.Split(val, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if names == nil {
+				names = make(map[string]bool)
+			}
+			names[textproto.CanonicalMIMEHeaderKey(name)] = true
+		}
+	}
+	return names
+}
+
 // isPermanentHTTPHeader checks whether hdr belongs to the list of
 // permenant request headers maintained by IANA.
 // http://www.iana.org/assignments/message-headers/message-headers.xml
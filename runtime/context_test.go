@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -69,6 +71,124 @@ func TestAnnotateContext_ForwardsGrpcMetadata(t *testing.T) {
 	}
 }
 
+func TestAnnotateContext_IncomingHeaderMapper(t *testing.T) {
+	request, err := http.NewRequest("GET", "http://www.example.com", nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest(%q, %q, nil) failed with %v; want success", "GET", "http://www.example.com", err)
+	}
+	request.Header.Add("Authorization", "Bearer x")
+	request.Header.Add("Grpc-Metadata-Foo", "Value1")
+	request.Header.Add("Some-Irrelevant-Header", "some value")
+	mux := runtime.NewServeMux(runtime.WithIncomingHeaderMapper(func(key string) []string {
+		switch key {
+		case "Authorization":
+			return []string{key, "grpc-metadata-user-token"}
+		case "Some-Irrelevant-Header":
+			return nil
+		}
+		return []string{key}
+	}))
+	annotated, err := runtime.AnnotateContext(context.Background(), mux, request)
+	if err != nil {
+		t.Fatalf("runtime.AnnotateContext(ctx, %#v) failed with %v; want success", request, err)
+	}
+	md, _ := metadata.FromOutgoingContext(annotated)
+	for key, want := range map[string][]string{
+		"authorization":             {"Bearer x"},
+		"grpcgateway-authorization": {"Bearer x"},
+		"user-token":                {"Bearer x"},
+		"foo":                       {"Value1"},
+	} {
+		if got := md[key]; !reflect.DeepEqual(got, want) {
+			t.Errorf("md[%q] = %q; want %q", key, got, want)
+		}
+	}
+	if got, want := len(md), emptyForwardMetaCount+4; got != want {
+		t.Errorf("metadata items in context = %d want %d: %v", got, want, md)
+	}
+}
+
+func TestAnnotateContext_ForwardsRepeatedHeaders(t *testing.T) {
+	request, err := http.NewRequest("GET", "http://www.example.com", nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest(%q, %q, nil) failed with %v; want success", "GET", "http://www.example.com", err)
+	}
+	request.RemoteAddr = "192.0.2.100:25782"
+	request.Header.Add("X-Feature", "a")
+	request.Header.Add("X-Feature", "b")
+	request.Header.Add("Grpc-Metadata-Tag", "c")
+	request.Header.Add("Grpc-Metadata-Tag", "d")
+	request.Header.Add("X-Forwarded-For", "203.0.113.1")
+	request.Header.Add("X-Forwarded-For", "198.51.100.2")
+	mux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+		if key == "X-Feature" {
+			return key, true
+		}
+		return runtime.DefaultHeaderMatcher(key)
+	}))
+	annotated, err := runtime.AnnotateContext(context.Background(), mux, request)
+	if err != nil {
+		t.Fatalf("runtime.AnnotateContext(ctx, %#v) failed with %v; want success", request, err)
+	}
+	md, _ := metadata.FromOutgoingContext(annotated)
+	for key, want := range map[string][]string{
+		"x-feature":       {"a", "b"},
+		"tag":             {"c", "d"},
+		"x-forwarded-for": {"203.0.113.1, 198.51.100.2, 192.0.2.100"},
+	} {
+		if got := md[key]; !reflect.DeepEqual(got, want) {
+			t.Errorf("md[%q] = %q; want %q", key, got, want)
+		}
+	}
+}
+
+func TestAnnotateContext_StripsHopByHopHeaders(t *testing.T) {
+	request, err := http.NewRequest("GET", "http://www.example.com", nil)
+	if err != nil {
+		t.Fatalf("http.NewRequest(%q, %q, nil) failed with %v; want success", "GET", "http://www.example.com", err)
+	}
+	request.Header.Add("Connection", "keep-alive, x-feature")
+	request.Header.Add("Connection", "Grpc-Metadata-Hop")
+	request.Header.Add("Keep-Alive", "timeout=5")
+	request.Header.Add("Upgrade", "h2c")
+	request.Header.Add("Proxy-Authorization", "Basic Zm9vOmJhcg==")
+	request.Header.Add("X-Feature", "a")
+	request.Header.Add("Grpc-Metadata-Hop", "b")
+	request.Header.Add("Grpc-Metadata-Tag", "c")
+	request.Header.Add("Accept", "application/json")
+	mux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+		if key == "X-Feature" {
+			return key, true
+		}
+		return runtime.DefaultHeaderMatcher(key)
+	}))
+	annotated, err := runtime.AnnotateContext(context.Background(), mux, request)
+	if err != nil {
+		t.Fatalf("runtime.AnnotateContext(ctx, %#v) failed with %v; want success", request, err)
+	}
+	md, _ := metadata.FromOutgoingContext(annotated)
+	for key, want := range map[string][]string{
+		"tag":                {"c"},
+		"grpcgateway-accept": {"application/json"},
+	} {
+		if got := md[key]; !reflect.DeepEqual(got, want) {
+			t.Errorf("md[%q] = %q; want %q", key, got, want)
+		}
+	}
+	for _, key := range []string{
+		"grpcgateway-connection",
+		"grpcgateway-keep-alive",
+		"grpcgateway-upgrade",
+		"grpcgateway-proxy-authorization",
+		"x-feature",
+		"hop",
+	} {
+		if got, ok := md[key]; ok {
+			t.Errorf("md[%q] = %q; want no hop-by-hop header", key, got)
+		}
+	}
+}
+
 func TestAnnotateContext_ForwardGrpcBinaryMetadata(t *testing.T) {
 	ctx := context.Background()
 	request, err := http.NewRequest("GET", "http://www.example.com", nil)
@@ -195,6 +315,90 @@ func TestAnnotateContext_SupportsTimeouts(t *testing.T) {
 		}
 	}
 }
+func TestAnnotateContext_RequestTimeoutHeader(t *testing.T) {
+	defer func(timeout time.Duration) { runtime.DefaultContextTimeout = timeout }(runtime.DefaultContextTimeout)
+	runtime.DefaultContextTimeout = 0
+
+	const acceptableError = 50 * time.Millisecond
+	for _, spec := range []struct {
+		name        string
+		ctxTimeout  time.Duration
+		headers     map[string]string
+		wantErr     bool
+		wantTimeout time.Duration
+	}{
+		{
+			name: "no header",
+		},
+		{
+			name:        "header",
+			headers:     map[string]string{"X-Request-Timeout": "2s"},
+			wantTimeout: 2 * time.Second,
+		},
+		{
+			name:        "shorter context deadline",
+			ctxTimeout:  time.Second,
+			headers:     map[string]string{"X-Request-Timeout": "2s"},
+			wantTimeout: time.Second,
+		},
+		{
+			name:        "shorter grpc-timeout",
+			headers:     map[string]string{"X-Request-Timeout": "2s", "Grpc-Timeout": "1S"},
+			wantTimeout: time.Second,
+		},
+		{
+			name:    "malformed",
+			headers: map[string]string{"X-Request-Timeout": "two seconds"},
+			wantErr: true,
+		},
+		{
+			name:    "negative",
+			headers: map[string]string{"X-Request-Timeout": "-2s"},
+			wantErr: true,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			ctx := context.Background()
+			if spec.ctxTimeout != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, spec.ctxTimeout)
+				defer cancel()
+			}
+			request, err := http.NewRequest("GET", "http://example.com", nil)
+			if err != nil {
+				t.Fatalf(`http.NewRequest("GET", "http://example.com", nil) failed with %v; want success`, err)
+			}
+			for k, v := range spec.headers {
+				request.Header.Set(k, v)
+			}
+			mux := runtime.NewServeMux(runtime.WithRequestTimeoutHeader("x-request-timeout"))
+			annotated, err := runtime.AnnotateContext(ctx, mux, request)
+			if spec.wantErr {
+				if got, want := status.Code(err), codes.InvalidArgument; got != want {
+					t.Errorf("runtime.AnnotateContext(ctx, mux, %#v) failed with %v; want a %v error", request, err, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runtime.AnnotateContext(ctx, mux, %#v) failed with %v; want success", request, err)
+			}
+			deadline, ok := annotated.Deadline()
+			if spec.wantTimeout == 0 {
+				if ok {
+					t.Errorf("annotated.Deadline() = %v, true; want _, false", deadline)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("annotated.Deadline() = _, false; want _, true")
+			}
+			if got, want := time.Until(deadline), spec.wantTimeout; got-want > acceptableError || got-want < -acceptableError {
+				t.Errorf("time.Until(deadline) = %v; want %v; with error %v", got, want, acceptableError)
+			}
+		})
+	}
+}
+
 func TestAnnotateContext_SupportsCustomAnnotators(t *testing.T) {
 	md1 := func(context.Context, *http.Request) metadata.MD { return metadata.New(map[string]string{"foo": "bar"}) }
 	md2 := func(context.Context, *http.Request) metadata.MD { return metadata.New(map[string]string{"baz": "qux"}) }
@@ -216,6 +420,33 @@ func TestAnnotateContext_SupportsCustomAnnotators(t *testing.T) {
 	}
 }
 
+func TestAnnotateContext_ForwardsLastEventID(t *testing.T) {
+	request, err := http.NewRequest("GET", "http://example.com", nil)
+	if err != nil {
+		t.Fatalf(`http.NewRequest("GET", "http://example.com", nil failed with %v; want success`, err)
+	}
+	request.Header.Set("Accept", "text/event-stream")
+	request.Header.Set("Last-Event-ID", "42")
+
+	annotated, err := runtime.AnnotateContext(context.Background(), runtime.NewServeMux(runtime.WithServerSentEvents(nil)), request)
+	if err != nil {
+		t.Fatalf("runtime.AnnotateContext(ctx, %#v) failed with %v; want success", request, err)
+	}
+	md, _ := metadata.FromOutgoingContext(annotated)
+	if got, want := md["last-event-id"], []string{"42"}; !reflect.DeepEqual(got, want) {
+		t.Errorf(`md["last-event-id"] = %q; want %q`, got, want)
+	}
+
+	annotated, err = runtime.AnnotateContext(context.Background(), runtime.NewServeMux(), request)
+	if err != nil {
+		t.Fatalf("runtime.AnnotateContext(ctx, %#v) failed with %v; want success", request, err)
+	}
+	md, _ = metadata.FromOutgoingContext(annotated)
+	if got, ok := md["last-event-id"]; ok {
+		t.Errorf(`md["last-event-id"] = %q; want no value without server-sent events`, got)
+	}
+}
+
 func TestAnnotateIncomingContext_WorksWithEmpty(t *testing.T) {
 	ctx := context.Background()
 
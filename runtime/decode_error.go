@@ -0,0 +1,61 @@
+package runtime
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// WithDescriptiveDecodeErrors returns a ServeMuxOption that makes the errors of the inbound
+// marshalers returned by MarshalerForRequest mention the Content-Type of the request and the
+// marshaler selected for it, e.g. "request body with Content-Type "application/json" could not be
+// decoded by *runtime.JSONPb: ...", so that clients can diagnose a body in another format than the
+// declared one.
+func WithDescriptiveDecodeErrors() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.descriptiveDecodeErrors = true
+	}
+}
+
+// describedMarshaler wraps an inbound Marshaler so that its unmarshaling errors mention the
+// Content-Type of the request and the marshaler selected for it, which helps clients to tell a
+// malformed body from a body in another format than the declared one.
+type describedMarshaler struct {
+	Marshaler
+	contentType string
+	selected    Marshaler
+}
+
+// withDecodeErrorContext returns inbound, whose errors are described with the Content-Type of r
+// and the marshaler selected for it.
+func withDecodeErrorContext(inbound, selected Marshaler, r *http.Request) Marshaler {
+	return &describedMarshaler{Marshaler: inbound, contentType: r.Header.Get(contentTypeHeader), selected: selected}
+}
+
+// Unmarshal unmarshals data with the wrapped Marshaler and describes any error.
+func (m *describedMarshaler) Unmarshal(data []byte, v interface{}) error {
+	return m.describe(m.Marshaler.Unmarshal(data, v))
+}
+
+// NewDecoder returns a Decoder whose errors are described, except io.EOF.
+func (m *describedMarshaler) NewDecoder(r io.Reader) Decoder {
+	dec := m.Marshaler.NewDecoder(r)
+	return DecoderFunc(func(v interface{}) error {
+		err := dec.Decode(v)
+		if err == io.EOF {
+			return err
+		}
+		return m.describe(err)
+	})
+}
+
+func (m *describedMarshaler) describe(err error) error {
+	if err == nil {
+		return nil
+	}
+	contentType := fmt.Sprintf("Content-Type %q", m.contentType)
+	if m.contentType == "" {
+		contentType = "no Content-Type"
+	}
+	return fmt.Errorf("request body with %s could not be decoded by %T: %w", contentType, m.selected, err)
+}
@@ -0,0 +1,66 @@
+package runtime_test
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+)
+
+func TestWithDescriptiveDecodeErrors(t *testing.T) {
+	const xmlBody = `<SimpleMessage><id>foo</id></SimpleMessage>`
+	for _, spec := range []struct {
+		name        string
+		opts        []runtime.ServeMuxOption
+		contentType string
+		wantPrefix  string
+	}{
+		{
+			name:        "declared content type",
+			opts:        []runtime.ServeMuxOption{runtime.WithDescriptiveDecodeErrors()},
+			contentType: "application/json",
+			wantPrefix:  `request body with Content-Type "application/json" could not be decoded by *runtime.JSONPb: `,
+		},
+		{
+			name:       "no content type",
+			opts:       []runtime.ServeMuxOption{runtime.WithDescriptiveDecodeErrors()},
+			wantPrefix: `request body with no Content-Type could not be decoded by *runtime.JSONPb: `,
+		},
+		{
+			name:        "option not set",
+			contentType: "application/json",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.opts...)
+			r := httptest.NewRequest("POST", "http://example.com/foo", nil)
+			if spec.contentType != "" {
+				r.Header.Set("Content-Type", spec.contentType)
+			}
+			inbound, _ := runtime.MarshalerForRequest(mux, r)
+
+			var want string
+			if err := (&runtime.JSONPb{}).Unmarshal([]byte(xmlBody), &pb.SimpleMessage{}); err != nil {
+				want = spec.wantPrefix + err.Error()
+			} else {
+				t.Fatalf("(&runtime.JSONPb{}).Unmarshal(%q) succeeded; want an error", xmlBody)
+			}
+
+			err := inbound.NewDecoder(strings.NewReader(xmlBody)).Decode(&pb.SimpleMessage{})
+			if err == nil || err.Error() != want {
+				t.Errorf("Decode(%q) failed with %v; want %q", xmlBody, err, want)
+			}
+			err = inbound.Unmarshal([]byte(xmlBody), &pb.SimpleMessage{})
+			if err == nil || err.Error() != want {
+				t.Errorf("Unmarshal(%q) failed with %v; want %q", xmlBody, err, want)
+			}
+			// An empty body is still reported with io.EOF.
+			if err := inbound.NewDecoder(strings.NewReader("")).Decode(&pb.SimpleMessage{}); err != io.EOF {
+				t.Errorf("Decode(%q) failed with %v; want %v", "", err, io.EOF)
+			}
+		})
+	}
+}
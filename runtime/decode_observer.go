@@ -0,0 +1,158 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// maxObservedBodySize is the maximum number of bytes of a request body given to
+// a DecodeErrorObserverFunc. Longer bodies are truncated.
+const maxObservedBodySize = 64 << 10
+
+// DecodeErrorObserverFunc is called with the request, its raw body and the error
+// when the inbound marshaler fails to unmarshal a request body.
+// rawBody holds at most the first 64KiB of the body. For the messages of client streams
+// decoded by a Decoder which reports what it has buffered, like *json.Decoder, rawBody starts
+// after the last message decoded successfully.
+type DecodeErrorObserverFunc func(ctx context.Context, r *http.Request, rawBody []byte, err error)
+
+// WithDecodeErrorObserver returns a ServeMuxOption that registers fn to be called
+// whenever the inbound marshaler returned by MarshalerForRequest fails to unmarshal
+// a request body. It is called before the error is handed to the error handler,
+// which makes it suitable for logging malformed requests.
+func WithDecodeErrorObserver(fn DecodeErrorObserverFunc) ServeMuxOption {
+	return func(mux *ServeMux) {
+		mux.decodeErrorObserver = fn
+	}
+}
+
+// observedMarshaler wraps an inbound Marshaler to report unmarshaling errors
+// to a DecodeErrorObserverFunc.
+type observedMarshaler struct {
+	Marshaler
+	r       *http.Request
+	observe DecodeErrorObserverFunc
+}
+
+// Unmarshal unmarshals data with the wrapped Marshaler and reports any error.
+func (m *observedMarshaler) Unmarshal(data []byte, v interface{}) error {
+	err := m.Marshaler.Unmarshal(data, v)
+	if err != nil {
+		if len(data) > maxObservedBodySize {
+			data = data[:maxObservedBodySize]
+		}
+		m.observe(m.r.Context(), m.r, data, err)
+	}
+	return err
+}
+
+// ContentTypeFromMessage returns the content type of the wrapped Marshaler for v.
+func (m *observedMarshaler) ContentTypeFromMessage(v interface{}) string {
+	if typeMarshaler, ok := m.Marshaler.(contentTypeMarshaler); ok {
+		return typeMarshaler.ContentTypeFromMessage(v)
+	}
+	return m.ContentType()
+}
+
+func (m *observedMarshaler) Delimiter() []byte {
+	return streamDelimiter(m.Marshaler)
+}
+
+func (m *observedMarshaler) LeadingDelimiter() bool {
+	return leadingDelimiter(m.Marshaler)
+}
+
+func (m *observedMarshaler) StreamSupported() bool {
+	return streamSupported(m.Marshaler)
+}
+
+// withUnknownFields keeps observing the copy of the wrapped Marshaler, if it supports
+// unknownFieldsMarshaler.
+func (m *observedMarshaler) withUnknownFields(allow bool) Marshaler {
+	u, ok := m.Marshaler.(unknownFieldsMarshaler)
+	if !ok {
+		return m
+	}
+	return &observedMarshaler{Marshaler: u.withUnknownFields(allow), r: m.r, observe: m.observe}
+}
+
+// NewDecoder returns a Decoder which records what it reads from r, so that it can
+// be reported along with a decoding error.
+func (m *observedMarshaler) NewDecoder(r io.Reader) Decoder {
+	buf := &limitedBuffer{limit: maxObservedBodySize}
+	return &observedDecoder{
+		Decoder: m.Marshaler.NewDecoder(io.TeeReader(r, buf)),
+		r:       r,
+		buf:     buf,
+		m:       m,
+	}
+}
+
+type observedDecoder struct {
+	Decoder
+	r   io.Reader
+	buf *limitedBuffer
+	m   *observedMarshaler
+}
+
+// bufferedDecoder is implemented by decoders which read ahead, like *json.Decoder.
+type bufferedDecoder interface {
+	// Buffered returns what the decoder has read but not decoded yet.
+	Buffered() io.Reader
+}
+
+// Decode decodes v with the wrapped Decoder and reports any error. What is left
+// of the body within the size limit is read before the error is reported, since
+// the decoder may have stopped reading early. Once v is decoded, only what the
+// Decoder has read ahead is kept, so that the messages of a client stream are
+// not all recorded.
+func (d *observedDecoder) Decode(v interface{}) error {
+	err := d.Decoder.Decode(v)
+	if err == nil {
+		if b, ok := d.Decoder.(bufferedDecoder); ok {
+			n, _ := io.Copy(ioutil.Discard, b.Buffered())
+			d.buf.keepLast(int(n))
+		}
+		return nil
+	}
+	if err != io.EOF {
+		if remaining := d.buf.limit - d.buf.Len(); remaining > 0 {
+			io.CopyN(d.buf, d.r, int64(remaining))
+		}
+		d.m.observe(d.m.r.Context(), d.m.r, d.buf.Bytes(), err)
+	}
+	return err
+}
+
+// limitedBuffer is a bytes.Buffer which silently discards what is written past limit.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+	// written is the number of bytes written since the start of the buffer, discarded
+	// ones included.
+	written int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	b.written += n
+	if remaining := b.limit - b.Len(); len(p) > remaining {
+		p = p[:remaining]
+	}
+	b.Buffer.Write(p)
+	return n, nil
+}
+
+// keepLast drops what was written to the buffer but the last n bytes. The buffer is emptied if
+// some bytes were discarded, since what is written next would not follow what is kept.
+func (b *limitedBuffer) keepLast(n int) {
+	if b.written > b.Len() {
+		b.Reset()
+	} else if n < b.Len() {
+		b.Next(b.Len() - n)
+	}
+	b.written = b.Len()
+}
@@ -0,0 +1,211 @@
+package runtime_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+)
+
+func TestDecodeErrorObserver(t *testing.T) {
+	type observation struct {
+		path    string
+		rawBody string
+		err     error
+	}
+	for _, spec := range []struct {
+		name    string
+		body    string
+		wantErr bool
+		want    string
+	}{
+		{
+			name: "valid body",
+			body: `{"id": "foo"}`,
+		},
+		{
+			name:    "malformed body",
+			body:    `{"id": "foo"`,
+			wantErr: true,
+			want:    `{"id": "foo"`,
+		},
+		{
+			name:    "wrong field type",
+			body:    `{"id": 1}`,
+			wantErr: true,
+			want:    `{"id": 1}`,
+		},
+		{
+			name:    "truncated body",
+			body:    `{"id": "` + strings.Repeat("a", 128<<10),
+			wantErr: true,
+			want:    (`{"id": "` + strings.Repeat("a", 128<<10))[:64<<10],
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			var got []observation
+			mux := runtime.NewServeMux(runtime.WithDecodeErrorObserver(func(ctx context.Context, r *http.Request, rawBody []byte, err error) {
+				got = append(got, observation{path: r.URL.Path, rawBody: string(rawBody), err: err})
+			}))
+
+			for _, unmarshal := range []struct {
+				name string
+				fn   func(m runtime.Marshaler, msg *pb.SimpleMessage) error
+			}{
+				{
+					name: "Decode",
+					fn: func(m runtime.Marshaler, msg *pb.SimpleMessage) error {
+						return m.NewDecoder(strings.NewReader(spec.body)).Decode(msg)
+					},
+				},
+				{
+					name: "Unmarshal",
+					fn: func(m runtime.Marshaler, msg *pb.SimpleMessage) error {
+						return m.Unmarshal([]byte(spec.body), msg)
+					},
+				},
+			} {
+				got = nil
+				r := httptest.NewRequest("POST", "http://example.com/v1/foo", strings.NewReader(spec.body))
+				inbound, _ := runtime.MarshalerForRequest(mux, r)
+
+				var msg pb.SimpleMessage
+				err := unmarshal.fn(inbound, &msg)
+				if !spec.wantErr {
+					if err != nil {
+						t.Errorf("%s failed with %v; want success", unmarshal.name, err)
+					}
+					if len(got) != 0 {
+						t.Errorf("%s: observer called %d times; want 0", unmarshal.name, len(got))
+					}
+					continue
+				}
+
+				if err == nil {
+					t.Errorf("%s succeeded; want failure", unmarshal.name)
+					continue
+				}
+				if len(got) != 1 {
+					t.Errorf("%s: observer called %d times; want 1", unmarshal.name, len(got))
+					continue
+				}
+				if got, want := got[0].path, "/v1/foo"; got != want {
+					t.Errorf("%s: observed path = %q; want %q", unmarshal.name, got, want)
+				}
+				if got[0].rawBody != spec.want {
+					t.Errorf("%s: observed body of %d bytes = %.32q...; want %d bytes %.32q...", unmarshal.name, len(got[0].rawBody), got[0].rawBody, len(spec.want), spec.want)
+				}
+				if got[0].err != err {
+					t.Errorf("%s: observed error = %v; want %v", unmarshal.name, got[0].err, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeErrorObserverKeepsOutboundMarshaler(t *testing.T) {
+	m := &runtime.JSONPb{}
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, m),
+		runtime.WithDecodeErrorObserver(func(context.Context, *http.Request, []byte, error) {}),
+	)
+	r := httptest.NewRequest("POST", "http://example.com/v1/foo", nil)
+	if _, out := runtime.MarshalerForRequest(mux, r); out != m {
+		t.Errorf("out = %#v; want %#v", out, m)
+	}
+}
+
+func TestDecodeErrorObserverForwardsStreamFraming(t *testing.T) {
+	for _, spec := range []struct {
+		name      string
+		m         runtime.Marshaler
+		delimiter string
+		leading   bool
+		streaming bool
+	}{
+		{
+			name:      "JSONPb",
+			m:         &runtime.JSONPb{},
+			delimiter: "\n",
+			streaming: true,
+		},
+		{
+			name:      "leading delimiter",
+			m:         &jsonSeqMarshaler{},
+			delimiter: "\x1e",
+			leading:   true,
+			streaming: true,
+		},
+		{
+			name:      "not streamable",
+			m:         &documentMarshaler{},
+			delimiter: "\n",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(
+				runtime.WithMarshalerOption(runtime.MIMEWildcard, spec.m),
+				runtime.WithDecodeErrorObserver(func(context.Context, *http.Request, []byte, error) {}),
+			)
+			r := httptest.NewRequest("POST", "http://example.com/v1/foo", nil)
+			inbound, _ := runtime.MarshalerForRequest(mux, r)
+			d, ok := inbound.(runtime.LeadingDelimited)
+			if !ok {
+				t.Fatalf("inbound marshaler %#v is not a LeadingDelimited", inbound)
+			}
+			if got, want := string(d.Delimiter()), spec.delimiter; got != want {
+				t.Errorf("Delimiter() = %q; want %q", got, want)
+			}
+			if got, want := d.LeadingDelimiter(), spec.leading; got != want {
+				t.Errorf("LeadingDelimiter() = %t; want %t", got, want)
+			}
+			if got, want := inbound.(runtime.Streamable).StreamSupported(), spec.streaming; got != want {
+				t.Errorf("StreamSupported() = %t; want %t", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeErrorObserverClientStream(t *testing.T) {
+	const failing = `{"id": 1}`
+	// The messages decoded before the failing one fill more than the size limit in total.
+	for _, spec := range []struct {
+		name     string
+		previous string
+	}{
+		{
+			name:     "short messages",
+			previous: strings.Repeat(`{"id": "foo"}`+"\n", 8<<10),
+		},
+		{
+			name:     "long messages",
+			previous: strings.Repeat(`{"id": "`+strings.Repeat("a", 16<<10)+`"}`+"\n", 8),
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			var got []string
+			mux := runtime.NewServeMux(runtime.WithDecodeErrorObserver(func(ctx context.Context, r *http.Request, rawBody []byte, err error) {
+				got = append(got, string(rawBody))
+			}))
+			body := spec.previous + failing
+			r := httptest.NewRequest("POST", "http://example.com/v1/foo", strings.NewReader(body))
+			inbound, _ := runtime.MarshalerForRequest(mux, r)
+
+			dec := inbound.NewDecoder(r.Body)
+			var err error
+			for err == nil {
+				err = dec.Decode(&pb.SimpleMessage{})
+			}
+			if len(got) != 1 {
+				t.Fatalf("observer called %d times; want 1", len(got))
+			}
+			if want := "\n" + failing; got[0] != want {
+				t.Errorf("observed body of %d bytes = %.32q...; want %q", len(got[0]), got[0], want)
+			}
+		})
+	}
+}
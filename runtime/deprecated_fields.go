@@ -0,0 +1,206 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	descriptor2 "github.com/golang/protobuf/descriptor"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// WithDeprecatedFieldWarnings returns a ServeMuxOption that adds a Warning header to the responses
+// of the requests whose body sets fields marked deprecated in the descriptor of the request
+// message, at any nesting level of singular messages, to warn the clients before the fields are
+// removed, e.g.
+//
+//	Warning: 299 - "Deprecated fields used: old_name, user.nick"
+//
+// Only the fields decoded before the response headers are written are reported.
+func WithDeprecatedFieldWarnings() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.deprecatedFieldWarnings = true
+	}
+}
+
+type deprecatedFieldsKey struct{}
+
+// deprecatedFieldSet collects the deprecated fields set in the messages decoded for a request.
+type deprecatedFieldSet struct {
+	mu     sync.Mutex
+	names  []string
+	listed map[string]bool
+}
+
+func (s *deprecatedFieldSet) add(names []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range names {
+		if !s.listed[name] {
+			s.listed[name] = true
+			s.names = append(s.names, name)
+		}
+	}
+}
+
+// warning returns the value of the Warning header for the collected fields, or "" if there is none.
+func (s *deprecatedFieldSet) warning() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.names) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("299 - %q", "Deprecated fields used: "+strings.Join(s.names, ", "))
+}
+
+// withDeprecatedFieldWarnings returns r with a deprecatedFieldSet in its context, and w which adds
+// the Warning header for it to the response.
+func withDeprecatedFieldWarnings(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, *http.Request) {
+	set := &deprecatedFieldSet{listed: make(map[string]bool)}
+	r = r.WithContext(context.WithValue(r.Context(), deprecatedFieldsKey{}, set))
+	return &deprecationResponseWriter{ResponseWriter: w, set: set}, r
+}
+
+// withDeprecatedFieldCheck returns m, which reports the deprecated fields set in the messages it
+// decodes to the deprecatedFieldSet of r, if any.
+func withDeprecatedFieldCheck(m Marshaler, r *http.Request) Marshaler {
+	set, ok := r.Context().Value(deprecatedFieldsKey{}).(*deprecatedFieldSet)
+	if !ok {
+		return m
+	}
+	return &deprecationMarshaler{Marshaler: m, set: set}
+}
+
+// deprecationMarshaler wraps an inbound Marshaler to collect the deprecated fields set in the
+// messages it decodes.
+type deprecationMarshaler struct {
+	Marshaler
+	set *deprecatedFieldSet
+}
+
+func (m *deprecationMarshaler) Unmarshal(data []byte, v interface{}) error {
+	err := m.Marshaler.Unmarshal(data, v)
+	if err == nil {
+		m.check(v)
+	}
+	return err
+}
+
+func (m *deprecationMarshaler) NewDecoder(r io.Reader) Decoder {
+	d := m.Marshaler.NewDecoder(r)
+	return DecoderFunc(func(v interface{}) error {
+		err := d.Decode(v)
+		if err == nil {
+			m.check(v)
+		}
+		return err
+	})
+}
+
+func (m *deprecationMarshaler) check(v interface{}) {
+	if msg, ok := v.(proto.Message); ok {
+		m.set.add(deprecatedFields(msg, ""))
+	}
+}
+
+// deprecatedFields returns the names of the fields of msg which are set and marked deprecated in
+// its descriptor, after prefix and the path of the singular message fields they are nested in.
+func deprecatedFields(msg proto.Message, prefix string) []string {
+	dm, ok := msg.(descriptor2.Message)
+	if !ok {
+		return nil
+	}
+	v := reflect.ValueOf(msg)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	sv := v.Elem()
+	_, md := descriptor2.ForMessage(dm)
+	props := proto.GetProperties(sv.Type())
+
+	var names []string
+	for _, f := range md.GetField() {
+		fv, ok := protoFieldValue(sv, props, f)
+		if !ok || isUnsetField(fv) {
+			continue
+		}
+		name := prefix + f.GetName()
+		if f.GetOptions().GetDeprecated() {
+			names = append(names, name)
+		}
+		if f.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+			continue
+		}
+		if nested, ok := fv.Interface().(proto.Message); ok {
+			names = append(names, deprecatedFields(nested, name+".")...)
+		}
+	}
+	return names
+}
+
+// protoFieldValue returns the value of the field f of the message struct sv, whose properties
+// are props. It returns false if the field is a member of a oneof which is not set to it.
+func protoFieldValue(sv reflect.Value, props *proto.StructProperties, f *descriptor.FieldDescriptorProto) (reflect.Value, bool) {
+	if oop, ok := props.OneofTypes[f.GetName()]; ok {
+		ov := sv.Field(oop.Field)
+		if ov.IsNil() || ov.Elem().Type() != oop.Type {
+			return reflect.Value{}, false
+		}
+		return ov.Elem().Elem().Field(0), true
+	}
+	for i, p := range props.Prop {
+		if p.Tag == int(f.GetNumber()) && p.OrigName == f.GetName() {
+			return sv.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// isUnsetField tells whether v is the value of an unset field: the zero value, or an empty list or map.
+func isUnsetField(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// deprecationResponseWriter adds the Warning header for the deprecated fields of a request to its
+// response before the header is written.
+type deprecationResponseWriter struct {
+	http.ResponseWriter
+
+	set         *deprecatedFieldSet
+	wroteHeader bool
+}
+
+func (w *deprecationResponseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if warning := w.set.warning(); warning != "" {
+			w.Header().Add("Warning", warning)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *deprecationResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *deprecationResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,135 @@
+package runtime_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+)
+
+// deprecatedMessage is a message whose field old_name is deprecated, as if generated from
+//
+//	message DeprecatedMessage {
+//		string name = 1;
+//		string old_name = 2 [deprecated = true];
+//		DeprecatedMessage nested = 3;
+//	}
+type deprecatedMessage struct {
+	Name    string             `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	OldName string             `protobuf:"bytes,2,opt,name=old_name,json=oldName,proto3" json:"old_name,omitempty"`
+	Nested  *deprecatedMessage `protobuf:"bytes,3,opt,name=nested,proto3" json:"nested,omitempty"`
+}
+
+func (m *deprecatedMessage) Reset()         { *m = deprecatedMessage{} }
+func (m *deprecatedMessage) String() string { return proto.CompactTextString(m) }
+func (*deprecatedMessage) ProtoMessage()    {}
+
+func (*deprecatedMessage) Descriptor() ([]byte, []int) {
+	return deprecatedFileDescriptor, []int{0}
+}
+
+var deprecatedFileDescriptor = func() []byte {
+	fd := &descriptor.FileDescriptorProto{
+		Name:    proto.String("runtime/deprecated_fields_test.proto"),
+		Package: proto.String("grpc.gateway.runtime.test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptor.DescriptorProto{{
+			Name: proto.String("DeprecatedMessage"),
+			Field: []*descriptor.FieldDescriptorProto{
+				{
+					Name:     proto.String("name"),
+					Number:   proto.Int32(1),
+					Label:    descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Type:     descriptor.FieldDescriptorProto_TYPE_STRING.Enum(),
+					JsonName: proto.String("name"),
+				},
+				{
+					Name:     proto.String("old_name"),
+					Number:   proto.Int32(2),
+					Label:    descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Type:     descriptor.FieldDescriptorProto_TYPE_STRING.Enum(),
+					JsonName: proto.String("oldName"),
+					Options:  &descriptor.FieldOptions{Deprecated: proto.Bool(true)},
+				},
+				{
+					Name:     proto.String("nested"),
+					Number:   proto.Int32(3),
+					Label:    descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Type:     descriptor.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+					TypeName: proto.String(".grpc.gateway.runtime.test.DeprecatedMessage"),
+					JsonName: proto.String("nested"),
+				},
+			},
+		}},
+	}
+	b, err := proto.Marshal(fd)
+	if err != nil {
+		panic(err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(b)
+	zw.Close()
+	return buf.Bytes()
+}()
+
+func TestWithDeprecatedFieldWarnings(t *testing.T) {
+	for _, spec := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		body    string
+
+		want string
+	}{
+		{
+			name:    "deprecated field",
+			muxOpts: []runtime.ServeMuxOption{runtime.WithDeprecatedFieldWarnings()},
+			body:    `{"name":"foo","old_name":"bar"}`,
+			want:    `299 - "Deprecated fields used: old_name"`,
+		},
+		{
+			name:    "nested deprecated fields",
+			muxOpts: []runtime.ServeMuxOption{runtime.WithDeprecatedFieldWarnings()},
+			body:    `{"oldName":"bar","nested":{"nested":{"oldName":"baz"}}}`,
+			want:    `299 - "Deprecated fields used: old_name, nested.nested.old_name"`,
+		},
+		{
+			name:    "no deprecated field",
+			muxOpts: []runtime.ServeMuxOption{runtime.WithDeprecatedFieldWarnings()},
+			body:    `{"name":"foo","nested":{"name":"bar"}}`,
+		},
+		{
+			name: "warnings disabled",
+			body: `{"name":"foo","old_name":"bar"}`,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.muxOpts...)
+			pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+			mux.Handle("POST", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				inbound, _ := runtime.MarshalerForRequest(mux, r)
+				var msg deprecatedMessage
+				if err := inbound.NewDecoder(r.Body).Decode(&msg); err != nil {
+					t.Errorf("Decode(%q) failed with %v; want success", spec.body, err)
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader(spec.body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got := w.Header().Get("Warning"); got != spec.want {
+				t.Errorf("w.Header().Get(%q) = %q; want %q", "Warning", got, spec.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package runtime
+
+import (
+	"context"
+
+	"google.golang.org/grpc/status"
+)
+
+// ErrorBodyTemplate describes the body of error responses, for clients which expect another shape
+// than the default one. With the Key "error" and the DetailsField "-", the body is like
+// {"error": {"code": 5, "message": "not found"}}.
+type ErrorBodyTemplate struct {
+	// Key is the key of the error object in the body. If it is empty, the error object is the body.
+	Key string
+	// CodeField is the name of the field of the error object with the gRPC code. It defaults to "code".
+	CodeField string
+	// MessageField is the name of the field of the error object with the message. It defaults to "message".
+	MessageField string
+	// DetailsField is the name of the field of the error object with the details, if any.
+	// It defaults to "details".
+	DetailsField string
+}
+
+// omitField is the name of the fields of an ErrorBodyTemplate which are omitted.
+const omitField = "-"
+
+// WithErrorBodyTemplate returns a ServeMuxOption that shapes the body of the error responses of
+// DefaultHTTPError and DefaultHTTPProtoErrorHandler as described by tmpl. The bodies are kept as
+// is if the marshaler cannot marshal the shaped body, like ProtoMarshaller.
+func WithErrorBodyTemplate(tmpl ErrorBodyTemplate) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.errorBodyTemplate = &tmpl
+	}
+}
+
+// marshalStatusBody marshals the body of the error response for st, shaped by the error body
+// template of s, or def if s has none or if marshaler cannot marshal the shaped body. It returns
+// the marshaled body too.
+func (s *ServeMux) marshalStatusBody(ctx context.Context, marshaler Marshaler, st *status.Status, def interface{}) (interface{}, []byte, error) {
+	if s.errorBodyTemplate != nil {
+		body := s.errorBodyTemplate.body(st)
+		if buf, err := s.marshalErrorBody(ctx, marshaler, body); err == nil {
+			return body, buf, nil
+		}
+	}
+	buf, err := s.marshalErrorBody(ctx, marshaler, def)
+	return def, buf, err
+}
+
+// body returns the body of the error response for s.
+func (t *ErrorBodyTemplate) body(s *status.Status) interface{} {
+	obj := make(map[string]interface{})
+	if name := fieldName(t.CodeField, "code"); name != omitField {
+		obj[name] = int32(s.Code())
+	}
+	if name := fieldName(t.MessageField, "message"); name != omitField {
+		obj[name] = s.Message()
+	}
+	if details := s.Proto().GetDetails(); len(details) > 0 {
+		if name := fieldName(t.DetailsField, "details"); name != omitField {
+			obj[name] = details
+		}
+	}
+	if t.Key == "" {
+		return obj
+	}
+	return map[string]interface{}{t.Key: obj}
+}
+
+func fieldName(name, def string) string {
+	if name == "" {
+		return def
+	}
+	return name
+}
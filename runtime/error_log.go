@@ -0,0 +1,30 @@
+package runtime
+
+import (
+	"context"
+
+	"google.golang.org/grpc/peer"
+)
+
+// ErrorLoggerFunc is called with the errors of backend calls and the gRPC peer of the call, so
+// that the failures can be correlated with the backend which was hit.
+// The peer is nil if the context of the call does not have one.
+type ErrorLoggerFunc func(ctx context.Context, p *peer.Peer, err error)
+
+// WithErrorLogger returns a ServeMuxOption that gives fn the errors replied with HTTPError and
+// the errors of response streams, together with the gRPC peer in their context, if any (see
+// peer.NewContext). fn is called before the error is replied.
+func WithErrorLogger(fn ErrorLoggerFunc) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.errorLogger = fn
+	}
+}
+
+// logError gives err, an error of the call with ctx, to the error logger of s, if any.
+func (s *ServeMux) logError(ctx context.Context, err error) {
+	if s.errorLogger == nil {
+		return
+	}
+	p, _ := peer.FromContext(ctx)
+	s.errorLogger(ctx, p, err)
+}
@@ -0,0 +1,77 @@
+package runtime_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithErrorLogger(t *testing.T) {
+	backend := &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 9090}}
+	backendErr := status.Error(codes.Unavailable, "backend unavailable")
+
+	for _, spec := range []struct {
+		name   string
+		ctx    context.Context
+		stream bool
+
+		wantPeer *peer.Peer
+	}{
+		{
+			name:     "unary",
+			ctx:      peer.NewContext(context.Background(), backend),
+			wantPeer: backend,
+		},
+		{
+			name:     "stream",
+			ctx:      peer.NewContext(context.Background(), backend),
+			stream:   true,
+			wantPeer: backend,
+		},
+		{
+			name: "no peer",
+			ctx:  context.Background(),
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			var calls int
+			var gotPeer *peer.Peer
+			var gotErr error
+			mux := runtime.NewServeMux(runtime.WithErrorLogger(func(ctx context.Context, p *peer.Peer, err error) {
+				calls++
+				gotPeer, gotErr = p, err
+			}))
+
+			ctx := runtime.NewServerMetadataContext(spec.ctx, runtime.ServerMetadata{})
+			req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			w := httptest.NewRecorder()
+			if spec.stream {
+				recv := func() (proto.Message, error) { return nil, backendErr }
+				runtime.ForwardResponseStream(ctx, mux, &runtime.JSONPb{}, w, req, recv)
+			} else {
+				runtime.HTTPError(ctx, mux, &runtime.JSONPb{}, w, req, backendErr)
+			}
+
+			if got, want := w.Code, http.StatusServiceUnavailable; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+			if calls != 1 {
+				t.Fatalf("the error logger was called %d times; want once", calls)
+			}
+			if gotPeer != spec.wantPeer {
+				t.Errorf("peer = %v; want %v", gotPeer, spec.wantPeer)
+			}
+			if gotErr != backendErr {
+				t.Errorf("err = %v; want %v", gotErr, backendErr)
+			}
+		})
+	}
+}
@@ -2,9 +2,11 @@ package runtime
 
 import (
 	"context"
+	"encoding/base64"
 	"io"
 	"net/http"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/grpc-ecosystem/grpc-gateway/internal"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/grpclog"
@@ -94,8 +96,40 @@ var (
 	OtherErrorHandler = DefaultOtherErrorHandler
 )
 
+// WithErrorMarshaler returns a ServeMuxOption that makes the error handlers marshal the errors
+// with m, regardless of the marshaler negotiated for the request, e.g. to reply to the errors in
+// readable JSON while the successful responses are in binary protobuf. By default the errors are
+// marshaled with the negotiated marshaler. The errors returned in the middle of response streams
+// are still marshaled with the marshaler of the stream.
+func WithErrorMarshaler(m Marshaler) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.errorMarshaler = m
+	}
+}
+
+// errorMarshalerFor returns the marshaler of the errors of a request with the negotiated
+// marshaler, as set with WithErrorMarshaler.
+func (s *ServeMux) errorMarshalerFor(marshaler Marshaler) Marshaler {
+	if s.errorMarshaler == nil {
+		return marshaler
+	}
+	if s.textCharset != nil {
+		return &charsetMarshaler{m: s.errorMarshaler, opts: s.textCharset}
+	}
+	return s.errorMarshaler
+}
+
 // MuxOrGlobalHTTPError uses the mux-configured error handler, falling back to GlobalErrorHandler.
 func MuxOrGlobalHTTPError(ctx context.Context, mux *ServeMux, marshaler Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if tooLarge := requestBodyTooLarge(r); tooLarge != nil {
+		err = tooLarge
+		w = &statusResponseWriter{ResponseWriter: w, code: http.StatusRequestEntityTooLarge}
+	}
+	mux.logError(ctx, err)
+	if retryFailure(r, err) {
+		return
+	}
+	marshaler = mux.errorMarshalerFor(marshaler)
 	if mux.protoErrorHandler != nil {
 		mux.protoErrorHandler(ctx, mux, marshaler, w, r, err)
 	} else {
@@ -117,6 +151,12 @@ func DefaultHTTPError(ctx context.Context, mux *ServeMux, marshaler Marshaler, w
 		s = status.New(codes.Unknown, err.Error())
 	}
 
+	md, ok := ServerMetadataFromContext(ctx)
+	if !ok {
+		grpclog.Infof("Failed to extract ServerMetadata from context")
+	}
+	setStatusDetailsHeader(w, s)
+
 	w.Header().Del("Trailer")
 
 	contentType := marshaler.ContentType()
@@ -129,14 +169,12 @@ func DefaultHTTPError(ctx context.Context, mux *ServeMux, marshaler Marshaler, w
 	}
 	w.Header().Set("Content-Type", contentType)
 
-	body := &internal.Error{
+	body, buf, merr := mux.marshalStatusBody(ctx, marshaler, s, &internal.Error{
 		Error:   s.Message(),
 		Message: s.Message(),
 		Code:    int32(s.Code()),
 		Details: s.Proto().GetDetails(),
-	}
-
-	buf, merr := marshaler.Marshal(body)
+	})
 	if merr != nil {
 		grpclog.Infof("Failed to marshal error message %q: %v", body, merr)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -146,14 +184,11 @@ func DefaultHTTPError(ctx context.Context, mux *ServeMux, marshaler Marshaler, w
 		return
 	}
 
-	md, ok := ServerMetadataFromContext(ctx)
-	if !ok {
-		grpclog.Infof("Failed to extract ServerMetadata from context")
-	}
-
 	handleForwardResponseServerMetadata(w, mux, md)
+	md = mux.limitTrailers(md)
 	handleForwardResponseTrailerHeader(w, md)
-	st := HTTPStatusFromCode(s.Code())
+	mux.handleGRPCMessageHeader(w, s)
+	st := mux.httpStatus(s)
 	w.WriteHeader(st)
 	if _, err := w.Write(buf); err != nil {
 		grpclog.Infof("Failed to write response: %v", err)
@@ -167,3 +202,19 @@ func DefaultHTTPError(ctx context.Context, mux *ServeMux, marshaler Marshaler, w
 func DefaultOtherErrorHandler(w http.ResponseWriter, _ *http.Request, msg string, code int) {
 	http.Error(w, msg, code)
 }
+
+// setStatusDetailsHeader sets the "Grpc-Status-Details-Bin" header of the response to the
+// base64-encoded google.rpc.Status of s if it has details, so that they are not lost for proxies
+// in front of the gateway, such as another gateway.
+func setStatusDetailsHeader(w http.ResponseWriter, s *status.Status) {
+	pb := s.Proto()
+	if len(pb.GetDetails()) == 0 {
+		return
+	}
+	b, err := proto.Marshal(pb)
+	if err != nil {
+		grpclog.Infof("Failed to marshal status details: %v", err)
+		return
+	}
+	w.Header().Set("Grpc-Status-Details-Bin", base64.RawStdEncoding.EncodeToString(b))
+}
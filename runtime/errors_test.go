@@ -2,6 +2,7 @@ package runtime_test
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,8 +10,13 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/internal"
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -100,3 +106,214 @@ func TestDefaultHTTPError(t *testing.T) {
 		}
 	}
 }
+
+func TestHTTPErrorStatusDetailsHeader(t *testing.T) {
+	withDetails, _ := status.New(codes.FailedPrecondition, "upstream failure").WithDetails(
+		&errdetails.PreconditionFailure{},
+	)
+	for _, spec := range []struct {
+		name    string
+		handler runtime.ProtoErrorHandlerFunc
+	}{
+		{name: "DefaultHTTPError", handler: runtime.DefaultHTTPError},
+		{name: "DefaultHTTPProtoErrorHandler", handler: runtime.DefaultHTTPProtoErrorHandler},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("", "", nil)
+			spec.handler(ctx, runtime.NewServeMux(), &runtime.JSONPb{}, w, req, withDetails.Err())
+
+			header := w.Header().Get("Grpc-Status-Details-Bin")
+			b, err := base64.RawStdEncoding.DecodeString(header)
+			if err != nil {
+				t.Fatalf("base64 decoding of %q failed with %v; want success", header, err)
+			}
+			var got spb.Status
+			if err := proto.Unmarshal(b, &got); err != nil {
+				t.Fatalf("proto.Unmarshal(%q) failed with %v; want success", b, err)
+			}
+			if !proto.Equal(&got, withDetails.Proto()) {
+				t.Errorf("Grpc-Status-Details-Bin = %v; want %v", &got, withDetails.Proto())
+			}
+
+			w = httptest.NewRecorder()
+			spec.handler(ctx, runtime.NewServeMux(), &runtime.JSONPb{}, w, req, status.Error(codes.NotFound, "not found"))
+			if h := w.Header().Get("Grpc-Status-Details-Bin"); h != "" {
+				t.Errorf("Grpc-Status-Details-Bin = %q; want no header for a status without details", h)
+			}
+		})
+	}
+}
+
+func TestHTTPErrorBodyTemplate(t *testing.T) {
+	withDetails, _ := status.New(codes.FailedPrecondition, "precondition failed").WithDetails(
+		&errdetails.PreconditionFailure{},
+	)
+	for _, spec := range []struct {
+		name string
+		tmpl runtime.ErrorBodyTemplate
+		err  error
+		want string
+	}{
+		{
+			name: "nested without details",
+			tmpl: runtime.ErrorBodyTemplate{Key: "error", DetailsField: "-"},
+			err:  withDetails.Err(),
+			want: `{"error":{"code":9,"message":"precondition failed"}}`,
+		},
+		{
+			name: "renamed fields",
+			tmpl: runtime.ErrorBodyTemplate{CodeField: "status", MessageField: "reason", DetailsField: "info"},
+			err:  withDetails.Err(),
+			want: `{"info":[{"@type":"type.googleapis.com/google.rpc.PreconditionFailure"}],"reason":"precondition failed","status":9}`,
+		},
+		{
+			name: "no details",
+			tmpl: runtime.ErrorBodyTemplate{Key: "error"},
+			err:  status.Error(codes.NotFound, "not found"),
+			want: `{"error":{"code":5,"message":"not found"}}`,
+		},
+	} {
+		for _, handler := range []struct {
+			name string
+			fn   runtime.ProtoErrorHandlerFunc
+		}{
+			{name: "DefaultHTTPError", fn: runtime.DefaultHTTPError},
+			{name: "DefaultHTTPProtoErrorHandler", fn: runtime.DefaultHTTPProtoErrorHandler},
+		} {
+			t.Run(spec.name+"/"+handler.name, func(t *testing.T) {
+				ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest("", "", nil)
+				mux := runtime.NewServeMux(runtime.WithErrorBodyTemplate(spec.tmpl))
+				handler.fn(ctx, mux, &runtime.JSONPb{}, w, req, spec.err)
+
+				if got := w.Body.String(); got != spec.want {
+					t.Errorf("w.Body = %s; want %s", got, spec.want)
+				}
+				if got, want := w.Code, runtime.HTTPStatusFromCode(status.Code(spec.err)); got != want {
+					t.Errorf("w.Code = %d; want %d", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestHTTPErrorBodyTemplateProtoMarshaler(t *testing.T) {
+	err := status.Error(codes.NotFound, "not found")
+	for _, spec := range []struct {
+		name string
+		fn   runtime.ProtoErrorHandlerFunc
+		want proto.Message
+	}{
+		{
+			name: "DefaultHTTPError",
+			fn:   runtime.DefaultHTTPError,
+			want: &internal.Error{Error: "not found", Message: "not found", Code: int32(codes.NotFound)},
+		},
+		{
+			name: "DefaultHTTPProtoErrorHandler",
+			fn:   runtime.DefaultHTTPProtoErrorHandler,
+			want: status.Convert(err).Proto(),
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("", "", nil)
+			mux := runtime.NewServeMux(runtime.WithErrorBodyTemplate(runtime.ErrorBodyTemplate{Key: "error"}))
+			spec.fn(ctx, mux, &runtime.ProtoMarshaller{}, w, req, err)
+
+			if got, want := w.Code, http.StatusNotFound; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+			// The template cannot be marshaled into a protobuf message, so the default body is sent.
+			got := proto.Clone(spec.want)
+			got.Reset()
+			if err := proto.Unmarshal(w.Body.Bytes(), got); err != nil {
+				t.Fatalf("proto.Unmarshal(%q, got) failed with %v; want success", w.Body.Bytes(), err)
+			}
+			if !proto.Equal(got, spec.want) {
+				t.Errorf("w.Body = %v; want %v", got, spec.want)
+			}
+		})
+	}
+}
+
+func TestWithErrorMarshaler(t *testing.T) {
+	for _, spec := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		id      string
+
+		wantCode        int
+		wantContentType string
+	}{
+		{
+			name:            "binary success",
+			muxOpts:         []runtime.ServeMuxOption{runtime.WithErrorMarshaler(&runtime.JSONPb{})},
+			id:              "foo",
+			wantCode:        http.StatusOK,
+			wantContentType: "application/octet-stream",
+		},
+		{
+			name:            "JSON error",
+			muxOpts:         []runtime.ServeMuxOption{runtime.WithErrorMarshaler(&runtime.JSONPb{})},
+			wantCode:        http.StatusNotFound,
+			wantContentType: "application/json",
+		},
+		{
+			name:            "negotiated error marshaler",
+			wantCode:        http.StatusNotFound,
+			wantContentType: "application/octet-stream",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			opts := append([]runtime.ServeMuxOption{
+				runtime.WithMarshalerOption("application/octet-stream", &runtime.ProtoMarshaller{}),
+			}, spec.muxOpts...)
+			mux := runtime.NewServeMux(opts...)
+			pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+			mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+				_, outbound := runtime.MarshalerForRequest(mux, r)
+				if spec.id == "" {
+					runtime.HTTPError(ctx, mux, outbound, w, r, status.Error(codes.NotFound, "not found"))
+					return
+				}
+				runtime.ForwardResponseMessage(ctx, mux, outbound, w, r, &pb.SimpleMessage{Id: spec.id})
+			})
+
+			r := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			r.Header.Set("Accept", "application/octet-stream")
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.wantCode; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+			if got, want := w.Header().Get("Content-Type"), spec.wantContentType; got != want {
+				t.Errorf("Content-Type = %q; want %q", got, want)
+			}
+			switch {
+			case spec.id != "":
+				var got pb.SimpleMessage
+				if err := proto.Unmarshal(w.Body.Bytes(), &got); err != nil {
+					t.Fatalf("proto.Unmarshal(%q) failed with %v; want success", w.Body.Bytes(), err)
+				}
+				if got.Id != spec.id {
+					t.Errorf("got.Id = %q; want %q", got.Id, spec.id)
+				}
+			case spec.wantContentType == "application/json":
+				var body map[string]interface{}
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatalf("json.Unmarshal(%q) failed with %v; want success", w.Body.Bytes(), err)
+				}
+				if got, want := body["message"], "not found"; got != want {
+					t.Errorf("body[%q] = %v; want %q", "message", got, want)
+				}
+			}
+		})
+	}
+}
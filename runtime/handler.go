@@ -1,27 +1,63 @@
 package runtime
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/textproto"
+	"reflect"
+	"strconv"
+	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/grpc-ecosystem/grpc-gateway/internal"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/grpclog"
+	"google.golang.org/grpc/status"
 )
 
 var errEmptyResponse = errors.New("empty response")
 
+const responseTimeTrailer = "X-Response-Time-Ms"
+
+type requestStartKey struct{}
+
 // ForwardResponseStream forwards the stream from gRPC server to REST client.
+// If marshaler is a Streamable which does not support streaming, the request is replied with an
+// "Unimplemented" error instead.
 func ForwardResponseStream(ctx context.Context, mux *ServeMux, marshaler Marshaler, w http.ResponseWriter, req *http.Request, recv func() (proto.Message, error), opts ...func(context.Context, http.ResponseWriter, proto.Message) error) {
+	if !streamSupported(marshaler) {
+		msg := fmt.Sprintf("streaming responses are not supported for Content-Type %q", marshaler.ContentType())
+		HTTPError(ctx, mux, marshaler, w, req, status.Error(codes.Unimplemented, msg))
+		return
+	}
+	buffered := false
+	if req.ProtoMajor == 1 && req.ProtoMinor == 0 {
+		switch mux.http10Streaming {
+		case HTTP10StreamingReject:
+			HTTPError(ctx, mux, marshaler, w, req, status.Error(codes.Unimplemented, "streaming responses are not supported over HTTP/1.0"))
+			return
+		case HTTP10StreamingBuffer:
+			bw := &bufferedResponseWriter{ResponseWriter: w, code: http.StatusOK}
+			defer bw.writeBuffered()
+			w = bw
+			buffered = true
+		}
+	}
+
 	f, ok := w.(http.Flusher)
 	if !ok {
-		grpclog.Infof("Flush not supported in %T", w)
-		http.Error(w, "unexpected type of web server", http.StatusInternalServerError)
-		return
+		if mux.streamFlush == streamFlushDefault {
+			grpclog.Infof("Flush not supported in %T", w)
+			http.Error(w, "unexpected type of web server", http.StatusInternalServerError)
+			return
+		}
+		f = nopFlusher{}
 	}
 
 	md, ok := ServerMetadataFromContext(ctx)
@@ -30,22 +66,47 @@ func ForwardResponseStream(ctx context.Context, mux *ServeMux, marshaler Marshal
 		http.Error(w, "unexpected error", http.StatusInternalServerError)
 		return
 	}
-	handleForwardResponseServerMetadata(w, mux, md)
+	forwarded := handleForwardResponseServerMetadata(w, mux, md)
+	if mux.timingTrailers {
+		start, ok := req.Context().Value(requestStartKey{}).(time.Time)
+		if !ok {
+			start = mux.clock.Now()
+		}
+		w.Header().Add("Trailer", responseTimeTrailer)
+		defer func() {
+			elapsed := mux.clock.Now().Sub(start)
+			w.Header().Set(responseTimeTrailer, strconv.FormatInt(int64(elapsed/time.Millisecond), 10))
+		}()
+	}
 
-	w.Header().Set("Transfer-Encoding", "chunked")
-	w.Header().Set("Content-Type", marshaler.ContentType())
+	sse := mux.acceptsServerSentEvents(req)
+	if !buffered {
+		w.Header().Set("Transfer-Encoding", "chunked")
+	}
+	if sse {
+		w.Header().Set("Content-Type", sseContentType)
+	} else {
+		w.Header().Set("Content-Type", marshaler.ContentType())
+	}
 	if err := handleForwardResponseOptions(ctx, w, nil, opts); err != nil {
 		HTTPError(ctx, mux, marshaler, w, req, err)
 		return
 	}
+	dedupeResponseHeaders(w, forwarded)
 
-	var delimiter []byte
-	if d, ok := marshaler.(Delimited); ok {
-		delimiter = d.Delimiter()
-	} else {
-		delimiter = []byte("\n")
+	delimiter, leading := streamFraming(mux, marshaler)
+	if mux.streamFirstByteTimeout > 0 {
+		recv = recvFirstWithTimeout(mux.clock, mux.streamFirstByteTimeout, recv)
 	}
 
+	if sse && mux.sseKeepAlive > 0 && !buffered {
+		k := newSSEKeepAlive(w, f, mux.clock, mux.sseKeepAlive)
+		defer k.stop()
+		w, f = k, k
+	}
+
+	// The last record, e.g. an error, is flushed with the end of the stream.
+	defer f.Flush()
 	var wroteHeader bool
 	for {
 		resp, err := recv()
@@ -64,7 +125,7 @@ func ForwardResponseStream(ctx context.Context, mux *ServeMux, marshaler Marshal
 		var buf []byte
 		switch {
 		case resp == nil:
-			buf, err = marshaler.Marshal(errorChunk(streamError(ctx, mux.streamErrorHandler, errEmptyResponse)))
+			buf, err = marshaler.Marshal(errorChunk(mux, streamError(ctx, mux, errEmptyResponse)))
 		default:
 			result := map[string]interface{}{"result": resp}
 			if rb, ok := resp.(responseBody); ok {
@@ -79,20 +140,58 @@ func ForwardResponseStream(ctx context.Context, mux *ServeMux, marshaler Marshal
 			handleForwardResponseStreamError(ctx, wroteHeader, marshaler, w, req, mux, err)
 			return
 		}
+		if sse {
+			var id string
+			if mux.sseEventID != nil && resp != nil {
+				id = mux.sseEventID(resp)
+			}
+			if err = writeServerSentEvent(w, "", id, buf); err != nil {
+				grpclog.Infof("Failed to send event: %v", err)
+				return
+			}
+			wroteHeader = true
+			if mux.streamFlush != streamFlushEnd {
+				f.Flush()
+			}
+			continue
+		}
+		if mux.lengthPrefixedStreaming {
+			buf = lengthPrefixed(buf)
+		}
+		if leading {
+			if _, err = w.Write(delimiter); err != nil {
+				grpclog.Infof("Failed to send delimiter chunk: %v", err)
+				return
+			}
+		}
 		if _, err = w.Write(buf); err != nil {
 			grpclog.Infof("Failed to send response chunk: %v", err)
 			return
 		}
 		wroteHeader = true
-		if _, err = w.Write(delimiter); err != nil {
-			grpclog.Infof("Failed to send delimiter chunk: %v", err)
-			return
+		if !leading {
+			if _, err = w.Write(delimiter); err != nil {
+				grpclog.Infof("Failed to send delimiter chunk: %v", err)
+				return
+			}
+		}
+		if mux.streamFlush != streamFlushEnd {
+			f.Flush()
 		}
-		f.Flush()
 	}
 }
 
-func handleForwardResponseServerMetadata(w http.ResponseWriter, mux *ServeMux, md ServerMetadata) {
+// nopFlusher is the http.Flusher of http.ResponseWriters which cannot be flushed.
+type nopFlusher struct{}
+
+func (nopFlusher) Flush() {}
+
+// handleForwardResponseServerMetadata adds the header metadata of md to the headers of w. With
+// WithDedupeResponseHeaders, it returns the headers it added.
+func handleForwardResponseServerMetadata(w http.ResponseWriter, mux *ServeMux, md ServerMetadata) http.Header {
+	if mux.dedupeResponseHeaders {
+		return forwardDedupedServerMetadata(w, mux, md)
+	}
 	for k, vs := range md.HeaderMD {
 		if h, ok := mux.outgoingHeaderMatcher(k); ok {
 			for _, v := range vs {
@@ -100,6 +199,7 @@ func handleForwardResponseServerMetadata(w http.ResponseWriter, mux *ServeMux, m
 			}
 		}
 	}
+	return nil
 }
 
 func handleForwardResponseTrailerHeader(w http.ResponseWriter, md ServerMetadata) {
@@ -131,7 +231,24 @@ func ForwardResponseMessage(ctx context.Context, mux *ServeMux, marshaler Marsha
 		grpclog.Infof("Failed to extract ServerMetadata from context")
 	}
 
-	handleForwardResponseServerMetadata(w, mux, md)
+	if isNilMessage(resp) {
+		switch mux.nilResponseBehavior {
+		case NilResponseNull:
+			resp = nil
+		case NilResponseError:
+			HTTPError(ctx, mux, marshaler, w, req, status.Error(codes.Internal, "nil response message"))
+			return
+		default:
+			resp = emptyMessage(resp)
+		}
+	}
+
+	if _, ok := resp.(*httpbody.HttpBody); ok {
+		md = handleDownloadFilename(w, md)
+	}
+	code, md := mux.statusFromMetadata(md)
+	forwarded := handleForwardResponseServerMetadata(w, mux, md)
+	md = mux.limitTrailers(md)
 	handleForwardResponseTrailerHeader(w, md)
 
 	contentType := marshaler.ContentType()
@@ -147,10 +264,23 @@ func ForwardResponseMessage(ctx context.Context, mux *ServeMux, marshaler Marsha
 		HTTPError(ctx, mux, marshaler, w, req, err)
 		return
 	}
+	dedupeResponseHeaders(w, forwarded)
+	if mux.paginationLinks != nil && resp != nil {
+		handlePaginationLinks(ctx, w, req, mux, resp)
+	}
+	if mux.responseValidator != nil && resp != nil {
+		if err := mux.responseValidator(resp); err != nil {
+			grpclog.Infof("Response validation failed: %v", err)
+			HTTPError(ctx, mux, marshaler, w, req, status.Errorf(codes.Internal, "invalid response: %v", err))
+			return
+		}
+	}
 	var buf []byte
 	var err error
 	if rb, ok := resp.(responseBody); ok {
 		buf, err = marshaler.Marshal(rb.XXX_ResponseBody())
+	} else if resp == nil {
+		buf, err = marshaler.Marshal(nil)
 	} else {
 		buf, err = marshaler.Marshal(resp)
 	}
@@ -160,6 +290,9 @@ func ForwardResponseMessage(ctx context.Context, mux *ServeMux, marshaler Marsha
 		return
 	}
 
+	if !handleOperationLocation(w, mux, resp) && code != 0 {
+		w.WriteHeader(code)
+	}
 	if _, err = w.Write(buf); err != nil {
 		grpclog.Infof("Failed to write response: %v", err)
 	}
@@ -167,6 +300,64 @@ func ForwardResponseMessage(ctx context.Context, mux *ServeMux, marshaler Marsha
 	handleForwardResponseTrailer(w, md)
 }
 
+// WriteResponse writes "msg" to "w" as the response to "r", without calling any gRPC server.
+// The message goes through the same steps as the responses of the handlers registered to "mux":
+// it is marshaled with the outbound marshaler chosen for "r", and the forward response options
+// of "mux" are applied. It allows middleware to respond directly, e.g. with a cached message.
+func WriteResponse(ctx context.Context, w http.ResponseWriter, r *http.Request, mux *ServeMux, msg proto.Message) {
+	if _, ok := ServerMetadataFromContext(ctx); !ok {
+		ctx = NewServerMetadataContext(ctx, ServerMetadata{})
+	}
+	_, outboundMarshaler := MarshalerForRequest(mux, r)
+	ForwardResponseMessage(ctx, mux, outboundMarshaler, w, r, msg, mux.GetForwardResponseOptions()...)
+}
+
+// bufferedResponseWriter holds a response until writeBuffered is called,
+// so that it can be sent with a Content-Length.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+
+	buf  bytes.Buffer
+	code int
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	w.code = code
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
+
+// Flush does nothing, since the response is only sent by writeBuffered.
+func (w *bufferedResponseWriter) Flush() {}
+
+// writeBuffered sends the held response.
+func (w *bufferedResponseWriter) writeBuffered() {
+	w.Header().Set("Content-Length", strconv.Itoa(w.buf.Len()))
+	w.ResponseWriter.WriteHeader(w.code)
+	if _, err := w.ResponseWriter.Write(w.buf.Bytes()); err != nil {
+		grpclog.Infof("Failed to write response: %v", err)
+	}
+}
+
+// isNilMessage returns true if msg is nil or a nil pointer.
+func isNilMessage(msg proto.Message) bool {
+	if msg == nil {
+		return true
+	}
+	v := reflect.ValueOf(msg)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// emptyMessage returns an empty message of the type of the nil message msg.
+func emptyMessage(msg proto.Message) proto.Message {
+	if msg == nil {
+		return &empty.Empty{}
+	}
+	return reflect.New(reflect.TypeOf(msg).Elem()).Interface().(proto.Message)
+}
+
 func handleForwardResponseOptions(ctx context.Context, w http.ResponseWriter, resp proto.Message, opts []func(context.Context, http.ResponseWriter, proto.Message) error) error {
 	if len(opts) == 0 {
 		return nil
@@ -181,32 +372,132 @@ func handleForwardResponseOptions(ctx context.Context, w http.ResponseWriter, re
 }
 
 func handleForwardResponseStreamError(ctx context.Context, wroteHeader bool, marshaler Marshaler, w http.ResponseWriter, req *http.Request, mux *ServeMux, err error) {
-	serr := streamError(ctx, mux.streamErrorHandler, err)
+	mux.logError(ctx, err)
+	serr := streamError(ctx, mux, err)
 	if !wroteHeader {
 		w.WriteHeader(int(serr.HttpCode))
 	}
-	buf, merr := marshaler.Marshal(errorChunk(serr))
+	buf, merr := marshaler.Marshal(errorChunk(mux, serr))
 	if merr != nil {
 		grpclog.Infof("Failed to marshal an error: %v", merr)
 		return
 	}
-	if _, werr := w.Write(buf); werr != nil {
+	if mux.acceptsServerSentEvents(req) {
+		if werr := writeServerSentEvent(w, "error", "", buf); werr != nil {
+			grpclog.Infof("Failed to notify error to client: %v", werr)
+		}
+		return
+	}
+	// The error is delimited like the other records, so that clients can tell a complete
+	// error record from a truncated stream.
+	if mux.lengthPrefixedStreaming {
+		buf = lengthPrefixed(buf)
+	}
+	delimiter, leading := streamFraming(mux, marshaler)
+	// The delimiter may be shared by the marshaler with other streams, so the record is built
+	// in a new slice.
+	record := make([]byte, 0, len(delimiter)+len(buf))
+	if leading {
+		record = append(record, delimiter...)
+	}
+	record = append(record, buf...)
+	if !leading {
+		record = append(record, delimiter...)
+	}
+	if _, werr := w.Write(record); werr != nil {
 		grpclog.Infof("Failed to notify error to client: %v", werr)
 		return
 	}
 }
 
+// recvFirstWithTimeout returns a recv which fails with a "DeadlineExceeded" error if the first
+// message is not received by recv within timeout. The pending call of recv is then left to
+// return once the context of the stream is done.
+func recvFirstWithTimeout(clock Clock, timeout time.Duration, recv func() (proto.Message, error)) func() (proto.Message, error) {
+	first := true
+	return func() (proto.Message, error) {
+		if !first {
+			return recv()
+		}
+		first = false
+
+		type result struct {
+			msg proto.Message
+			err error
+		}
+		expired := make(chan struct{})
+		t := clock.AfterFunc(timeout, func() { close(expired) })
+		received := make(chan result, 1)
+		go func() {
+			msg, err := recv()
+			received <- result{msg: msg, err: err}
+		}()
+		select {
+		case res := <-received:
+			t.Stop()
+			return res.msg, res.err
+		case <-expired:
+			return nil, status.Errorf(codes.DeadlineExceeded, "no response message received within %v", timeout)
+		}
+	}
+}
+
+// streamDelimiter returns the delimiter written between the records of a stream.
+func streamDelimiter(marshaler Marshaler) []byte {
+	if d, ok := marshaler.(Delimited); ok {
+		return d.Delimiter()
+	}
+	return []byte("\n")
+}
+
+// leadingDelimiter returns true if the delimiter is written before each record of a stream
+// rather than after it.
+func leadingDelimiter(marshaler Marshaler) bool {
+	d, ok := marshaler.(LeadingDelimited)
+	return ok && d.LeadingDelimiter()
+}
+
+// streamSupported returns false if marshaler cannot marshal the records of a stream.
+func streamSupported(marshaler Marshaler) bool {
+	st, ok := marshaler.(Streamable)
+	return !ok || st.StreamSupported()
+}
+
+// streamFraming returns the delimiter of the records of streams and whether it is written before
+// each record rather than after it.
+func streamFraming(mux *ServeMux, marshaler Marshaler) (delimiter []byte, leading bool) {
+	if mux.lengthPrefixedStreaming {
+		return []byte("\n"), false
+	}
+	return streamDelimiter(marshaler), leadingDelimiter(marshaler)
+}
+
+// lengthPrefixed returns the record buf prefixed with its length, as set by
+// WithLengthPrefixedStreaming.
+func lengthPrefixed(buf []byte) []byte {
+	prefixed := strconv.AppendInt(nil, int64(len(buf)), 10)
+	prefixed = append(prefixed, '\n')
+	return append(prefixed, buf...)
+}
+
 // streamError returns the payload for the final message in a response stream
 // that represents the given err.
-func streamError(ctx context.Context, errHandler StreamErrorHandlerFunc, err error) *StreamError {
-	serr := errHandler(ctx, err)
-	if serr != nil {
-		return serr
+func streamError(ctx context.Context, mux *ServeMux, err error) *StreamError {
+	serr := mux.streamErrorHandler(ctx, err)
+	if serr == nil {
+		// TODO: log about misbehaving stream error handler?
+		serr = DefaultHTTPStreamErrorHandler(ctx, err)
 	}
-	// TODO: log about misbehaving stream error handler?
-	return DefaultHTTPStreamErrorHandler(ctx, err)
+	mapStreamErrorStatus(mux, serr, err)
+	return serr
 }
 
-func errorChunk(err *StreamError) map[string]proto.Message {
-	return map[string]proto.Message{"error": (*internal.StreamError)(err)}
+// errorChunk returns the record of a stream which reports err. It has the marker set by
+// WithStreamErrorMarker, if any.
+func errorChunk(mux *ServeMux, err *StreamError) map[string]interface{} {
+	chunk := map[string]interface{}{"error": (*internal.StreamError)(err)}
+	if mux.streamErrorMarker != "" {
+		chunk[mux.streamErrorMarker] = true
+	}
+	return chunk
 }
@@ -0,0 +1,41 @@
+package runtime
+
+import (
+	"sort"
+)
+
+// HandlerInfo describes a handler registered with a ServeMux.
+type HandlerInfo struct {
+	// Method is the HTTP method the handler serves.
+	Method string
+	// Pattern is the path pattern the handler serves.
+	Pattern Pattern
+	// Path is the string representation of Pattern, like "/v1/{name=messages/*}".
+	Path string
+	// Fields are the names of the fields captured by Pattern, in the order of the path.
+	Fields []string
+}
+
+// Handlers returns the handlers registered with s, e.g. to serve a table of the routes.
+// They are ordered by method, and then in the order they are tried to match requests.
+// The returned slice is a copy which can be modified without changing s.
+func (s *ServeMux) Handlers() []HandlerInfo {
+	methods := make([]string, 0, len(s.handlers))
+	for m := range s.handlers {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+
+	var infos []HandlerInfo
+	for _, m := range methods {
+		for _, h := range s.handlers[m] {
+			infos = append(infos, HandlerInfo{
+				Method:  m,
+				Pattern: h.pat,
+				Path:    h.pat.String(),
+				Fields:  append([]string(nil), h.pat.vars...),
+			})
+		}
+	}
+	return infos
+}
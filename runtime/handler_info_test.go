@@ -0,0 +1,62 @@
+package runtime_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+)
+
+func TestServeMuxHandlers(t *testing.T) {
+	// "/v1/{name=messages/*}"
+	message := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{
+			int(utilities.OpLitPush), 0,
+			int(utilities.OpLitPush), 1,
+			int(utilities.OpPush), 0,
+			int(utilities.OpConcatN), 2,
+			int(utilities.OpCapture), 2,
+		},
+		[]string{"v1", "messages", "name"},
+		"",
+	))
+	// "/v1/messages"
+	messages := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{int(utilities.OpLitPush), 0, int(utilities.OpLitPush), 1},
+		[]string{"v1", "messages"},
+		"",
+	))
+	handler := func(w http.ResponseWriter, r *http.Request, _ map[string]string) {}
+
+	mux := runtime.NewServeMux()
+	mux.Handle("POST", messages, handler)
+	mux.Handle("GET", message, handler)
+	mux.Handle("GET", messages, handler)
+
+	want := []runtime.HandlerInfo{
+		{Method: "GET", Pattern: message, Path: "/v1/{name=messages/*}", Fields: []string{"name"}},
+		{Method: "GET", Pattern: messages, Path: "/v1/messages"},
+		{Method: "POST", Pattern: messages, Path: "/v1/messages"},
+	}
+	got := mux.Handlers()
+	if len(got) != len(want) {
+		t.Fatalf("mux.Handlers() = %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i].Method != want[i].Method || got[i].Path != want[i].Path || got[i].Pattern.String() != want[i].Pattern.String() {
+			t.Errorf("mux.Handlers()[%d] = %v; want %v", i, got[i], want[i])
+		}
+		if !reflect.DeepEqual(got[i].Fields, want[i].Fields) {
+			t.Errorf("mux.Handlers()[%d].Fields = %q; want %q", i, got[i].Fields, want[i].Fields)
+		}
+	}
+
+	got[0].Fields[0] = "changed"
+	if fields := mux.Handlers()[0].Fields; fields[0] != "name" {
+		t.Errorf("mux.Handlers()[0].Fields = %q after changing a copy; want %q", fields, []string{"name"})
+	}
+}
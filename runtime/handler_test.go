@@ -1,17 +1,26 @@
 package runtime_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/grpc-ecosystem/grpc-gateway/internal"
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -133,6 +142,7 @@ func TestForwardResponseStream(t *testing.T) {
 					if err != nil {
 						t.Errorf("marshaler.Marshal() failed %v", err)
 					}
+					b = append(b, marshaler.Delimiter()...)
 					errBytes := body[len(want):]
 					if string(errBytes) != string(b) {
 						t.Errorf("ForwardResponseStream() = \"%s\" want \"%s\"", errBytes, b)
@@ -170,6 +180,683 @@ func TestForwardResponseStream(t *testing.T) {
 	}
 }
 
+func TestForwardResponseStreamErrorMarker(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		wantKey string
+	}{{
+		name: "no marker",
+	}, {
+		name:    "marker",
+		muxOpts: []runtime.ServeMuxOption{runtime.WithStreamErrorMarker("final")},
+		wantKey: "final",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			var count int
+			recv := func() (proto.Message, error) {
+				count++
+				if count > 2 {
+					return nil, status.Error(codes.Unavailable, "gone")
+				}
+				return &pb.SimpleMessage{Id: "One"}, nil
+			}
+			req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			resp := httptest.NewRecorder()
+			ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+			runtime.ForwardResponseStream(ctx, runtime.NewServeMux(tt.muxOpts...), &runtime.JSONPb{}, resp, req, recv)
+
+			body := resp.Body.String()
+			if !strings.HasSuffix(body, "\n") {
+				t.Fatalf("ForwardResponseStream() = %q; want the error record to be delimited", body)
+			}
+			lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+			if got, want := len(lines), 3; got != want {
+				t.Fatalf("ForwardResponseStream() wrote %d records; want %d; body=%q", got, want, body)
+			}
+			for i, line := range lines {
+				var record map[string]json.RawMessage
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("json.Unmarshal(%q) failed with %v; want success", line, err)
+				}
+				_, isData := record["result"]
+				_, isError := record["error"]
+				marker, hasMarker := record[tt.wantKey]
+				if i < len(lines)-1 {
+					if !isData || isError || (tt.wantKey != "" && hasMarker) {
+						t.Errorf("record %d = %s; want a data record", i, line)
+					}
+					continue
+				}
+				if isData || !isError {
+					t.Errorf("record %d = %s; want an error record", i, line)
+				}
+				if tt.wantKey != "" && string(marker) != "true" {
+					t.Errorf("record %d = %s; want %q set to true", i, line, tt.wantKey)
+				}
+				if tt.wantKey == "" && len(record) != 1 {
+					t.Errorf("record %d = %s; want only the error", i, line)
+				}
+			}
+		})
+	}
+}
+
+// jsonSeqMarshaler frames streams as JSON text sequences (RFC 7464).
+type jsonSeqMarshaler struct {
+	runtime.JSONPb
+}
+
+func (*jsonSeqMarshaler) Delimiter() []byte      { return []byte{0x1E} }
+func (*jsonSeqMarshaler) LeadingDelimiter() bool { return true }
+
+func TestForwardResponseStreamLeadingDelimiter(t *testing.T) {
+	var count int
+	recv := func() (proto.Message, error) {
+		count++
+		if count > 2 {
+			return nil, status.Error(codes.Unavailable, "gone")
+		}
+		return &pb.SimpleMessage{Id: strconv.Itoa(count)}, nil
+	}
+	req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+	resp := httptest.NewRecorder()
+	ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+	runtime.ForwardResponseStream(ctx, runtime.NewServeMux(), &jsonSeqMarshaler{}, resp, req, recv)
+
+	want := "\x1e" + `{"result":{"id":"1"}}` +
+		"\x1e" + `{"result":{"id":"2"}}` +
+		"\x1e" + `{"error":{"grpcCode":14,"httpCode":503,"message":"gone","httpStatus":"Service Unavailable"}}`
+	if got := resp.Body.String(); got != want {
+		t.Errorf("ForwardResponseStream() = %q; want %q", got, want)
+	}
+}
+
+func TestForwardResponseStreamLengthPrefixed(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		marshaler runtime.Marshaler
+		err       error
+		want      string
+	}{
+		{
+			name:      "messages",
+			marshaler: &runtime.JSONPb{},
+			err:       io.EOF,
+			want:      "21\n" + `{"result":{"id":"1"}}` + "\n" + "21\n" + `{"result":{"id":"2"}}` + "\n",
+		},
+		{
+			name:      "error record",
+			marshaler: &runtime.JSONPb{},
+			err:       status.Error(codes.Unavailable, "gone"),
+			want: "21\n" + `{"result":{"id":"1"}}` + "\n" + "21\n" + `{"result":{"id":"2"}}` + "\n" +
+				"92\n" + `{"error":{"grpcCode":14,"httpCode":503,"message":"gone","httpStatus":"Service Unavailable"}}` + "\n",
+		},
+		{
+			name:      "marshaler delimiter ignored",
+			marshaler: &jsonSeqMarshaler{},
+			err:       io.EOF,
+			want:      "21\n" + `{"result":{"id":"1"}}` + "\n" + "21\n" + `{"result":{"id":"2"}}` + "\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var count int
+			recv := func() (proto.Message, error) {
+				count++
+				if count > 2 {
+					return nil, tt.err
+				}
+				return &pb.SimpleMessage{Id: strconv.Itoa(count)}, nil
+			}
+			req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			resp := httptest.NewRecorder()
+			ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+			mux := runtime.NewServeMux(runtime.WithLengthPrefixedStreaming())
+			runtime.ForwardResponseStream(ctx, mux, tt.marshaler, resp, req, recv)
+
+			if got := resp.Body.String(); got != tt.want {
+				t.Errorf("ForwardResponseStream() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// documentMarshaler tells whether it supports streaming, like a marshaler of whole documents.
+type documentMarshaler struct {
+	runtime.JSONPb
+	streaming bool
+}
+
+func (m *documentMarshaler) StreamSupported() bool { return m.streaming }
+
+func TestForwardResponseStreamNonStreamingMarshaler(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		streaming bool
+
+		wantCode int
+		wantRecv bool
+	}{
+		{
+			name:     "streaming not supported",
+			wantCode: http.StatusNotImplemented,
+		},
+		{
+			name:      "streaming supported",
+			streaming: true,
+			wantCode:  http.StatusOK,
+			wantRecv:  true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var received bool
+			recv := func() (proto.Message, error) {
+				received = true
+				return nil, io.EOF
+			}
+			req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			resp := httptest.NewRecorder()
+			ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+			runtime.ForwardResponseStream(ctx, runtime.NewServeMux(), &documentMarshaler{streaming: tt.streaming}, resp, req, recv)
+
+			if got, want := resp.Code, tt.wantCode; got != want {
+				t.Errorf("resp.Code = %d; want %d", got, want)
+			}
+			if received != tt.wantRecv {
+				t.Errorf("received = %t; want %t", received, tt.wantRecv)
+			}
+			if !tt.streaming {
+				const want = `streaming responses are not supported for Content-Type \"application/json\"`
+				if got := resp.Body.String(); !strings.Contains(got, want) {
+					t.Errorf("resp.Body = %q; want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+// sharedDelimiterMarshaler returns a delimiter with spare capacity, shared by all its streams.
+type sharedDelimiterMarshaler struct {
+	jsonSeqMarshaler
+	delimiter []byte
+}
+
+func (m *sharedDelimiterMarshaler) Delimiter() []byte { return m.delimiter }
+
+func TestForwardResponseStreamLeadingDelimiterNotModified(t *testing.T) {
+	var count int
+	recv := func() (proto.Message, error) {
+		count++
+		if count > 1 {
+			return nil, status.Error(codes.Unavailable, "gone")
+		}
+		return &pb.SimpleMessage{Id: "1"}, nil
+	}
+	m := &sharedDelimiterMarshaler{delimiter: append(make([]byte, 0, 256), 0x1E)}
+	req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+	resp := httptest.NewRecorder()
+	ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+	runtime.ForwardResponseStream(ctx, runtime.NewServeMux(), m, resp, req, recv)
+
+	if got, want := m.delimiter[:cap(m.delimiter)], append([]byte{0x1E}, make([]byte, 255)...); !bytes.Equal(got, want) {
+		t.Errorf("the delimiter was written to: %q; want %q", got, want)
+	}
+	want := "\x1e" + `{"result":{"id":"1"}}` +
+		"\x1e" + `{"error":{"grpcCode":14,"httpCode":503,"message":"gone","httpStatus":"Service Unavailable"}}`
+	if got := resp.Body.String(); got != want {
+		t.Errorf("ForwardResponseStream() = %q; want %q", got, want)
+	}
+}
+
+func TestForwardResponseStreamServerSentEvents(t *testing.T) {
+	msgs := []proto.Message{
+		&pb.SimpleMessage{Id: "One"},
+		&pb.SimpleMessage{Id: "Two"},
+	}
+	newRecv := func(err error) func() (proto.Message, error) {
+		var count int
+		return func() (proto.Message, error) {
+			if count == len(msgs) {
+				if err != nil {
+					return nil, err
+				}
+				return nil, io.EOF
+			}
+			count++
+			return msgs[count-1], nil
+		}
+	}
+	idFunc := func(msg proto.Message) string {
+		return msg.(*pb.SimpleMessage).Id
+	}
+
+	ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+	marshaler := &runtime.JSONPb{}
+	for _, tt := range []struct {
+		name    string
+		idFunc  runtime.SSEEventIDFunc
+		accept  string
+		err     error
+		wantSSE bool
+	}{{
+		name:    "event ids",
+		idFunc:  idFunc,
+		accept:  "text/event-stream",
+		wantSSE: true,
+	}, {
+		name:    "no event ids",
+		accept:  "text/event-stream",
+		wantSSE: true,
+	}, {
+		name:    "stream error",
+		idFunc:  idFunc,
+		accept:  "text/event-stream",
+		err:     grpc.Errorf(codes.OutOfRange, "400"),
+		wantSSE: true,
+	}, {
+		name:   "not accepted",
+		idFunc: idFunc,
+		accept: "application/json",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(runtime.WithServerSentEvents(tt.idFunc))
+			req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			req.Header.Set("Accept", tt.accept)
+			resp := httptest.NewRecorder()
+
+			runtime.ForwardResponseStream(ctx, mux, marshaler, resp, req, newRecv(tt.err))
+
+			w := resp.Result()
+			body, err := ioutil.ReadAll(w.Body)
+			if err != nil {
+				t.Errorf("Failed to read response body with %v", err)
+			}
+			w.Body.Close()
+
+			var want []byte
+			for _, msg := range msgs {
+				b, err := marshaler.Marshal(map[string]interface{}{"result": msg})
+				if err != nil {
+					t.Errorf("marshaler.Marshal() failed %v", err)
+				}
+				if !tt.wantSSE {
+					want = append(want, b...)
+					want = append(want, marshaler.Delimiter()...)
+					continue
+				}
+				if tt.idFunc != nil {
+					want = append(want, "id: "+tt.idFunc(msg)+"\n"...)
+				}
+				want = append(want, "data: "+string(b)+"\n\n"...)
+			}
+			if tt.err != nil {
+				st, _ := status.FromError(tt.err)
+				httpCode := runtime.HTTPStatusFromCode(st.Code())
+				b, err := marshaler.Marshal(map[string]proto.Message{
+					"error": &internal.StreamError{
+						GrpcCode:   int32(st.Code()),
+						HttpCode:   int32(httpCode),
+						Message:    st.Message(),
+						HttpStatus: http.StatusText(httpCode),
+						Details:    st.Proto().GetDetails(),
+					},
+				})
+				if err != nil {
+					t.Errorf("marshaler.Marshal() failed %v", err)
+				}
+				want = append(want, "event: error\ndata: "+string(b)+"\n\n"...)
+			}
+
+			if string(body) != string(want) {
+				t.Errorf("ForwardResponseStream() = \"%s\" want \"%s\"", body, want)
+			}
+			wantContentType := marshaler.ContentType()
+			if tt.wantSSE {
+				wantContentType = "text/event-stream"
+			}
+			if got := w.Header.Get("Content-Type"); got != wantContentType {
+				t.Errorf("Content-Type = %q; want %q", got, wantContentType)
+			}
+		})
+	}
+}
+
+func TestForwardResponseStreamTimingTrailers(t *testing.T) {
+	clock := newFakeClock()
+	mux := runtime.NewServeMux(runtime.WithTimingTrailers(), runtime.WithClock(clock))
+	pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+	mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		var count int
+		recv := func() (proto.Message, error) {
+			if count == 2 {
+				return nil, io.EOF
+			}
+			count++
+			clock.Advance(150 * time.Millisecond)
+			return &pb.SimpleMessage{Id: "One"}, nil
+		}
+		ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+		runtime.ForwardResponseStream(ctx, mux, &runtime.JSONPb{}, w, r, recv)
+	})
+
+	req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+	resp := httptest.NewRecorder()
+	mux.ServeHTTP(resp, req)
+
+	w := resp.Result()
+	if got, want := w.Header.Get("Trailer"), "X-Response-Time-Ms"; got != want {
+		t.Errorf("Trailer header = %q; want %q", got, want)
+	}
+	if _, err := ioutil.ReadAll(w.Body); err != nil {
+		t.Errorf("Failed to read response body with %v", err)
+	}
+	w.Body.Close()
+	if got, want := w.Trailer.Get("X-Response-Time-Ms"), "300"; got != want {
+		t.Errorf("X-Response-Time-Ms trailer = %q; want %q", got, want)
+	}
+}
+
+func TestForwardResponseStreamHTTP10(t *testing.T) {
+	msgs := []proto.Message{&pb.SimpleMessage{Id: "One"}, &pb.SimpleMessage{Id: "Two"}}
+	for _, tt := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		minor   int
+
+		wantStatus  int
+		wantChunked bool
+		wantLength  bool
+		wantCalled  bool
+	}{{
+		name:        "default",
+		wantStatus:  http.StatusOK,
+		wantChunked: true,
+		wantCalled:  true,
+	}, {
+		name:       "buffer",
+		muxOpts:    []runtime.ServeMuxOption{runtime.WithHTTP10StreamingMode(runtime.HTTP10StreamingBuffer)},
+		wantStatus: http.StatusOK,
+		wantLength: true,
+		wantCalled: true,
+	}, {
+		name:       "reject",
+		muxOpts:    []runtime.ServeMuxOption{runtime.WithHTTP10StreamingMode(runtime.HTTP10StreamingReject)},
+		wantStatus: http.StatusNotImplemented,
+	}, {
+		name:        "HTTP/1.1 not buffered",
+		muxOpts:     []runtime.ServeMuxOption{runtime.WithHTTP10StreamingMode(runtime.HTTP10StreamingBuffer)},
+		minor:       1,
+		wantStatus:  http.StatusOK,
+		wantChunked: true,
+		wantCalled:  true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			var count int
+			recv := func() (proto.Message, error) {
+				if count == len(msgs) {
+					return nil, io.EOF
+				}
+				count++
+				return msgs[count-1], nil
+			}
+			req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			req.Proto, req.ProtoMajor, req.ProtoMinor = fmt.Sprintf("HTTP/1.%d", tt.minor), 1, tt.minor
+			resp := httptest.NewRecorder()
+			ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+			runtime.ForwardResponseStream(ctx, runtime.NewServeMux(tt.muxOpts...), &runtime.JSONPb{}, resp, req, recv)
+
+			w := resp.Result()
+			if got, want := w.StatusCode, tt.wantStatus; got != want {
+				t.Errorf("StatusCode %d want %d", got, want)
+			}
+			if got := w.Header.Get("Transfer-Encoding") == "chunked"; got != tt.wantChunked {
+				t.Errorf("Transfer-Encoding = %q; want chunked %t", w.Header.Get("Transfer-Encoding"), tt.wantChunked)
+			}
+			body, err := ioutil.ReadAll(w.Body)
+			if err != nil {
+				t.Fatalf("Failed to read response body with %v", err)
+			}
+			if tt.wantLength {
+				if got, want := w.Header.Get("Content-Length"), strconv.Itoa(len(body)); got != want {
+					t.Errorf("Content-Length = %q; want %q", got, want)
+				}
+			}
+			if got, want := count > 0, tt.wantCalled; got != want {
+				t.Errorf("stream read = %t; want %t", got, want)
+			}
+			if tt.wantCalled {
+				if got, want := strings.Count(string(body), "\n"), len(msgs); got != want {
+					t.Errorf("response has %d records; want %d; body=%q", got, want, body)
+				}
+			}
+		})
+	}
+}
+
+func TestForwardResponseStreamFirstByteTimeout(t *testing.T) {
+	const timeout = time.Second
+	for _, tt := range []struct {
+		name string
+		// delays are how long each message takes to be received.
+		delays []time.Duration
+
+		wantStatus  int
+		wantRecords int
+		wantError   bool
+	}{{
+		name:       "slow first message",
+		delays:     []time.Duration{timeout},
+		wantStatus: http.StatusGatewayTimeout,
+		wantError:  true,
+	}, {
+		name:        "prompt first message",
+		delays:      []time.Duration{0, 2 * timeout},
+		wantStatus:  http.StatusOK,
+		wantRecords: 2,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			release := make(chan struct{})
+			defer close(release)
+			var count int
+			recv := func() (proto.Message, error) {
+				if count == len(tt.delays) {
+					return nil, io.EOF
+				}
+				delay := tt.delays[count]
+				count++
+				clock.Advance(delay)
+				if delay >= timeout && count == 1 {
+					// The message never comes before the stream is aborted.
+					<-release
+					return nil, context.Canceled
+				}
+				return &pb.SimpleMessage{Id: strconv.Itoa(count)}, nil
+			}
+			mux := runtime.NewServeMux(runtime.WithClock(clock), runtime.WithStreamFirstByteTimeout(timeout))
+			req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			resp := httptest.NewRecorder()
+			ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+			runtime.ForwardResponseStream(ctx, mux, &runtime.JSONPb{}, resp, req, recv)
+
+			w := resp.Result()
+			if got, want := w.StatusCode, tt.wantStatus; got != want {
+				t.Errorf("StatusCode %d want %d", got, want)
+			}
+			body, err := ioutil.ReadAll(w.Body)
+			if err != nil {
+				t.Fatalf("Failed to read response body with %v", err)
+			}
+			if got, want := strings.Count(string(body), `"result"`), tt.wantRecords; got != want {
+				t.Errorf("response has %d records; want %d; body=%q", got, want, body)
+			}
+			if got, want := strings.Contains(string(body), `"error"`), tt.wantError; got != want {
+				t.Errorf("response has an error = %t; want %t; body=%q", got, want, body)
+			}
+		})
+	}
+}
+
+func TestForwardResponseStreamMaxDuration(t *testing.T) {
+	const maxDuration = 3 * time.Second
+	for _, tt := range []struct {
+		name string
+		// delays are how long each message takes to be received.
+		delays []time.Duration
+
+		wantRecords int
+		wantError   bool
+	}{{
+		name:        "short stream",
+		delays:      []time.Duration{time.Second, time.Second},
+		wantRecords: 2,
+	}, {
+		name:        "runaway stream",
+		delays:      []time.Duration{time.Second, time.Second, time.Second, time.Second},
+		wantRecords: 2,
+		wantError:   true,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			mux := runtime.NewServeMux(runtime.WithClock(clock), runtime.WithMaxStreamDuration(maxDuration))
+			var streamCtx context.Context
+			handler := func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				streamCtx = r.Context()
+				var count int
+				recv := func() (proto.Message, error) {
+					if count == len(tt.delays) {
+						return nil, io.EOF
+					}
+					clock.Advance(tt.delays[count])
+					count++
+					// Like the gRPC client stream, recv fails once the context of the call is done.
+					if err := r.Context().Err(); err != nil {
+						return nil, status.Error(codes.DeadlineExceeded, err.Error())
+					}
+					return &pb.SimpleMessage{Id: strconv.Itoa(count)}, nil
+				}
+				ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+				runtime.ForwardResponseStream(ctx, mux, &runtime.JSONPb{}, w, r, recv)
+			}
+			pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+			mux.Handle("GET", pat, handler, runtime.WithServerStreaming())
+			req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req)
+
+			w := resp.Result()
+			if got, want := w.StatusCode, http.StatusOK; got != want {
+				t.Errorf("StatusCode %d want %d", got, want)
+			}
+			body, err := ioutil.ReadAll(w.Body)
+			if err != nil {
+				t.Fatalf("Failed to read response body with %v", err)
+			}
+			if got, want := strings.Count(string(body), `"result"`), tt.wantRecords; got != want {
+				t.Errorf("response has %d records; want %d; body=%q", got, want, body)
+			}
+			wantCode := fmt.Sprintf(`"grpcCode":%d`, codes.DeadlineExceeded)
+			if got, want := strings.Contains(string(body), wantCode), tt.wantError; got != want {
+				t.Errorf("response has a DeadlineExceeded error = %t; want %t; body=%q", got, want, body)
+			}
+			if streamCtx.Err() == nil {
+				t.Errorf("stream context is not canceled at the end of the stream")
+			}
+		})
+	}
+}
+
+// flushRecorder records the body written at each Flush.
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushed []string
+}
+
+func (r *flushRecorder) Flush() {
+	r.flushed = append(r.flushed, r.Body.String())
+	r.ResponseRecorder.Flush()
+}
+
+// noFlushRecorder is an http.ResponseWriter which is not an http.Flusher.
+type noFlushRecorder struct {
+	rec *httptest.ResponseRecorder
+}
+
+func (r noFlushRecorder) Header() http.Header         { return r.rec.Header() }
+func (r noFlushRecorder) Write(b []byte) (int, error) { return r.rec.Write(b) }
+func (r noFlushRecorder) WriteHeader(code int)        { r.rec.WriteHeader(code) }
+
+func TestForwardResponseStreamFlush(t *testing.T) {
+	const one, two = `{"result":{"id":"1"}}` + "\n", `{"result":{"id":"2"}}` + "\n"
+	const failure = `{"error":{"grpcCode":14,"httpCode":503,"message":"gone","httpStatus":"Service Unavailable"}}` + "\n"
+	for _, tt := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		err     error
+
+		wantFlushed []string
+	}{{
+		name:        "default",
+		wantFlushed: []string{one, one + two, one + two},
+	}, {
+		name:        "each message",
+		muxOpts:     []runtime.ServeMuxOption{runtime.WithStreamFlush(true)},
+		err:         status.Error(codes.Unavailable, "gone"),
+		wantFlushed: []string{one, one + two, one + two + failure},
+	}, {
+		name:        "end of stream",
+		muxOpts:     []runtime.ServeMuxOption{runtime.WithStreamFlush(false)},
+		wantFlushed: []string{one + two},
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			var count int
+			recv := func() (proto.Message, error) {
+				if count == 2 {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return nil, io.EOF
+				}
+				count++
+				return &pb.SimpleMessage{Id: strconv.Itoa(count)}, nil
+			}
+			req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			resp := &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+			ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+			runtime.ForwardResponseStream(ctx, runtime.NewServeMux(tt.muxOpts...), &runtime.JSONPb{}, resp, req, recv)
+
+			if got, want := resp.flushed, tt.wantFlushed; !reflect.DeepEqual(got, want) {
+				t.Errorf("flushed %q; want %q", got, want)
+			}
+		})
+	}
+
+	t.Run("not a flusher", func(t *testing.T) {
+		for _, flush := range []bool{true, false} {
+			var count int
+			recv := func() (proto.Message, error) {
+				if count == 2 {
+					return nil, io.EOF
+				}
+				count++
+				return &pb.SimpleMessage{Id: strconv.Itoa(count)}, nil
+			}
+			req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			resp := httptest.NewRecorder()
+			ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+			mux := runtime.NewServeMux(runtime.WithStreamFlush(flush))
+			runtime.ForwardResponseStream(ctx, mux, &runtime.JSONPb{}, noFlushRecorder{resp}, req, recv)
+
+			if got, want := resp.Code, http.StatusOK; got != want {
+				t.Errorf("WithStreamFlush(%t): resp.Code = %d; want %d", flush, got, want)
+			}
+			if got, want := resp.Body.String(), one+two; got != want {
+				t.Errorf("WithStreamFlush(%t): resp.Body = %q; want %q", flush, got, want)
+			}
+		}
+	})
+}
+
 // A custom marshaler implementation, that doesn't implement the delimited interface
 type CustomMarshaler struct {
 	m *runtime.JSONPb
@@ -322,3 +1009,155 @@ func TestForwardResponseMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteResponse(t *testing.T) {
+	cached := &pb.SimpleMessage{Id: "cached"}
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption("application/x-pretty", &runtime.JSONPb{Indent: "  "}),
+		runtime.WithForwardResponseOption(func(ctx context.Context, w http.ResponseWriter, _ proto.Message) error {
+			w.Header().Set("X-Forwarded-By", "gateway")
+			return nil
+		}),
+	)
+	pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+	mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		t.Errorf("handler called; want the cached response")
+	})
+	middleware := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			runtime.WriteResponse(r.Context(), w, r, mux, cached)
+		})
+	}
+
+	for _, tt := range []struct {
+		accept    string
+		marshaler runtime.Marshaler
+	}{{
+		marshaler: &runtime.JSONPb{OrigName: true},
+	}, {
+		accept:    "application/x-pretty",
+		marshaler: &runtime.JSONPb{Indent: "  "},
+	}} {
+		req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		resp := httptest.NewRecorder()
+		middleware(mux).ServeHTTP(resp, req)
+
+		want, err := tt.marshaler.Marshal(cached)
+		if err != nil {
+			t.Fatalf("marshaler.Marshal() failed %v", err)
+		}
+		if got := resp.Body.String(); got != string(want) {
+			t.Errorf("WriteResponse() = %q; want %q; accept=%q", got, want, tt.accept)
+		}
+		if got, want := resp.Header().Get("Content-Type"), "application/json"; got != want {
+			t.Errorf("Content-Type = %q; want %q", got, want)
+		}
+		if got, want := resp.Header().Get("X-Forwarded-By"), "gateway"; got != want {
+			t.Errorf("X-Forwarded-By = %q; want %q", got, want)
+		}
+	}
+}
+
+func TestForwardResponseMessageNilResponse(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		resp    proto.Message
+
+		wantStatus int
+		wantBody   string
+	}{{
+		name:       "default typed nil",
+		resp:       (*pb.SimpleMessage)(nil),
+		wantStatus: http.StatusOK,
+		wantBody:   "{}",
+	}, {
+		name:       "default nil",
+		wantStatus: http.StatusOK,
+		wantBody:   "{}",
+	}, {
+		name:       "null",
+		muxOpts:    []runtime.ServeMuxOption{runtime.WithNilResponseBehavior(runtime.NilResponseNull)},
+		resp:       (*pb.SimpleMessage)(nil),
+		wantStatus: http.StatusOK,
+		wantBody:   "null",
+	}, {
+		name:       "error",
+		muxOpts:    []runtime.ServeMuxOption{runtime.WithNilResponseBehavior(runtime.NilResponseError)},
+		resp:       (*pb.SimpleMessage)(nil),
+		wantStatus: http.StatusInternalServerError,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+			req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			resp := httptest.NewRecorder()
+
+			runtime.ForwardResponseMessage(ctx, runtime.NewServeMux(tt.muxOpts...), &runtime.JSONPb{}, resp, req, tt.resp)
+
+			if got, want := resp.Code, tt.wantStatus; got != want {
+				t.Errorf("StatusCode %d want %d", got, want)
+			}
+			if tt.wantBody != "" {
+				if got := resp.Body.String(); got != tt.wantBody {
+					t.Errorf("ForwardResponseMessage() = %q want %q", got, tt.wantBody)
+				}
+			}
+		})
+	}
+}
+
+func TestForwardResponseMessageResponseValidator(t *testing.T) {
+	noSecrets := func(msg proto.Message) error {
+		if m, ok := msg.(*pb.SimpleMessage); ok && m.Id == "secret" {
+			return errors.New("id must not be leaked")
+		}
+		return nil
+	}
+	for _, tt := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		resp    proto.Message
+
+		wantStatus int
+		wantBody   string
+	}{{
+		name:       "valid",
+		muxOpts:    []runtime.ServeMuxOption{runtime.WithResponseValidator(noSecrets)},
+		resp:       &pb.SimpleMessage{Id: "public"},
+		wantStatus: http.StatusOK,
+		wantBody:   `{"id":"public"}`,
+	}, {
+		name:       "invalid",
+		muxOpts:    []runtime.ServeMuxOption{runtime.WithResponseValidator(noSecrets)},
+		resp:       &pb.SimpleMessage{Id: "secret"},
+		wantStatus: http.StatusInternalServerError,
+	}, {
+		name:       "disabled",
+		resp:       &pb.SimpleMessage{Id: "secret"},
+		wantStatus: http.StatusOK,
+		wantBody:   `{"id":"secret"}`,
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+			req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			resp := httptest.NewRecorder()
+
+			runtime.ForwardResponseMessage(ctx, runtime.NewServeMux(tt.muxOpts...), &runtime.JSONPb{}, resp, req, tt.resp)
+
+			if got, want := resp.Code, tt.wantStatus; got != want {
+				t.Errorf("StatusCode %d want %d", got, want)
+			}
+			if tt.wantBody != "" {
+				if got := resp.Body.String(); got != tt.wantBody {
+					t.Errorf("ForwardResponseMessage() = %q want %q", got, tt.wantBody)
+				}
+			}
+			if tt.wantStatus == http.StatusInternalServerError && strings.Contains(resp.Body.String(), "secret") {
+				t.Errorf("ForwardResponseMessage() = %q; want no response message", resp.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package runtime
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/grpclog"
+	"google.golang.org/grpc/status"
+)
+
+// HTTPStatusMapperFunc returns the HTTP response status for the gRPC error s with the given code.
+// It returns 0 to use the status given by HTTPStatusFromCode.
+type HTTPStatusMapperFunc func(code codes.Code, s *status.Status) int
+
+// WithHTTPStatusMapper returns a ServeMuxOption that lets fn choose the HTTP response status of
+// gRPC errors, e.g. by inspecting their details, before falling back to HTTPStatusFromCode.
+//
+// It applies to DefaultHTTPError and DefaultHTTPProtoErrorHandler, and to the status of the
+// errors of response streams.
+func WithHTTPStatusMapper(fn HTTPStatusMapperFunc) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.httpStatusMapper = fn
+	}
+}
+
+// httpStatus returns the HTTP response status for the gRPC error s.
+func (s *ServeMux) httpStatus(st *status.Status) int {
+	if s.httpStatusMapper != nil {
+		if code := s.httpStatusMapper(st.Code(), st); code != 0 {
+			return code
+		}
+	}
+	return HTTPStatusFromCode(st.Code())
+}
+
+// mapStreamErrorStatus sets the HTTP status of serr, the stream error for err, as chosen by the
+// HTTPStatusMapperFunc of mux, if any.
+func mapStreamErrorStatus(mux *ServeMux, serr *StreamError, err error) {
+	if mux.httpStatusMapper == nil {
+		return
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		st = status.New(codes.Code(serr.GrpcCode), serr.Message)
+	}
+	if code := mux.httpStatusMapper(st.Code(), st); code != 0 {
+		serr.HttpCode = int32(code)
+		serr.HttpStatus = http.StatusText(code)
+	}
+}
+
+// WithStatusFromMetadata returns a ServeMuxOption that sets the HTTP status of successful unary
+// responses to the integer in the response header metadata key, e.g. "x-http-code", so that the
+// backends can choose it, e.g. 201 for created resources. The key is not forwarded as a response
+// header. Values which are not statuses from 200 to 599 are ignored, with a warning, and the
+// responses keep their status.
+func WithStatusFromMetadata(key string) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.statusMetadataKey = strings.ToLower(key)
+	}
+}
+
+// statusFromMetadata returns the HTTP status set in md with WithStatusFromMetadata, or 0 if there
+// is none, and md without the metadata key of the status.
+func (s *ServeMux) statusFromMetadata(md ServerMetadata) (int, ServerMetadata) {
+	if s.statusMetadataKey == "" {
+		return 0, md
+	}
+	vals := md.HeaderMD.Get(s.statusMetadataKey)
+	if len(vals) == 0 {
+		return 0, md
+	}
+	md.HeaderMD = md.HeaderMD.Copy()
+	delete(md.HeaderMD, s.statusMetadataKey)
+
+	code, err := strconv.Atoi(vals[0])
+	if err != nil || code < 200 || code > 599 {
+		grpclog.Warningf("Ignored invalid HTTP status %q in metadata %q", vals[0], s.statusMetadataKey)
+		return 0, md
+	}
+	return code, md
+}
+
+// GRPCMessageHeader is the response header which holds the message of gRPC errors with
+// WithGRPCMessageHeader.
+const GRPCMessageHeader = "X-Grpc-Message"
+
+// WithGRPCMessageHeader returns a ServeMuxOption that sets the GRPCMessageHeader of error
+// responses to the message of their gRPC status, for the clients which only read the status line
+// and the headers, since the reason phrase of the status line cannot be set. The message is
+// percent-encoded like the grpc-message of gRPC, so that it is a valid header value.
+//
+// It applies to DefaultHTTPError and DefaultHTTPProtoErrorHandler.
+func WithGRPCMessageHeader() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.grpcMessageHeader = true
+	}
+}
+
+// handleGRPCMessageHeader sets the GRPCMessageHeader of w to the message of st, with
+// WithGRPCMessageHeader.
+func (s *ServeMux) handleGRPCMessageHeader(w http.ResponseWriter, st *status.Status) {
+	if !s.grpcMessageHeader || st.Message() == "" {
+		return
+	}
+	w.Header().Set(GRPCMessageHeader, encodeGRPCMessage(st.Message()))
+}
+
+// encodeGRPCMessage percent-encodes the bytes of msg which are not printable ASCII characters,
+// and '%'.
+func encodeGRPCMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		if c := msg[i]; c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,235 @@
+package runtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// quotaStatus maps the errors with a QuotaFailure to 429 and keeps the default otherwise.
+func quotaStatus(code codes.Code, s *status.Status) int {
+	for _, d := range s.Details() {
+		if _, ok := d.(*errdetails.QuotaFailure); ok && code == codes.FailedPrecondition {
+			return http.StatusTooManyRequests
+		}
+	}
+	return 0
+}
+
+func TestHTTPStatusMapper(t *testing.T) {
+	quota, _ := status.New(codes.FailedPrecondition, "quota exceeded").WithDetails(&errdetails.QuotaFailure{})
+	for _, spec := range []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "mapped", err: quota.Err(), want: http.StatusTooManyRequests},
+		{name: "default", err: status.Error(codes.FailedPrecondition, "failed"), want: http.StatusBadRequest},
+	} {
+		for _, handler := range []struct {
+			name string
+			fn   runtime.ProtoErrorHandlerFunc
+		}{
+			{name: "DefaultHTTPError", fn: runtime.DefaultHTTPError},
+			{name: "DefaultHTTPProtoErrorHandler", fn: runtime.DefaultHTTPProtoErrorHandler},
+		} {
+			t.Run(spec.name+"/"+handler.name, func(t *testing.T) {
+				ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest("", "", nil)
+				mux := runtime.NewServeMux(runtime.WithHTTPStatusMapper(quotaStatus))
+				handler.fn(ctx, mux, &runtime.JSONPb{}, w, req, spec.err)
+
+				if got := w.Code; got != spec.want {
+					t.Errorf("w.Code = %d; want %d", got, spec.want)
+				}
+			})
+		}
+	}
+}
+
+func TestHTTPStatusMapperStream(t *testing.T) {
+	quota, _ := status.New(codes.FailedPrecondition, "quota exceeded").WithDetails(&errdetails.QuotaFailure{})
+	for _, spec := range []struct {
+		name       string
+		messages   int
+		wantStatus int
+	}{
+		{name: "before the first message", wantStatus: http.StatusTooManyRequests},
+		{name: "after a message", messages: 1, wantStatus: http.StatusOK},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			var count int
+			recv := func() (proto.Message, error) {
+				if count == spec.messages {
+					return nil, quota.Err()
+				}
+				count++
+				return &pb.SimpleMessage{Id: "One"}, nil
+			}
+			req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			resp := httptest.NewRecorder()
+			ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+			mux := runtime.NewServeMux(runtime.WithHTTPStatusMapper(quotaStatus))
+			runtime.ForwardResponseStream(ctx, mux, &runtime.JSONPb{}, resp, req, recv)
+
+			if got := resp.Code; got != spec.wantStatus {
+				t.Errorf("resp.Code = %d; want %d", got, spec.wantStatus)
+			}
+			lines := strings.Split(strings.TrimSuffix(resp.Body.String(), "\n"), "\n")
+			var record struct {
+				Error struct {
+					HTTPCode   int    `json:"httpCode"`
+					HTTPStatus string `json:"httpStatus"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal([]byte(lines[len(lines)-1]), &record); err != nil {
+				t.Fatalf("json.Unmarshal(%q) failed with %v; want success", lines[len(lines)-1], err)
+			}
+			if got, want := record.Error.HTTPCode, http.StatusTooManyRequests; got != want {
+				t.Errorf("httpCode = %d; want %d", got, want)
+			}
+			if got, want := record.Error.HTTPStatus, http.StatusText(http.StatusTooManyRequests); got != want {
+				t.Errorf("httpStatus = %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestGRPCMessageHeader(t *testing.T) {
+	for _, spec := range []struct {
+		name    string
+		opts    []runtime.ServeMuxOption
+		err     error
+		want    string
+		present bool
+	}{
+		{
+			name:    "message",
+			opts:    []runtime.ServeMuxOption{runtime.WithGRPCMessageHeader()},
+			err:     status.Error(codes.NotFound, "book not found"),
+			want:    "book not found",
+			present: true,
+		},
+		{
+			name:    "encoded message",
+			opts:    []runtime.ServeMuxOption{runtime.WithGRPCMessageHeader()},
+			err:     status.Error(codes.InvalidArgument, "50% off\r\nnaïve"),
+			want:    "50%25 off%0D%0Ana%C3%AFve",
+			present: true,
+		},
+		{
+			name: "no message",
+			opts: []runtime.ServeMuxOption{runtime.WithGRPCMessageHeader()},
+			err:  status.Error(codes.Internal, ""),
+		},
+		{
+			name: "option not set",
+			err:  status.Error(codes.NotFound, "book not found"),
+		},
+	} {
+		for _, handler := range []struct {
+			name string
+			fn   runtime.ProtoErrorHandlerFunc
+		}{
+			{name: "DefaultHTTPError", fn: runtime.DefaultHTTPError},
+			{name: "DefaultHTTPProtoErrorHandler", fn: runtime.DefaultHTTPProtoErrorHandler},
+		} {
+			t.Run(spec.name+"/"+handler.name, func(t *testing.T) {
+				ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest("", "", nil)
+				mux := runtime.NewServeMux(spec.opts...)
+				handler.fn(ctx, mux, &runtime.JSONPb{}, w, req, spec.err)
+
+				got, present := w.Result().Header[runtime.GRPCMessageHeader]
+				if present != spec.present {
+					t.Fatalf("%s present = %t; want %t", runtime.GRPCMessageHeader, present, spec.present)
+				}
+				if present && got[0] != spec.want {
+					t.Errorf("%s = %q; want %q", runtime.GRPCMessageHeader, got[0], spec.want)
+				}
+			})
+		}
+	}
+}
+
+func TestWithStatusFromMetadata(t *testing.T) {
+	for _, spec := range []struct {
+		name  string
+		key   string
+		value []string
+
+		wantCode int
+	}{
+		{
+			name:     "no option",
+			value:    []string{"201"},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "valid status",
+			key:      "X-Http-Code",
+			value:    []string{"201"},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:     "no metadata",
+			key:      "x-http-code",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "not a number",
+			key:      "x-http-code",
+			value:    []string{"created"},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "informational status",
+			key:      "x-http-code",
+			value:    []string{"101"},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "out of range",
+			key:      "x-http-code",
+			value:    []string{"600"},
+			wantCode: http.StatusOK,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			var opts []runtime.ServeMuxOption
+			if spec.key != "" {
+				opts = append(opts, runtime.WithStatusFromMetadata(spec.key))
+			}
+			mux := runtime.NewServeMux(opts...)
+			md := runtime.ServerMetadata{HeaderMD: metadata.MD{}}
+			if spec.value != nil {
+				md.HeaderMD["x-http-code"] = spec.value
+			}
+			ctx := runtime.NewServerMetadataContext(context.Background(), md)
+			req := httptest.NewRequest("POST", "http://example.com/foo", nil)
+			resp := httptest.NewRecorder()
+			runtime.ForwardResponseMessage(ctx, mux, &runtime.JSONPb{}, resp, req, &pb.SimpleMessage{Id: "foo"})
+
+			if got, want := resp.Code, spec.wantCode; got != want {
+				t.Errorf("resp.Code = %d; want %d", got, want)
+			}
+			_, forwarded := resp.Header()["Grpc-Metadata-X-Http-Code"]
+			if want := spec.value != nil && spec.key == ""; forwarded != want {
+				t.Errorf("Grpc-Metadata-X-Http-Code forwarded = %t; want %t", forwarded, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// A JSON tree is the decoded form of a JSON value, which keeps the order of object members.
+// Its nodes are jsonObject, []interface{}, string, json.Number, bool or nil.
+// It is used by the options of JSONPb which rewrite the JSON produced or consumed by jsonpb.
+
+// jsonObject is a JSON object whose members are in the order of the input.
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	key   string
+	value interface{}
+}
+
+// jsonVisitor is called with a node of a JSON tree and the Go value it was marshaled from,
+// or which it is going to be unmarshaled into, after the children of the node have been visited.
+// It returns the node which replaces the visited one.
+// The Go value is a zero value when only its type is known, and it is invalid when its type is
+// unknown.
+type jsonVisitor func(node interface{}, v reflect.Value) (interface{}, error)
+
+func decodeJSONTree(data []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	node, err := decodeJSONNode(d)
+	if err != nil {
+		return nil, err
+	}
+	if d.More() {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+	return node, nil
+}
+
+func decodeJSONNode(d *json.Decoder) (interface{}, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch delim {
+	case '{':
+		obj := jsonObject{}
+		for d.More() {
+			tok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := tok.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected object key %v", tok)
+			}
+			value, err := decodeJSONNode(d)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonMember{key: key, value: value})
+		}
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case '[':
+		arr := []interface{}{}
+		for d.More() {
+			value, err := decodeJSONNode(d)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	default:
+		return nil, fmt.Errorf("unexpected delimiter %v", delim)
+	}
+}
+
+// encodeJSONTree encodes node, indenting each level by indent if it is not empty.
+func encodeJSONTree(node interface{}, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeJSONNode(&buf, node); err != nil {
+		return nil, err
+	}
+	if indent == "" {
+		return buf.Bytes(), nil
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", indent); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func encodeJSONNode(buf *bytes.Buffer, node interface{}) error {
+	switch n := node.(type) {
+	case jsonObject:
+		buf.WriteByte('{')
+		for i, m := range n {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeJSONString(buf, m.key); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := encodeJSONNode(buf, m.value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, v := range n {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeJSONNode(buf, v); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case string:
+		return encodeJSONString(buf, n)
+	case json.Number:
+		buf.WriteString(n.String())
+	default:
+		b, err := json.Marshal(n)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	}
+	return nil
+}
+
+func encodeJSONString(buf *bytes.Buffer, s string) error {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+	// Remove the newline added by the encoder.
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
+
+// wellKnownType is implemented by the well-known types, whose JSON representation is
+// not an object of their fields.
+type wellKnownType interface {
+	XXX_WellKnownType() string
+}
+
+// walkJSONTree calls visit for node and all its descendants, in post-order, along with the
+// part of v they correspond to. v is what node is marshaled from or unmarshaled into.
+func walkJSONTree(node interface{}, v reflect.Value, visit jsonVisitor) (interface{}, error) {
+	for v.IsValid() && v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			v = reflect.Value{}
+			break
+		}
+		v = v.Elem()
+	}
+
+	var err error
+	switch n := node.(type) {
+	case jsonObject:
+		if !v.IsValid() {
+			break
+		}
+		if v.Kind() == reflect.Map {
+			for i, m := range n {
+				if n[i].value, err = walkJSONTree(m.value, mapIndex(v, m.key), visit); err != nil {
+					return nil, err
+				}
+			}
+			break
+		}
+		sv, ok := messageStruct(v)
+		if !ok {
+			break
+		}
+		for i, m := range n {
+			if n[i].value, err = walkJSONTree(m.value, messageField(sv, m.key), visit); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		if !v.IsValid() || v.Kind() != reflect.Slice {
+			break
+		}
+		for i, e := range n {
+			ev := reflect.Zero(v.Type().Elem())
+			if i < v.Len() {
+				ev = v.Index(i)
+			}
+			if n[i], err = walkJSONTree(e, ev, visit); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return visit(node, v)
+}
+
+// renameJSONKeys replaces the keys of the objects of messages in node, at any nesting level, with
+// the keys returned by rename, along with the part of v they correspond to, as in walkJSONTree.
+// rename is given the struct of the message and the key, before the members are renamed.
+func renameJSONKeys(node interface{}, v reflect.Value, rename func(sv reflect.Value, key string) (string, reflect.Value)) interface{} {
+	for v.IsValid() && v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return node
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return node
+	}
+
+	switch n := node.(type) {
+	case jsonObject:
+		if v.Kind() == reflect.Map {
+			for i, m := range n {
+				n[i].value = renameJSONKeys(m.value, mapIndex(v, m.key), rename)
+			}
+			break
+		}
+		sv, ok := messageStruct(v)
+		if !ok {
+			break
+		}
+		for i, m := range n {
+			key, fv := rename(sv, m.key)
+			n[i].key = key
+			n[i].value = renameJSONKeys(m.value, fv, rename)
+		}
+	case []interface{}:
+		if v.Kind() != reflect.Slice {
+			break
+		}
+		for i, e := range n {
+			ev := reflect.Zero(v.Type().Elem())
+			if i < v.Len() {
+				ev = v.Index(i)
+			}
+			n[i] = renameJSONKeys(e, ev, rename)
+		}
+	}
+	return node
+}
+
+// sortJSONObjects sorts the members of the objects in node by key, at any nesting level.
+func sortJSONObjects(node interface{}) {
+	switch n := node.(type) {
+	case jsonObject:
+		sort.SliceStable(n, func(i, j int) bool { return n[i].key < n[j].key })
+		for _, m := range n {
+			sortJSONObjects(m.value)
+		}
+	case []interface{}:
+		for _, e := range n {
+			sortJSONObjects(e)
+		}
+	}
+}
+
+// messageStruct returns the struct of the message v, unless the message has a special
+// JSON representation.
+func messageStruct(v reflect.Value) (reflect.Value, bool) {
+	t := v.Type()
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct || !t.Implements(protoMessageType) {
+		return reflect.Value{}, false
+	}
+	if _, ok := reflect.Zero(t).Interface().(wellKnownType); ok {
+		return reflect.Value{}, false
+	}
+	if v.IsNil() {
+		return reflect.Zero(t.Elem()), true
+	}
+	return v.Elem(), true
+}
+
+// messageField returns the field of the message struct sv named key in JSON,
+// or an invalid value if there is no such field.
+func messageField(sv reflect.Value, key string) reflect.Value {
+	props := proto.GetProperties(sv.Type())
+	for _, op := range props.OneofTypes {
+		if key != op.Prop.OrigName && key != op.Prop.JSONName {
+			continue
+		}
+		field := sv.Field(op.Field)
+		if !field.IsNil() && field.Elem().Type() == op.Type {
+			return field.Elem().Elem().Field(0)
+		}
+		return reflect.Zero(op.Type.Elem()).Field(0)
+	}
+	for _, p := range props.Prop {
+		if p.OrigName == key || p.JSONName == key {
+			if f := sv.FieldByName(p.Name); f.IsValid() && f.Kind() != reflect.Interface {
+				return f
+			}
+		}
+	}
+	return reflect.Value{}
+}
+
+// mapIndex returns the value of the map v for the JSON object key, or a zero value if
+// the key is absent or cannot be converted to the type of the keys of v.
+func mapIndex(v reflect.Value, key string) reflect.Value {
+	t := v.Type()
+	if t.Key().Kind() == reflect.String && !v.IsNil() {
+		if ev := v.MapIndex(reflect.ValueOf(key).Convert(t.Key())); ev.IsValid() {
+			return ev
+		}
+	}
+	return reflect.Zero(t.Elem())
+}
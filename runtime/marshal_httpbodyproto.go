@@ -1,14 +1,27 @@
 package runtime
 
 import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"unicode"
+
 	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/metadata"
 )
 
+// DownloadFilenameMetadataKey is the key of the header metadata by which gRPC servers can
+// set the name of the file in a google.api.HttpBody response. The response then has the header
+// "Content-Disposition: attachment", so that browsers download the file rather than display it.
+// The name is sanitized, and the metadata is not forwarded as a header of its own.
+const DownloadFilenameMetadataKey = MetadataPrefix + "download-filename"
+
 // SetHTTPBodyMarshaler overwrite the default marshaler with the HTTPBodyMarshaler
 func SetHTTPBodyMarshaler(serveMux *ServeMux) {
-	serveMux.marshalers.mimeMap[MIMEWildcard] = &HTTPBodyMarshaler{
+	serveMux.marshalers.add(MIMEWildcard, &HTTPBodyMarshaler{
 		Marshaler: &JSONPb{OrigName: true},
-	}
+	})
 }
 
 // HTTPBodyMarshaler is a Marshaler which supports marshaling of a
@@ -41,3 +54,57 @@ func (h *HTTPBodyMarshaler) Marshal(v interface{}) ([]byte, error) {
 	}
 	return h.Marshaler.Marshal(v)
 }
+
+// handleDownloadFilename sets the header Content-Disposition for the filename set in md for an
+// HttpBody response. It returns md without the filename.
+func handleDownloadFilename(w http.ResponseWriter, md ServerMetadata) ServerMetadata {
+	vals := md.HeaderMD[DownloadFilenameMetadataKey]
+	if len(vals) == 0 {
+		return md
+	}
+	md.HeaderMD = withoutMetadata(md.HeaderMD, DownloadFilenameMetadataKey)
+	if v := contentDisposition(vals[0]); v != "" {
+		w.Header().Set("Content-Disposition", v)
+	}
+	return md
+}
+
+// contentDisposition returns the value of the header Content-Disposition for an attachment
+// named filename, as described in RFC 6266, or "" if nothing is left of filename once sanitized.
+// Directories and control characters are removed. The "filename" parameter has an ASCII
+// fallback of the name, while the "filename*" parameter has the name in UTF-8.
+func contentDisposition(filename string) string {
+	filename = path.Base(strings.Replace(filename, "\\", "/", -1))
+	filename = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == unicode.ReplacementChar {
+			return -1
+		}
+		return r
+	}, filename)
+	filename = strings.TrimSpace(filename)
+	if filename == "" || filename == "." || filename == "/" || filename == ".." {
+		return ""
+	}
+
+	fallback := strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || r == '"' || r == '\\' || r == '%' {
+			return '_'
+		}
+		return r
+	}, filename)
+	v := `attachment; filename="` + fallback + `"`
+	if fallback != filename {
+		v += "; filename*=UTF-8''" + url.PathEscape(filename)
+	}
+	return v
+}
+
+// withoutMetadata returns md without the metadata key, leaving md unchanged.
+func withoutMetadata(md metadata.MD, key string) metadata.MD {
+	if _, ok := md[key]; !ok {
+		return md
+	}
+	c := md.Copy()
+	delete(c, key)
+	return c
+}
@@ -2,10 +2,14 @@ package runtime_test
 
 import (
 	"bytes"
+	"context"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
 	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/metadata"
 )
 
 func TestHTTPBodyContentType(t *testing.T) {
@@ -47,3 +51,71 @@ func TestHTTPBodyMarshal(t *testing.T) {
 
 	}
 }
+
+func TestHTTPBodyDownloadFilename(t *testing.T) {
+	for _, spec := range []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{
+			name:     "plain",
+			filename: "report.csv",
+			want:     `attachment; filename="report.csv"`,
+		},
+		{
+			name:     "directories",
+			filename: "../../etc/passwd",
+			want:     `attachment; filename="passwd"`,
+		},
+		{
+			name:     "windows directories",
+			filename: `C:\\exports\\report.csv`,
+			want:     `attachment; filename="report.csv"`,
+		},
+		{
+			name:     "quotes and control characters",
+			filename: "a\"b\r\nSet-Cookie: x.csv",
+			want:     `attachment; filename="a_bSet-Cookie: x.csv"; filename*=UTF-8''a%22bSet-Cookie:%20x.csv`,
+		},
+		{
+			name:     "non ASCII",
+			filename: "résumé.pdf",
+			want:     `attachment; filename="r_sum_.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`,
+		},
+		{
+			name:     "nothing left",
+			filename: "../",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			md := runtime.ServerMetadata{
+				HeaderMD: metadata.Pairs(runtime.DownloadFilenameMetadataKey, spec.filename),
+			}
+			ctx := runtime.NewServerMetadataContext(context.Background(), md)
+			req := httptest.NewRequest("GET", "http://example.com/export", nil)
+			w := httptest.NewRecorder()
+			body := &httpbody.HttpBody{ContentType: "text/csv", Data: []byte("a,b\n")}
+			runtime.ForwardResponseMessage(ctx, runtime.NewServeMux(), &runtime.HTTPBodyMarshaler{Marshaler: &runtime.JSONPb{}}, w, req, body)
+
+			if got := w.Header().Get("Content-Disposition"); got != spec.want {
+				t.Errorf("Content-Disposition = %q; want %q", got, spec.want)
+			}
+			if got := w.Header().Get("Grpc-Metadata-" + runtime.DownloadFilenameMetadataKey); got != "" {
+				t.Errorf("the filename is forwarded as a header: %q", got)
+			}
+			if got, want := w.Body.String(), "a,b\n"; got != want {
+				t.Errorf("w.Body = %q; want %q", got, want)
+			}
+		})
+	}
+
+	// Other messages are not downloads.
+	md := runtime.ServerMetadata{HeaderMD: metadata.Pairs(runtime.DownloadFilenameMetadataKey, "report.json")}
+	ctx := runtime.NewServerMetadataContext(context.Background(), md)
+	w := httptest.NewRecorder()
+	runtime.ForwardResponseMessage(ctx, runtime.NewServeMux(), &runtime.JSONPb{}, w, httptest.NewRequest("GET", "http://example.com/foo", nil), &pb.SimpleMessage{Id: "foo"})
+	if got := w.Header().Get("Content-Disposition"); got != "" {
+		t.Errorf("Content-Disposition = %q; want none for other messages", got)
+	}
+}
@@ -5,10 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
+	"strconv"
+	"time"
 
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
 )
 
 // JSONPb is a Marshaler which marshals/unmarshals into/from JSON
@@ -17,8 +22,157 @@ import (
 //
 // The NewDecoder method returns a DecoderWrapper, so the underlying
 // *json.Decoder methods can be used.
+//
+// Additional behavior can be enabled by creating a JSONPbWithOptions with NewJSONPb.
 type JSONPb jsonpb.Marshaler
 
+// JSONPbWithOptions is a Marshaler like JSONPb with additional behavior, which has no
+// counterpart in jsonpb.Marshaler, enabled by the options given to NewJSONPb.
+//
+// The fields of the embedded JSONPb can be set as usual.
+type JSONPbWithOptions struct {
+	JSONPb
+
+	opts jsonPbOptions
+}
+
+// jsonPbOptions holds the optional behavior of a JSONPb which has no
+// counterpart in jsonpb.Marshaler.
+type jsonPbOptions struct {
+	rejectDuplicateKeys bool
+	specialFloats       SpecialFloatMode
+	timestampFormat     TimestampFormat
+	keyTransform        func(key string) string
+	rejectUnknownEnums  bool
+	deterministic       bool
+	// allowUnknown overrides allowUnknownFields for a single request if it is not nil.
+	allowUnknown *bool
+}
+
+// allowsUnknownFields tells whether decoding ignores unknown fields instead of failing.
+func (o jsonPbOptions) allowsUnknownFields() bool {
+	if o.allowUnknown != nil {
+		return *o.allowUnknown
+	}
+	return allowUnknownFields
+}
+
+// rewritesOutput tells whether the JSON produced by jsonpb has to be rewritten.
+func (o jsonPbOptions) rewritesOutput() bool {
+	return o.specialFloats != SpecialFloatsAsStrings || o.timestampFormat != TimestampRFC3339 || o.keyTransform != nil || o.deterministic
+}
+
+// rewritesInput tells whether the JSON consumed by jsonpb has to be rewritten.
+func (o jsonPbOptions) rewritesInput() bool {
+	return o.timestampFormat != TimestampRFC3339 || o.keyTransform != nil || o.rejectUnknownEnums
+}
+
+// JSONPbOption is an option that can be given to NewJSONPb.
+type JSONPbOption func(*JSONPbWithOptions)
+
+// WithRejectDuplicateKeys returns a JSONPbOption which makes the decoder fail
+// when an object in the input has the same key more than once, at any nesting level.
+// By default the last occurrence of a duplicate key wins.
+func WithRejectDuplicateKeys() JSONPbOption {
+	return func(j *JSONPbWithOptions) {
+		j.opts.rejectDuplicateKeys = true
+	}
+}
+
+// SpecialFloatMode is how JSONPb marshals the special floating point values
+// NaN, +Inf and -Inf in float, double, FloatValue and DoubleValue fields.
+type SpecialFloatMode int
+
+const (
+	// SpecialFloatsAsStrings marshals special values as the strings "NaN", "Infinity"
+	// and "-Infinity", as specified by the proto3 JSON mapping. This is the default.
+	SpecialFloatsAsStrings SpecialFloatMode = iota
+	// SpecialFloatsAsNull marshals special values as null.
+	SpecialFloatsAsNull
+	// SpecialFloatsReject makes marshaling fail when a message has a special value.
+	SpecialFloatsReject
+)
+
+// WithSpecialFloats returns a JSONPbOption which marshals special floating point
+// values according to mode.
+func WithSpecialFloats(mode SpecialFloatMode) JSONPbOption {
+	return func(j *JSONPbWithOptions) {
+		j.opts.specialFloats = mode
+	}
+}
+
+// TimestampFormat is how JSONPb marshals google.protobuf.Timestamp fields.
+type TimestampFormat int
+
+const (
+	// TimestampRFC3339 marshals timestamps as RFC 3339 strings, like "1972-01-01T10:00:20.021Z",
+	// as specified by the proto3 JSON mapping. This is the default.
+	TimestampRFC3339 TimestampFormat = iota
+	// TimestampEpochMillis marshals timestamps as the number of milliseconds since the Unix epoch.
+	// Sub-millisecond precision is lost.
+	TimestampEpochMillis
+	// TimestampEpochSeconds marshals timestamps as the number of seconds since the Unix epoch.
+	// Sub-second precision is lost.
+	TimestampEpochSeconds
+)
+
+// WithTimestampFormat returns a JSONPbOption which marshals google.protobuf.Timestamp fields
+// according to format. Timestamps are unmarshaled from numbers in format, and still from
+// RFC 3339 strings.
+func WithTimestampFormat(format TimestampFormat) JSONPbOption {
+	return func(j *JSONPbWithOptions) {
+		j.opts.timestampFormat = format
+	}
+}
+
+// WithKeyTransform returns a JSONPbOption which replaces the keys of the fields of messages in the
+// marshaled JSON with the keys returned by transform, at any nesting level, e.g. to name them in
+// PascalCase. transform is given the key jsonpb marshals, which depends on OrigName. The keys of
+// maps and of the well-known types, such as google.protobuf.Struct, are kept.
+// Objects are unmarshaled from the transformed keys, and still from the keys of jsonpb.
+func WithKeyTransform(transform func(key string) string) JSONPbOption {
+	return func(j *JSONPbWithOptions) {
+		j.opts.keyTransform = transform
+	}
+}
+
+// WithRejectUnknownEnumValues returns a JSONPbOption which makes the decoder fail when an enum
+// field, at any nesting level, is given an integer which is not a value of the enum. Integers are
+// accepted for enum fields like their names, as specified by the proto3 JSON mapping, and by
+// default the unknown ones are kept as is. The generated handlers reply to the failures with an
+// "InvalidArgument" error.
+func WithRejectUnknownEnumValues() JSONPbOption {
+	return func(j *JSONPbWithOptions) {
+		j.opts.rejectUnknownEnums = true
+	}
+}
+
+// WithDeterministicOutput returns a JSONPbOption which sorts the members of the objects in the
+// marshaled JSON by key, at any nesting level, after WithKeyTransform is applied, so that equal
+// messages are always marshaled into the same bytes, e.g. for caching or golden tests. The
+// members of Any objects are sorted too, and the "@type" member comes first.
+func WithDeterministicOutput() JSONPbOption {
+	return func(j *JSONPbWithOptions) {
+		j.opts.deterministic = true
+	}
+}
+
+// NewJSONPb returns a new JSONPbWithOptions with the given options applied.
+// The fields of the embedded JSONPb of the returned marshaler can be set as usual.
+func NewJSONPb(opts ...JSONPbOption) *JSONPbWithOptions {
+	j := &JSONPbWithOptions{}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+// withOptions returns a JSONPbWithOptions with the settings of j and no option, which
+// implements the methods of j.
+func (j *JSONPb) withOptions() *JSONPbWithOptions {
+	return &JSONPbWithOptions{JSONPb: *j}
+}
+
 // ContentType always returns "application/json".
 func (*JSONPb) ContentType() string {
 	return "application/json"
@@ -26,18 +180,181 @@ func (*JSONPb) ContentType() string {
 
 // Marshal marshals "v" into JSON.
 func (j *JSONPb) Marshal(v interface{}) ([]byte, error) {
-	if _, ok := v.(proto.Message); !ok {
+	return j.withOptions().Marshal(v)
+}
+
+// Unmarshal unmarshals JSON "data" into "v"
+func (j *JSONPb) Unmarshal(data []byte, v interface{}) error {
+	return unmarshalJSONPb(data, v, jsonPbOptions{})
+}
+
+// NewDecoder returns a Decoder which reads JSON stream from "r".
+func (j *JSONPb) NewDecoder(r io.Reader) Decoder {
+	return j.withOptions().NewDecoder(r)
+}
+
+// NewEncoder returns an Encoder which writes JSON stream into "w".
+func (j *JSONPb) NewEncoder(w io.Writer) Encoder {
+	return j.withOptions().NewEncoder(w)
+}
+
+// Delimiter for newline encoded JSON streams.
+func (j *JSONPb) Delimiter() []byte {
+	return []byte("\n")
+}
+
+// marshaler returns the jsonpb.Marshaler corresponding to the settings of j.
+func (j *JSONPbWithOptions) marshaler() *jsonpb.Marshaler {
+	return (*jsonpb.Marshaler)(&j.JSONPb)
+}
+
+// ContentType always returns "application/json".
+func (*JSONPbWithOptions) ContentType() string {
+	return "application/json"
+}
+
+// Marshal marshals "v" into JSON.
+func (j *JSONPbWithOptions) Marshal(v interface{}) ([]byte, error) {
+	if !j.opts.rewritesOutput() {
+		return j.marshalJSONPb(v)
+	}
+
+	buf, err := j.marshalJSONPb(v)
+	if err != nil {
+		return nil, err
+	}
+	return j.rewriteOutput(buf, v)
+}
+
+// marshalJSONPb marshals "v" into JSON as jsonpb does, without applying the options of j.
+func (j *JSONPbWithOptions) marshalJSONPb(v interface{}) ([]byte, error) {
+	p, ok := v.(proto.Message)
+	if !ok {
 		return j.marshalNonProtoField(v)
 	}
 
 	var buf bytes.Buffer
-	if err := j.marshalTo(&buf, v); err != nil {
+	if err := j.marshaler().Marshal(&buf, p); err != nil {
 		return nil, err
 	}
 	return buf.Bytes(), nil
 }
 
-func (j *JSONPb) marshalTo(w io.Writer, v interface{}) error {
+// rewriteOutput applies the options of j to buf, the JSON marshaled from v by jsonpb.
+func (j *JSONPbWithOptions) rewriteOutput(buf []byte, v interface{}) ([]byte, error) {
+	tree, err := decodeJSONTree(buf)
+	if err != nil {
+		return nil, err
+	}
+	tree, err = walkJSONTree(tree, reflect.ValueOf(v), func(node interface{}, v reflect.Value) (interface{}, error) {
+		if j.opts.specialFloats != SpecialFloatsAsStrings {
+			var err error
+			if node, err = j.opts.specialFloats.rewrite(node, v); err != nil {
+				return nil, err
+			}
+		}
+		if j.opts.timestampFormat != TimestampRFC3339 {
+			return j.opts.timestampFormat.marshal(node, v)
+		}
+		return node, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if j.opts.keyTransform != nil {
+		tree = renameJSONKeys(tree, reflect.ValueOf(v), func(sv reflect.Value, key string) (string, reflect.Value) {
+			return j.opts.keyTransform(key), messageField(sv, key)
+		})
+	}
+	if j.opts.deterministic {
+		sortJSONObjects(tree)
+	}
+	return encodeJSONTree(tree, j.Indent)
+}
+
+// rewrite returns the replacement of node, if it is a special value marshaled from
+// the floating point value v.
+func (mode SpecialFloatMode) rewrite(node interface{}, v reflect.Value) (interface{}, error) {
+	s, ok := node.(string)
+	if !ok || (s != "NaN" && s != "Infinity" && s != "-Infinity") || !isFloatValue(v) {
+		return node, nil
+	}
+	if mode == SpecialFloatsReject {
+		return nil, fmt.Errorf("special floating point value %s cannot be marshaled", s)
+	}
+	return nil, nil
+}
+
+// marshal returns the replacement of node, if it is a timestamp marshaled from v.
+func (format TimestampFormat) marshal(node interface{}, v reflect.Value) (interface{}, error) {
+	s, ok := node.(string)
+	if !ok || !v.IsValid() || v.Type() != timestampType {
+		return node, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return nil, err
+	}
+	n := t.Unix()
+	if format == TimestampEpochMillis {
+		n = n*1000 + int64(t.Nanosecond())/int64(time.Millisecond)
+	}
+	return json.Number(strconv.FormatInt(n, 10)), nil
+}
+
+// unmarshal returns the replacement of node, if it is a number to unmarshal into the timestamp v.
+func (format TimestampFormat) unmarshal(node interface{}, v reflect.Value) (interface{}, error) {
+	num, ok := node.(json.Number)
+	if !ok || !v.IsValid() || v.Type() != timestampType {
+		return node, nil
+	}
+	unit := time.Second
+	if format == TimestampEpochMillis {
+		unit = time.Millisecond
+	}
+	var t time.Time
+	if n, err := num.Int64(); err == nil {
+		perSecond := int64(time.Second / unit)
+		t = time.Unix(n/perSecond, n%perSecond*int64(unit))
+	} else {
+		f, err := num.Float64()
+		if err != nil {
+			return nil, err
+		}
+		sec, frac := math.Modf(f * float64(unit) / float64(time.Second))
+		t = time.Unix(int64(sec), int64(frac*float64(time.Second)))
+	}
+	return t.UTC().Format(time.RFC3339Nano), nil
+}
+
+var (
+	doubleValueType = reflect.TypeOf((*wrappers.DoubleValue)(nil))
+	floatValueType  = reflect.TypeOf((*wrappers.FloatValue)(nil))
+	timestampType   = reflect.TypeOf((*timestamp.Timestamp)(nil))
+)
+
+// isFloatValue tells whether v is a floating point field, or a wrapper of one.
+func isFloatValue(v reflect.Value) bool {
+	if !v.IsValid() {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return true
+	}
+	return v.Type() == doubleValueType || v.Type() == floatValueType
+}
+
+func (j *JSONPbWithOptions) marshalTo(w io.Writer, v interface{}) error {
+	if j.opts.rewritesOutput() {
+		buf, err := j.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(buf)
+		return err
+	}
+
 	p, ok := v.(proto.Message)
 	if !ok {
 		buf, err := j.marshalNonProtoField(v)
@@ -47,7 +364,7 @@ func (j *JSONPb) marshalTo(w io.Writer, v interface{}) error {
 		_, err = w.Write(buf)
 		return err
 	}
-	return (*jsonpb.Marshaler)(j).Marshal(w, p)
+	return j.marshaler().Marshal(w, p)
 }
 
 var (
@@ -60,7 +377,7 @@ var (
 // but it is only capable of marshaling non-message field values of protobuf,
 // i.e. primitive types, enums; pointers to primitives or enums; maps from
 // integer/string types to primitives/enums/pointers to messages.
-func (j *JSONPb) marshalNonProtoField(v interface{}) ([]byte, error) {
+func (j *JSONPbWithOptions) marshalNonProtoField(v interface{}) ([]byte, error) {
 	if v == nil {
 		return []byte("null"), nil
 	}
@@ -93,7 +410,7 @@ func (j *JSONPb) marshalNonProtoField(v interface{}) ([]byte, error) {
 						return nil, err
 					}
 				}
-				if err = (*jsonpb.Marshaler)(j).Marshal(&buf, rv.Index(i).Interface().(proto.Message)); err != nil {
+				if err = j.marshaler().Marshal(&buf, rv.Index(i).Interface().(proto.Message)); err != nil {
 					return nil, err
 				}
 			}
@@ -109,7 +426,7 @@ func (j *JSONPb) marshalNonProtoField(v interface{}) ([]byte, error) {
 	if rv.Kind() == reflect.Map {
 		m := make(map[string]*json.RawMessage)
 		for _, k := range rv.MapKeys() {
-			buf, err := j.Marshal(rv.MapIndex(k).Interface())
+			buf, err := j.marshalJSONPb(rv.MapIndex(k).Interface())
 			if err != nil {
 				return nil, err
 			}
@@ -127,30 +444,32 @@ func (j *JSONPb) marshalNonProtoField(v interface{}) ([]byte, error) {
 }
 
 // Unmarshal unmarshals JSON "data" into "v"
-func (j *JSONPb) Unmarshal(data []byte, v interface{}) error {
-	return unmarshalJSONPb(data, v)
+func (j *JSONPbWithOptions) Unmarshal(data []byte, v interface{}) error {
+	return unmarshalJSONPb(data, v, j.opts)
 }
 
 // NewDecoder returns a Decoder which reads JSON stream from "r".
-func (j *JSONPb) NewDecoder(r io.Reader) Decoder {
+func (j *JSONPbWithOptions) NewDecoder(r io.Reader) Decoder {
 	d := json.NewDecoder(r)
-	return DecoderWrapper{Decoder: d}
+	return DecoderWrapper{Decoder: d, opts: j.opts}
 }
 
 // DecoderWrapper is a wrapper around a *json.Decoder that adds
 // support for protos to the Decode method.
 type DecoderWrapper struct {
 	*json.Decoder
+
+	opts jsonPbOptions
 }
 
 // Decode wraps the embedded decoder's Decode method to support
 // protos using a jsonpb.Unmarshaler.
 func (d DecoderWrapper) Decode(v interface{}) error {
-	return decodeJSONPb(d.Decoder, v)
+	return decodeJSONPb(d.Decoder, v, d.opts)
 }
 
 // NewEncoder returns an Encoder which writes JSON stream into "w".
-func (j *JSONPb) NewEncoder(w io.Writer) Encoder {
+func (j *JSONPbWithOptions) NewEncoder(w io.Writer) Encoder {
 	return EncoderFunc(func(v interface{}) error {
 		if err := j.marshalTo(w, v); err != nil {
 			return err
@@ -162,21 +481,40 @@ func (j *JSONPb) NewEncoder(w io.Writer) Encoder {
 	})
 }
 
-func unmarshalJSONPb(data []byte, v interface{}) error {
+func unmarshalJSONPb(data []byte, v interface{}, opts jsonPbOptions) error {
 	d := json.NewDecoder(bytes.NewReader(data))
-	return decodeJSONPb(d, v)
+	return decodeJSONPb(d, v, opts)
 }
 
-func decodeJSONPb(d *json.Decoder, v interface{}) error {
+func decodeJSONPb(d *json.Decoder, v interface{}, opts jsonPbOptions) error {
+	if opts.rejectDuplicateKeys || opts.rewritesInput() {
+		var raw json.RawMessage
+		if err := d.Decode(&raw); err != nil {
+			return err
+		}
+		if opts.rejectDuplicateKeys {
+			if err := checkDuplicateKeys(raw); err != nil {
+				return err
+			}
+		}
+		if opts.rewritesInput() {
+			var err error
+			if raw, err = rewriteInput(raw, v, opts); err != nil {
+				return err
+			}
+		}
+		d = json.NewDecoder(bytes.NewReader(raw))
+	}
+
 	p, ok := v.(proto.Message)
 	if !ok {
-		return decodeNonProtoField(d, v)
+		return decodeNonProtoField(d, v, opts)
 	}
-	unmarshaler := &jsonpb.Unmarshaler{AllowUnknownFields: allowUnknownFields}
+	unmarshaler := &jsonpb.Unmarshaler{AllowUnknownFields: opts.allowsUnknownFields()}
 	return unmarshaler.UnmarshalNext(d, p)
 }
 
-func decodeNonProtoField(d *json.Decoder, v interface{}) error {
+func decodeNonProtoField(d *json.Decoder, v interface{}, opts jsonPbOptions) error {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Ptr {
 		return fmt.Errorf("%T is not a pointer", v)
@@ -186,7 +524,7 @@ func decodeNonProtoField(d *json.Decoder, v interface{}) error {
 			rv.Set(reflect.New(rv.Type().Elem()))
 		}
 		if rv.Type().ConvertibleTo(typeProtoMessage) {
-			unmarshaler := &jsonpb.Unmarshaler{AllowUnknownFields: allowUnknownFields}
+			unmarshaler := &jsonpb.Unmarshaler{AllowUnknownFields: opts.allowsUnknownFields()}
 			return unmarshaler.UnmarshalNext(d, rv.Interface().(proto.Message))
 		}
 		rv = rv.Elem()
@@ -211,7 +549,7 @@ func decodeNonProtoField(d *json.Decoder, v interface{}) error {
 			}
 			bk := result[0]
 			bv := reflect.New(rv.Type().Elem())
-			if err := unmarshalJSONPb([]byte(*v), bv.Interface()); err != nil {
+			if err := unmarshalJSONPb([]byte(*v), bv.Interface(), opts); err != nil {
 				return err
 			}
 			rv.SetMapIndex(bk, bv.Elem())
@@ -237,6 +575,125 @@ func decodeNonProtoField(d *json.Decoder, v interface{}) error {
 	return d.Decode(v)
 }
 
+// rewriteInput applies opts to data, the JSON to unmarshal into v, before it is given to jsonpb.
+func rewriteInput(data []byte, v interface{}, opts jsonPbOptions) ([]byte, error) {
+	tree, err := decodeJSONTree(data)
+	if err != nil {
+		return nil, err
+	}
+	if opts.keyTransform != nil {
+		tree = renameJSONKeys(tree, reflect.ValueOf(v), func(sv reflect.Value, key string) (string, reflect.Value) {
+			key = untransformKey(sv, key, opts.keyTransform)
+			return key, messageField(sv, key)
+		})
+	}
+	if opts.timestampFormat != TimestampRFC3339 {
+		if tree, err = walkJSONTree(tree, reflect.ValueOf(v), opts.timestampFormat.unmarshal); err != nil {
+			return nil, err
+		}
+	}
+	if opts.rejectUnknownEnums {
+		if tree, err = walkJSONTree(tree, reflect.ValueOf(v), checkEnumValue); err != nil {
+			return nil, err
+		}
+	}
+	return encodeJSONTree(tree, "")
+}
+
+var protoEnumType = reflect.TypeOf((*protoEnum)(nil)).Elem()
+
+// checkEnumValue returns an error if node is an integer which is not a value of the enum v.
+func checkEnumValue(node interface{}, v reflect.Value) (interface{}, error) {
+	num, ok := node.(json.Number)
+	if !ok || !v.IsValid() {
+		return node, nil
+	}
+	t := v.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Int32 || !t.Implements(protoEnumType) {
+		return node, nil
+	}
+	n, err := strconv.ParseInt(num.String(), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %s for enum %s", num, t.Name())
+	}
+	// The String method of generated enums returns the number itself for unknown values,
+	// while names cannot be numbers.
+	if e := reflect.ValueOf(n).Convert(t).Interface().(fmt.Stringer); e.String() == num.String() {
+		return nil, fmt.Errorf("unknown value %s for enum %s", num, t.Name())
+	}
+	return node, nil
+}
+
+// untransformKey returns the name of the field of the message struct sv whose key is
+// transformed into key, or key if there is none.
+func untransformKey(sv reflect.Value, key string, transform func(string) string) string {
+	if messageField(sv, key).IsValid() {
+		return key
+	}
+	props := proto.GetProperties(sv.Type())
+	for _, op := range props.OneofTypes {
+		if transform(op.Prop.OrigName) == key || transform(op.Prop.JSONName) == key {
+			return op.Prop.OrigName
+		}
+	}
+	for _, p := range props.Prop {
+		if p.OrigName == "" {
+			continue
+		}
+		if transform(p.OrigName) == key || transform(p.JSONName) == key {
+			return p.OrigName
+		}
+	}
+	return key
+}
+
+// checkDuplicateKeys returns an error if any JSON object in data has
+// the same key more than once.
+func checkDuplicateKeys(data []byte) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	// keys holds the keys seen so far for each object being read, innermost last.
+	// A nil entry stands for an array.
+	var keys []map[string]bool
+	// isKey tells whether the next string token is an object key.
+	isKey := false
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case json.Delim:
+			switch t {
+			case '{':
+				keys = append(keys, make(map[string]bool))
+				isKey = true
+				continue
+			case '[':
+				keys = append(keys, nil)
+			default:
+				keys = keys[:len(keys)-1]
+			}
+		case string:
+			if isKey {
+				seen := keys[len(keys)-1]
+				if seen[t] {
+					return fmt.Errorf("duplicate key %q in JSON object", t)
+				}
+				seen[t] = true
+				isKey = false
+				continue
+			}
+		}
+		isKey = len(keys) > 0 && keys[len(keys)-1] != nil
+	}
+}
+
 type protoEnum interface {
 	fmt.Stringer
 	EnumDescriptor() ([]byte, []int)
@@ -245,7 +702,7 @@ type protoEnum interface {
 var typeProtoMessage = reflect.TypeOf((*proto.Message)(nil)).Elem()
 
 // Delimiter for newline encoded JSON streams.
-func (j *JSONPb) Delimiter() []byte {
+func (j *JSONPbWithOptions) Delimiter() []byte {
 	return []byte("\n")
 }
 
@@ -2,10 +2,13 @@ package runtime_test
 
 import (
 	"bytes"
+	"io/ioutil"
+	"math"
 	"reflect"
 	"strconv"
 	"strings"
 	"testing"
+	"unicode"
 
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
@@ -487,6 +490,82 @@ func TestJSONPbDecoderUnknownField(t *testing.T) {
 	}
 }
 
+func TestJSONPbDecoderDuplicateKeys(t *testing.T) {
+	for _, spec := range []struct {
+		name string
+		data string
+	}{
+		{
+			name: "top level",
+			data: `{"uuid": "6EC2446F-7E89-4127-B3E6-5C05E6BECBA7", "uuid": "A1"}`,
+		},
+		{
+			name: "nested",
+			data: `{"nested": [{"name": "foo"}, {"name": "bar", "amount": 1, "name": "baz"}]}`,
+		},
+		{
+			name: "map value",
+			data: `{"mapped_nested_value": {"a": {"name": "foo", "name": "bar"}}}`,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			m := runtime.NewJSONPb(runtime.WithRejectDuplicateKeys())
+
+			var got examplepb.ABitOfEverything
+			if err := m.NewDecoder(strings.NewReader(spec.data)).Decode(&got); err == nil {
+				t.Errorf("dec.Decode(&got) not failed; want duplicate key error; data=%q", spec.data)
+			}
+			if err := m.Unmarshal([]byte(spec.data), &got); err == nil {
+				t.Errorf("m.Unmarshal(%q, &got) not failed; want duplicate key error", spec.data)
+			}
+
+			// The default marshaler keeps the last occurrence.
+			var def runtime.JSONPb
+			if err := def.Unmarshal([]byte(spec.data), &got); err != nil {
+				t.Errorf("def.Unmarshal(%q, &got) failed with %v; want success", spec.data, err)
+			}
+		})
+	}
+}
+
+func TestJSONPbDecoderDuplicateKeysLastWins(t *testing.T) {
+	var m runtime.JSONPb
+	data := `{"uuid": "first", "uuid": "second"}`
+
+	var got examplepb.ABitOfEverything
+	if err := m.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("m.Unmarshal(%q, &got) failed with %v; want success", data, err)
+	}
+	if got, want := got.Uuid, "second"; got != want {
+		t.Errorf("got.Uuid = %q; want %q", got, want)
+	}
+
+	strict := runtime.NewJSONPb(runtime.WithRejectDuplicateKeys())
+	data = `{"uuid": "first", "nested": [{"name": "a"}, {"name": "b"}], "single_nested": {"name": "c"}}`
+	if err := strict.Unmarshal([]byte(data), &got); err != nil {
+		t.Errorf("strict.Unmarshal(%q, &got) failed with %v; want success", data, err)
+	}
+}
+
+func TestJSONPbWithOptionsEmbedsJSONPb(t *testing.T) {
+	// JSONPb stays convertible from and to jsonpb.Marshaler.
+	j := runtime.JSONPb(jsonpb.Marshaler{OrigName: true})
+	if !(*jsonpb.Marshaler)(&j).OrigName {
+		t.Errorf("(*jsonpb.Marshaler)(&j).OrigName = false; want true")
+	}
+
+	m := runtime.NewJSONPb(runtime.WithRejectDuplicateKeys())
+	m.OrigName = true
+	msg := &examplepb.ABitOfEverything{SingleNested: &examplepb.ABitOfEverything_Nested{Name: "bar"}}
+	buf, err := m.Marshal(msg)
+	if err != nil {
+		t.Fatalf("m.Marshal(%v) failed with %v; want success", msg, err)
+	}
+	if got, want := string(buf), `{"single_nested":{"name":"bar"}}`; got != want {
+		t.Errorf("m.Marshal(%v) = %s; want %s", msg, got, want)
+	}
+}
+
 var (
 	fieldFixtures = []struct {
 		data          interface{}
@@ -807,3 +886,378 @@ func TestJSONPbMarshalResponseBodies(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONPbMarshalSpecialFloats(t *testing.T) {
+	msg := &examplepb.ABitOfEverything{
+		FloatValue:  float32(math.NaN()),
+		DoubleValue: math.Inf(1),
+		Uuid:        "NaN",
+	}
+	wrapped := &wrappers.DoubleValue{Value: math.Inf(-1)}
+
+	for _, spec := range []struct {
+		name        string
+		mode        runtime.SpecialFloatMode
+		wantMsg     string
+		wantWrapped string
+		wantErr     bool
+	}{
+		{
+			name:        "strings",
+			mode:        runtime.SpecialFloatsAsStrings,
+			wantMsg:     `{"uuid":"NaN","float_value":"NaN","double_value":"Infinity"}`,
+			wantWrapped: `"-Infinity"`,
+		},
+		{
+			name:        "null",
+			mode:        runtime.SpecialFloatsAsNull,
+			wantMsg:     `{"uuid":"NaN","float_value":null,"double_value":null}`,
+			wantWrapped: `null`,
+		},
+		{
+			name:    "reject",
+			mode:    runtime.SpecialFloatsReject,
+			wantErr: true,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			m := runtime.NewJSONPb(runtime.WithSpecialFloats(spec.mode))
+			m.OrigName = true
+
+			buf, err := m.Marshal(msg)
+			if spec.wantErr {
+				if err == nil {
+					t.Errorf("m.Marshal(%v) not failed; want an error", msg)
+				}
+				if _, err := m.Marshal(wrapped); err == nil {
+					t.Errorf("m.Marshal(%v) not failed; want an error", wrapped)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("m.Marshal(%v) failed with %v; want success", msg, err)
+			}
+			if got := string(buf); got != spec.wantMsg {
+				t.Errorf("m.Marshal(%v) = %s; want %s", msg, got, spec.wantMsg)
+			}
+
+			buf, err = m.Marshal(wrapped)
+			if err != nil {
+				t.Fatalf("m.Marshal(%v) failed with %v; want success", wrapped, err)
+			}
+			if got := string(buf); got != spec.wantWrapped {
+				t.Errorf("m.Marshal(%v) = %s; want %s", wrapped, got, spec.wantWrapped)
+			}
+
+			var enc bytes.Buffer
+			if err := m.NewEncoder(&enc).Encode(msg); err != nil {
+				t.Fatalf("enc.Encode(%v) failed with %v; want success", msg, err)
+			}
+			if got, want := enc.String(), spec.wantMsg+"\n"; got != want {
+				t.Errorf("enc.Encode(%v) wrote %q; want %q", msg, got, want)
+			}
+		})
+	}
+}
+
+func TestJSONPbTimestampFormat(t *testing.T) {
+	msg := &examplepb.ABitOfEverything{
+		TimestampValue: &timestamp.Timestamp{Seconds: 1500000000, Nanos: 123456789},
+	}
+	const rfc3339 = `{"timestamp_value":"2017-07-14T02:40:00.123456789Z"}`
+
+	for _, spec := range []struct {
+		name   string
+		format runtime.TimestampFormat
+		want   string
+		// wantNanos is the precision kept by a round trip.
+		wantNanos int32
+	}{
+		{
+			name:      "RFC 3339",
+			format:    runtime.TimestampRFC3339,
+			want:      rfc3339,
+			wantNanos: 123456789,
+		},
+		{
+			name:      "epoch milliseconds",
+			format:    runtime.TimestampEpochMillis,
+			want:      `{"timestamp_value":1500000000123}`,
+			wantNanos: 123000000,
+		},
+		{
+			name:   "epoch seconds",
+			format: runtime.TimestampEpochSeconds,
+			want:   `{"timestamp_value":1500000000}`,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			m := runtime.NewJSONPb(runtime.WithTimestampFormat(spec.format))
+			m.OrigName = true
+
+			buf, err := m.Marshal(msg)
+			if err != nil {
+				t.Fatalf("m.Marshal(%v) failed with %v; want success", msg, err)
+			}
+			if got := string(buf); got != spec.want {
+				t.Errorf("m.Marshal(%v) = %s; want %s", msg, got, spec.want)
+			}
+
+			var got examplepb.ABitOfEverything
+			if err := m.NewDecoder(strings.NewReader(spec.want)).Decode(&got); err != nil {
+				t.Fatalf("m.NewDecoder(%q).Decode(&got) failed with %v; want success", spec.want, err)
+			}
+			want := &timestamp.Timestamp{Seconds: 1500000000, Nanos: spec.wantNanos}
+			if !proto.Equal(got.TimestampValue, want) {
+				t.Errorf("got.TimestampValue = %v; want %v", got.TimestampValue, want)
+			}
+
+			// RFC 3339 strings are accepted whatever the format.
+			got.Reset()
+			if err := m.Unmarshal([]byte(rfc3339), &got); err != nil {
+				t.Fatalf("m.Unmarshal(%q, &got) failed with %v; want success", rfc3339, err)
+			}
+			if !proto.Equal(got.TimestampValue, msg.TimestampValue) {
+				t.Errorf("got.TimestampValue = %v; want %v", got.TimestampValue, msg.TimestampValue)
+			}
+		})
+	}
+}
+
+func TestJSONPbDecoderErrorsFromStream(t *testing.T) {
+	for _, body := range []string{
+		`{"repeatedStringValue": ["a", 1]}`,
+		`{"singleNested": {"amount": "x"}}`,
+		`{"uuid": "6EC2446F"`,
+	} {
+		var fromBytes, fromStream examplepb.ABitOfEverything
+		buffered := (&runtime.JSONPb{}).Unmarshal([]byte(body), &fromBytes)
+		streamed := (&runtime.JSONPb{}).NewDecoder(strings.NewReader(body)).Decode(&fromStream)
+		if buffered == nil || streamed == nil {
+			t.Errorf("decoding %q = %v, %v; want errors", body, buffered, streamed)
+			continue
+		}
+		if got, want := streamed.Error(), buffered.Error(); got != want {
+			t.Errorf("decoding %q from a stream failed with %q; want %q", body, got, want)
+		}
+	}
+}
+
+func BenchmarkJSONPbDecodeLargeBody(b *testing.B) {
+	msg := &examplepb.ABitOfEverything{}
+	for i := 0; i < 10000; i++ {
+		msg.RepeatedStringValue = append(msg.RepeatedStringValue, strings.Repeat("x", 100))
+	}
+	body, err := (&runtime.JSONPb{}).Marshal(msg)
+	if err != nil {
+		b.Fatalf("(&runtime.JSONPb{}).Marshal(%v) failed with %v; want success", msg, err)
+	}
+	b.Run("buffered", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			// As the request body was read before decoding it.
+			data, err := ioutil.ReadAll(bytes.NewReader(body))
+			if err != nil {
+				b.Fatal(err)
+			}
+			var got examplepb.ABitOfEverything
+			if err := (&runtime.JSONPb{}).NewDecoder(bytes.NewReader(data)).Decode(&got); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("streamed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var got examplepb.ABitOfEverything
+			if err := (&runtime.JSONPb{}).NewDecoder(bytes.NewReader(body)).Decode(&got); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// pascalCase converts snake_case and camelCase keys into PascalCase.
+func pascalCase(key string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range key {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func TestJSONPbKeyTransform(t *testing.T) {
+	msg := &examplepb.ABitOfEverything{
+		Uuid:         "6ba7b811",
+		SingleNested: &examplepb.ABitOfEverything_Nested{Name: "foo", Amount: 10},
+		Nested:       []*examplepb.ABitOfEverything_Nested{{Name: "bar"}},
+		OneofValue:   &examplepb.ABitOfEverything_OneofString{OneofString: "baz"},
+		MappedNestedValue: map[string]*examplepb.ABitOfEverything_Nested{
+			"nested_key": {Amount: 20},
+		},
+		TimestampValue: &timestamp.Timestamp{Seconds: 1500000000},
+	}
+	const want = `{"SingleNested":{"Name":"foo","Amount":10},"Uuid":"6ba7b811","Nested":[{"Name":"bar"}],` +
+		`"OneofString":"baz","MappedNestedValue":{"nested_key":{"Amount":20}},"TimestampValue":1500000000}`
+
+	for _, origName := range []bool{true, false} {
+		t.Run("OrigName="+strconv.FormatBool(origName), func(t *testing.T) {
+			m := runtime.NewJSONPb(runtime.WithKeyTransform(pascalCase), runtime.WithTimestampFormat(runtime.TimestampEpochSeconds))
+			m.OrigName = origName
+
+			buf, err := m.Marshal(msg)
+			if err != nil {
+				t.Fatalf("m.Marshal(%v) failed with %v; want success", msg, err)
+			}
+			if got := string(buf); got != want {
+				t.Errorf("m.Marshal(%v) = %s; want %s", msg, got, want)
+			}
+
+			var got examplepb.ABitOfEverything
+			if err := m.NewDecoder(strings.NewReader(want)).Decode(&got); err != nil {
+				t.Fatalf("m.NewDecoder(%q).Decode(&got) failed with %v; want success", want, err)
+			}
+			if !proto.Equal(&got, msg) {
+				t.Errorf("got = %v; want %v", &got, msg)
+			}
+
+			// The keys of jsonpb are accepted too.
+			const plain = `{"single_nested":{"name":"foo"},"repeatedStringValue":["a"]}`
+			got.Reset()
+			if err := m.Unmarshal([]byte(plain), &got); err != nil {
+				t.Fatalf("m.Unmarshal(%q, &got) failed with %v; want success", plain, err)
+			}
+			wantPlain := &examplepb.ABitOfEverything{
+				SingleNested:        &examplepb.ABitOfEverything_Nested{Name: "foo"},
+				RepeatedStringValue: []string{"a"},
+			}
+			if !proto.Equal(&got, wantPlain) {
+				t.Errorf("got = %v; want %v", &got, wantPlain)
+			}
+		})
+	}
+}
+
+func TestJSONPbDeterministicOutput(t *testing.T) {
+	msg := &examplepb.ABitOfEverything{
+		Uuid:         "6ba7b811",
+		SingleNested: &examplepb.ABitOfEverything_Nested{Name: "foo", Amount: 10},
+		MappedStringValue: map[string]string{
+			"c": "3", "a": "1", "b": "2",
+		},
+		MappedNestedValue: map[string]*examplepb.ABitOfEverything_Nested{
+			"y": {Name: "bar", Amount: 20},
+			"x": {Amount: 30},
+		},
+	}
+	const want = `{"mappedNestedValue":{"x":{"amount":30},"y":{"amount":20,"name":"bar"}},` +
+		`"mappedStringValue":{"a":"1","b":"2","c":"3"},"singleNested":{"amount":10,"name":"foo"},"uuid":"6ba7b811"}`
+
+	m := runtime.NewJSONPb(runtime.WithDeterministicOutput())
+	first, err := m.Marshal(msg)
+	if err != nil {
+		t.Fatalf("m.Marshal(%v) failed with %v; want success", msg, err)
+	}
+	if got := string(first); got != want {
+		t.Errorf("m.Marshal(%v) = %s; want %s", msg, got, want)
+	}
+	second, err := m.Marshal(proto.Clone(msg))
+	if err != nil {
+		t.Fatalf("m.Marshal(%v) failed with %v; want success", msg, err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Errorf("m.Marshal(%v) = %s, then %s; want identical bytes", msg, first, second)
+	}
+}
+
+func TestJSONPbIntegerEnumValues(t *testing.T) {
+	for _, spec := range []struct {
+		name   string
+		input  string
+		reject bool
+
+		want    *examplepb.ABitOfEverything
+		wantErr bool
+	}{
+		{
+			name:  "integer value",
+			input: `{"enum_value": 1}`,
+			want:  &examplepb.ABitOfEverything{EnumValue: examplepb.NumericEnum_ONE},
+		},
+		{
+			name:   "integer value rejecting unknown values",
+			input:  `{"enum_value": 1, "repeated_enum_value": [0, 1], "map_value": {"a": 1}}`,
+			reject: true,
+			want: &examplepb.ABitOfEverything{
+				EnumValue:         examplepb.NumericEnum_ONE,
+				RepeatedEnumValue: []examplepb.NumericEnum{examplepb.NumericEnum_ZERO, examplepb.NumericEnum_ONE},
+				MapValue:          map[string]examplepb.NumericEnum{"a": examplepb.NumericEnum_ONE},
+			},
+		},
+		{
+			name:   "name",
+			input:  `{"enum_value": "ONE"}`,
+			reject: true,
+			want:   &examplepb.ABitOfEverything{EnumValue: examplepb.NumericEnum_ONE},
+		},
+		{
+			name:  "unknown value kept",
+			input: `{"enum_value": 5}`,
+			want:  &examplepb.ABitOfEverything{EnumValue: examplepb.NumericEnum(5)},
+		},
+		{
+			name:    "unknown value",
+			input:   `{"enum_value": 5}`,
+			reject:  true,
+			wantErr: true,
+		},
+		{
+			name:    "unknown repeated value",
+			input:   `{"repeated_enum_value": [0, 7]}`,
+			reject:  true,
+			wantErr: true,
+		},
+		{
+			name:    "unknown nested value",
+			input:   `{"single_nested": {"ok": 3}}`,
+			reject:  true,
+			wantErr: true,
+		},
+		{
+			name:    "out of range value",
+			input:   `{"enum_value": 4294967297}`,
+			reject:  true,
+			wantErr: true,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			var m runtime.Marshaler = &runtime.JSONPb{}
+			if spec.reject {
+				m = runtime.NewJSONPb(runtime.WithRejectUnknownEnumValues())
+			}
+			var got examplepb.ABitOfEverything
+			err := m.Unmarshal([]byte(spec.input), &got)
+			if spec.wantErr {
+				if err == nil {
+					t.Errorf("m.Unmarshal(%q, &got) succeeded with %v; want an error", spec.input, &got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("m.Unmarshal(%q, &got) failed with %v; want success", spec.input, err)
+			}
+			if !proto.Equal(&got, spec.want) {
+				t.Errorf("got = %v; want %v", &got, spec.want)
+			}
+		})
+	}
+}
@@ -53,3 +53,20 @@ type Delimited interface {
 	// Delimiter returns the record seperator for the stream.
 	Delimiter() []byte
 }
+
+// LeadingDelimited is implemented by Delimited marshalers which write their delimiter before each
+// record of a stream rather than after it, e.g. the record separator 0x1E of JSON text sequences
+// (RFC 7464).
+type LeadingDelimited interface {
+	Delimited
+	// LeadingDelimiter returns true if the delimiter is written before each record.
+	LeadingDelimiter() bool
+}
+
+// Streamable is implemented by marshalers which can tell whether they can marshal the records of
+// a stream, e.g. not a marshaler of whole XML documents, whose records cannot be concatenated.
+// Marshalers which do not implement it are assumed to support streaming.
+type Streamable interface {
+	// StreamSupported returns false if the records of a stream cannot be marshaled.
+	StreamSupported() bool
+}
@@ -2,7 +2,10 @@ package runtime
 
 import (
 	"errors"
+	"mime"
 	"net/http"
+	"strings"
+	"sync"
 )
 
 // MIMEWildcard is the fallback MIME type used for requests which do not match
@@ -20,47 +23,154 @@ var (
 // It checks the registry on the ServeMux for the MIME type set by the Content-Type header.
 // If it isn't set (or the request Content-Type is empty), checks for "*".
 // If there are multiple Content-Type headers set, choose the first one that it can
-// exactly match in the registry.
+// exactly match in the registry. If the Accept headers are all blank, the outbound marshaler is
+// the one registered for "*", whatever the Content-Type.
 // Otherwise, it follows the above logic for "*"/InboundMarshaler/OutboundMarshaler.
+// If the request was routed to a handler with a marshaler set by WithRouteMarshaler, that
+// marshaler is returned as both inbound and outbound marshaler, whatever the headers.
 func MarshalerForRequest(mux *ServeMux, r *http.Request) (inbound Marshaler, outbound Marshaler) {
+	inbound, outbound, _ = NegotiateMarshalers(mux, r)
+	return inbound, outbound
+}
+
+// NegotiateMarshalers returns the inbound/outbound marshalers for this request, like
+// MarshalerForRequest. With WithStrictContentNegotiation, it also returns ErrUnsupportedMediaType
+// or ErrNotAcceptable if no marshaler is registered for the Content-Type or the Accept header of
+// the request, along with the marshalers registered for "*".
+func NegotiateMarshalers(mux *ServeMux, r *http.Request) (inbound Marshaler, outbound Marshaler, err error) {
+	if m, ok := r.Context().Value(routeMarshalerKey{}).(Marshaler); ok {
+		inbound, outbound = m, m
+	} else if mux.strictContentNegotiation {
+		inbound, outbound, err = mux.marshalers.negotiate(r)
+	} else {
+		inbound, outbound = mux.marshalers.forRequest(r)
+	}
+	if mux.textCharset != nil {
+		outbound = &charsetMarshaler{m: outbound, opts: mux.textCharset}
+	}
+	selected := inbound
+	if mux.strictDecoding != nil {
+		inbound = withRequestStrictness(inbound, r)
+	}
+	if len(mux.unmarshalFallbacks) > 0 {
+		inbound = mux.withUnmarshalFallback(selected, inbound)
+	}
+	if mux.descriptiveDecodeErrors {
+		inbound = withDecodeErrorContext(inbound, selected, r)
+	}
+	if mux.deprecatedFieldWarnings {
+		inbound = withDeprecatedFieldCheck(inbound, r)
+	}
+	if mux.decodeErrorObserver != nil {
+		inbound = &observedMarshaler{Marshaler: inbound, r: r, observe: mux.decodeErrorObserver}
+	}
+
+	return inbound, outbound, err
+}
+
+// marshalerRegistry is a mapping from MIME types to Marshalers.
+// It is safe for concurrent use.
+type marshalerRegistry struct {
+	mu      *sync.RWMutex
+	mimeMap map[string]Marshaler
+
+	matchSuffixes bool
+}
+
+// add adds a marshaler for a case-sensitive MIME type string ("*" to match any
+// MIME type).
+func (m marshalerRegistry) add(mime string, marshaler Marshaler) error {
+	if len(mime) == 0 {
+		return errors.New("empty MIME type")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mimeMap[mime] = marshaler
+
+	return nil
+}
+
+// forRequest returns the inbound/outbound marshalers registered for r,
+// as described in MarshalerForRequest. Both are looked up in the same state of the registry.
+func (m marshalerRegistry) forRequest(r *http.Request) (inbound Marshaler, outbound Marshaler) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	blankAccept := len(r.Header[acceptHeader]) > 0
 	for _, acceptVal := range r.Header[acceptHeader] {
-		if m, ok := mux.marshalers.mimeMap[acceptVal]; ok {
-			outbound = m
+		if strings.TrimSpace(acceptVal) == "" {
+			continue
+		}
+		blankAccept = false
+		if marshaler, ok := m.mimeMap[acceptVal]; ok {
+			outbound = marshaler
 			break
 		}
 	}
 
 	for _, contentTypeVal := range r.Header[contentTypeHeader] {
-		if m, ok := mux.marshalers.mimeMap[contentTypeVal]; ok {
-			inbound = m
+		if marshaler, ok := m.mimeMap[contentTypeVal]; ok {
+			inbound = marshaler
 			break
 		}
 	}
 
+	if m.matchSuffixes {
+		if outbound == nil {
+			outbound = m.bySuffix(r.Header[acceptHeader])
+		}
+		if inbound == nil {
+			inbound = m.bySuffix(r.Header[contentTypeHeader])
+		}
+	}
+
 	if inbound == nil {
-		inbound = mux.marshalers.mimeMap[MIMEWildcard]
+		inbound = m.mimeMap[MIMEWildcard]
+	}
+	if outbound == nil && blankAccept {
+		// A blank Accept header accepts any media type, so the default marshaler is used.
+		outbound = m.mimeMap[MIMEWildcard]
 	}
 	if outbound == nil {
 		outbound = inbound
 	}
-
 	return inbound, outbound
 }
 
-// marshalerRegistry is a mapping from MIME types to Marshalers.
-type marshalerRegistry struct {
-	mimeMap map[string]Marshaler
+// structuredSuffixTypes are the media types whose marshalers are used for the media types with
+// their structured syntax suffix (RFC 6839), such as "application/vnd.myco.v1+json", when
+// WithStructuredSuffixMatching is set.
+var structuredSuffixTypes = map[string][]string{
+	"json":  {"application/json"},
+	"proto": {"application/x-protobuf", "application/protobuf"},
+	"cbor":  {"application/cbor"},
 }
 
-// add adds a marshaler for a case-sensitive MIME type string ("*" to match any
-// MIME type).
-func (m marshalerRegistry) add(mime string, marshaler Marshaler) error {
-	if len(mime) == 0 {
-		return errors.New("empty MIME type")
+// suffixTypes returns the media types whose marshalers are used for mediaType, by its structured
+// syntax suffix, without its parameters.
+func suffixTypes(mediaType string) []string {
+	mediaType, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return nil
 	}
+	i := strings.LastIndex(mediaType, "+")
+	if i < 0 {
+		return nil
+	}
+	return structuredSuffixTypes[mediaType[i+1:]]
+}
 
-	m.mimeMap[mime] = marshaler
-
+// bySuffix returns the marshaler registered for the structured syntax suffix of the first of
+// mediaTypes which has one, or nil.
+func (m marshalerRegistry) bySuffix(mediaTypes []string) Marshaler {
+	for _, v := range mediaTypes {
+		for _, t := range suffixTypes(v) {
+			if marshaler, ok := m.mimeMap[t]; ok {
+				return marshaler
+			}
+		}
+	}
 	return nil
 }
 
@@ -74,6 +184,7 @@ func (m marshalerRegistry) add(mime string, marshaler Marshaler) error {
 // This can be attached to a ServerMux with the marshaler option.
 func makeMarshalerMIMERegistry() marshalerRegistry {
 	return marshalerRegistry{
+		mu: new(sync.RWMutex),
 		mimeMap: map[string]Marshaler{
 			MIMEWildcard: defaultMarshaler,
 		},
@@ -89,3 +200,44 @@ func WithMarshalerOption(mime string, marshaler Marshaler) ServeMuxOption {
 		}
 	}
 }
+
+type routeMarshalerKey struct{}
+
+// WithRouteMarshaler returns a ServeMuxOption which makes the requests routed to the handler
+// registered for the HTTP method meth and the pattern pat use marshaler m, for their request and
+// response bodies alike, instead of the marshalers chosen by the Accept and Content-Type headers.
+// Streaming responses are encoded with m too, with its delimiter if it implements Delimited.
+func WithRouteMarshaler(meth string, pat Pattern, m Marshaler) ServeMuxOption {
+	return func(mux *ServeMux) {
+		if mux.routeMarshalers == nil {
+			mux.routeMarshalers = make(map[string]Marshaler)
+		}
+		mux.routeMarshalers[routeKey(meth, pat)] = m
+	}
+}
+
+// routeKey identifies the handler registered for the HTTP method meth and the pattern pat.
+func routeKey(meth string, pat Pattern) string {
+	return meth + " " + pat.String()
+}
+
+// WithStructuredSuffixMatching returns a ServeMuxOption that makes MarshalerForRequest match the
+// media types without a registered marshaler by their structured syntax suffix (RFC 6839): the
+// types ending with "+json", such as "application/vnd.myco.v1+json", use the marshaler registered
+// for "application/json", the ones ending with "+proto" the marshaler registered for
+// "application/x-protobuf" or "application/protobuf", and the ones ending with "+cbor" the
+// marshaler registered for "application/cbor". Exact matches take precedence.
+func WithStructuredSuffixMatching() ServeMuxOption {
+	return func(mux *ServeMux) {
+		mux.marshalers.matchSuffixes = true
+	}
+}
+
+// RegisterMarshaler associates inbound and outbound Marshalers to a MIME type in s, like
+// WithMarshalerOption does when s is created. Unlike the option, it may be called while s serves
+// requests: the requests which are matched to marshalers once it returns use the new marshaler,
+// while the requests already being served keep the marshalers they got.
+// It returns an error if mime is empty.
+func (s *ServeMux) RegisterMarshaler(mime string, marshaler Marshaler) error {
+	return s.marshalers.add(mime, marshaler)
+}
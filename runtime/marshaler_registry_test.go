@@ -1,12 +1,18 @@
 package runtime_test
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
 )
 
 func TestMarshalerForRequest(t *testing.T) {
@@ -76,6 +82,265 @@ func TestMarshalerForRequest(t *testing.T) {
 	}
 }
 
+func TestMarshalerForRequestBlankAccept(t *testing.T) {
+	def, _ := runtime.MarshalerForRequest(runtime.NewServeMux(), httptest.NewRequest("GET", "http://example.com", nil))
+	protoMarshaler := &runtime.ProtoMarshaller{}
+	for _, spec := range []struct {
+		name        string
+		opts        []runtime.ServeMuxOption
+		contentType string
+		accept      string
+		wantOut     runtime.Marshaler
+	}{
+		{name: "empty", accept: "", wantOut: def},
+		{name: "spaces", accept: "  ", wantOut: def},
+		{
+			name:        "registered content type",
+			opts:        []runtime.ServeMuxOption{runtime.WithMarshalerOption("application/x-protobuf", protoMarshaler)},
+			contentType: "application/x-protobuf",
+			accept:      "",
+			wantOut:     def,
+		},
+		{
+			name:        "registered content type with strict content negotiation",
+			opts:        []runtime.ServeMuxOption{runtime.WithMarshalerOption("application/x-protobuf", protoMarshaler), runtime.WithStrictContentNegotiation()},
+			contentType: "application/x-protobuf",
+			accept:      " ",
+			wantOut:     def,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "http://example.com", nil)
+			if spec.contentType != "" {
+				r.Header.Set("Content-Type", spec.contentType)
+			}
+			r.Header.Set("Accept", spec.accept)
+			_, out := runtime.MarshalerForRequest(runtime.NewServeMux(spec.opts...), r)
+			if out != spec.wantOut {
+				t.Errorf("out = %#v; want %#v", out, spec.wantOut)
+			}
+		})
+	}
+}
+
+func TestMarshalerForRequestStructuredSuffix(t *testing.T) {
+	jsonMarshaler := &runtime.JSONPb{OrigName: true}
+	protoMarshaler := &runtime.ProtoMarshaller{}
+	def, _ := runtime.MarshalerForRequest(runtime.NewServeMux(), httptest.NewRequest("GET", "http://example.com", nil))
+	for _, spec := range []struct {
+		name        string
+		suffixes    bool
+		contentType string
+		accept      string
+
+		wantIn  runtime.Marshaler
+		wantOut runtime.Marshaler
+	}{
+		{
+			name:        "vendor json",
+			suffixes:    true,
+			contentType: "application/vnd.myco.v1+json",
+			wantIn:      jsonMarshaler,
+			wantOut:     jsonMarshaler,
+		},
+		{
+			name:        "vendor json with parameters",
+			suffixes:    true,
+			contentType: "application/vnd.myco.v1+json; charset=utf-8",
+			accept:      "application/vnd.myco.v1+proto",
+			wantIn:      jsonMarshaler,
+			wantOut:     protoMarshaler,
+		},
+		{
+			name:        "exact match first",
+			suffixes:    true,
+			contentType: "application/vnd.myco.exact+json",
+			wantIn:      protoMarshaler,
+			wantOut:     protoMarshaler,
+		},
+		{
+			name:        "unknown suffix",
+			suffixes:    true,
+			contentType: "application/vnd.myco.v1+xml",
+			wantIn:      def,
+			wantOut:     def,
+		},
+		{
+			name:        "suffixes not matched",
+			contentType: "application/vnd.myco.v1+json",
+			wantIn:      def,
+			wantOut:     def,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			opts := []runtime.ServeMuxOption{
+				runtime.WithMarshalerOption("application/json", jsonMarshaler),
+				runtime.WithMarshalerOption("application/x-protobuf", protoMarshaler),
+				runtime.WithMarshalerOption("application/vnd.myco.exact+json", protoMarshaler),
+			}
+			if spec.suffixes {
+				opts = append(opts, runtime.WithStructuredSuffixMatching())
+			}
+			mux := runtime.NewServeMux(opts...)
+
+			r := httptest.NewRequest("GET", "http://example.com", nil)
+			r.Header.Set("Content-Type", spec.contentType)
+			if spec.accept != "" {
+				r.Header.Set("Accept", spec.accept)
+			}
+			in, out := runtime.MarshalerForRequest(mux, r)
+			if in != spec.wantIn {
+				t.Errorf("in = %#v; want %#v", in, spec.wantIn)
+			}
+			if out != spec.wantOut {
+				t.Errorf("out = %#v; want %#v", out, spec.wantOut)
+			}
+		})
+	}
+}
+
+func TestServeMuxRegisterMarshaler(t *testing.T) {
+	mux := runtime.NewServeMux()
+	pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+	mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		_, outbound := runtime.MarshalerForRequest(mux, r)
+		ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+		runtime.ForwardResponseMessage(ctx, mux, outbound, w, r, &pb.SimpleMessage{Id: "foo"})
+	})
+	serve := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", "http://example.com/foo", nil)
+		r.Header.Set("Accept", "application/x-protobuf")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	if got, want := serve().Header().Get("Content-Type"), "application/json"; got != want {
+		t.Errorf("Content-Type = %q before registration; want %q", got, want)
+	}
+
+	// Requests keep being served while the marshaler is registered.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serve()
+		}()
+	}
+	if err := mux.RegisterMarshaler("application/x-protobuf", &runtime.ProtoMarshaller{}); err != nil {
+		t.Fatalf("mux.RegisterMarshaler failed with %v; want success", err)
+	}
+	wg.Wait()
+
+	w := serve()
+	if got, want := w.Header().Get("Content-Type"), "application/octet-stream"; got != want {
+		t.Errorf("Content-Type = %q after registration; want %q", got, want)
+	}
+	var msg pb.SimpleMessage
+	if err := proto.Unmarshal(w.Body.Bytes(), &msg); err != nil {
+		t.Fatalf("proto.Unmarshal(w.Body.Bytes(), &msg) failed with %v; want success", err)
+	}
+	if got, want := msg.Id, "foo"; got != want {
+		t.Errorf("msg.Id = %q; want %q", got, want)
+	}
+
+	if err := mux.RegisterMarshaler("", &runtime.ProtoMarshaller{}); err == nil {
+		t.Errorf("mux.RegisterMarshaler with an empty MIME type succeeded; want an error")
+	}
+}
+
+func TestWithRouteMarshaler(t *testing.T) {
+	upload := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"upload"}, ""))
+	export := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"export"}, ""))
+	other := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"other"}, ""))
+	pinned := &runtime.ProtoMarshaller{}
+	mux := runtime.NewServeMux(
+		runtime.WithRouteMarshaler("POST", upload, pinned),
+		runtime.WithRouteMarshaler("GET", export, pinned),
+	)
+
+	unary := func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		inbound, outbound := runtime.MarshalerForRequest(mux, r)
+		if r.Method == "POST" {
+			var msg pb.SimpleMessage
+			if err := inbound.NewDecoder(r.Body).Decode(&msg); err != nil {
+				t.Errorf("Decode failed with %v; want success", err)
+			}
+		}
+		ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+		runtime.ForwardResponseMessage(ctx, mux, outbound, w, r, &pb.SimpleMessage{Id: "foo"})
+	}
+	stream := func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		_, outbound := runtime.MarshalerForRequest(mux, r)
+		ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+		sent := false
+		runtime.ForwardResponseStream(ctx, mux, outbound, w, r, func() (proto.Message, error) {
+			if sent {
+				return nil, io.EOF
+			}
+			sent = true
+			return &pb.SimpleMessage{Id: "foo"}, nil
+		})
+	}
+	mux.Handle("POST", upload, unary)
+	mux.Handle("GET", upload, unary)
+	mux.Handle("GET", export, stream)
+	mux.Handle("POST", other, unary)
+
+	body, err := proto.Marshal(&pb.SimpleMessage{Id: "bar"})
+	if err != nil {
+		t.Fatalf("proto.Marshal failed with %v", err)
+	}
+	for _, spec := range []struct {
+		name   string
+		method string
+		path   string
+		body   []byte
+
+		wantContentType string
+	}{
+		{
+			name:            "pinned unary route",
+			method:          "POST",
+			path:            "/upload",
+			body:            body,
+			wantContentType: "application/octet-stream",
+		},
+		{
+			name:            "pinned streaming route",
+			method:          "GET",
+			path:            "/export",
+			wantContentType: "application/octet-stream",
+		},
+		{
+			name:            "other method of a pinned pattern",
+			method:          "GET",
+			path:            "/upload",
+			wantContentType: "application/json",
+		},
+		{
+			name:            "other route",
+			method:          "POST",
+			path:            "/other",
+			body:            []byte(`{"id": "bar"}`),
+			wantContentType: "application/json",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			r := httptest.NewRequest(spec.method, "http://example.com"+spec.path, bytes.NewReader(spec.body))
+			r.Header.Set("Accept", "application/json")
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got := w.Header().Get("Content-Type"); got != spec.wantContentType {
+				t.Errorf("Content-Type = %q; want %q", got, spec.wantContentType)
+			}
+		})
+	}
+}
+
 type dummyMarshaler struct{}
 
 func (dummyMarshaler) ContentType() string { return "" }
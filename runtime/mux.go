@@ -1,14 +1,20 @@
 package runtime
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/textproto"
+	"net/url"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/grpclog"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
@@ -38,6 +44,72 @@ type ServeMux struct {
 	protoErrorHandler         ProtoErrorHandlerFunc
 	disablePathLengthFallback bool
 	lastMatchWins             bool
+	maxHeaderCount            int
+	maxHeaderBytes            int
+	unknownVerbStatus         int
+	decodeErrorObserver       DecodeErrorObserverFunc
+	unexpectedBodyPolicy      UnexpectedBodyPolicy
+	unexpectedBodyMethods     []string
+	clock                     Clock
+	serverSentEvents          bool
+	sseEventID                SSEEventIDFunc
+	timingTrailers            bool
+	requestID                 *requestIDOptions
+	queryFields               *queryFieldFilter
+	builtinEndpoints          map[string]http.HandlerFunc
+	nilResponseBehavior       NilResponseBehavior
+	compressors               []responseCompressor
+	debugRoute                func(*http.Request) bool
+	unescapingMode            UnescapingMode
+	responseValidator         func(proto.Message) error
+	http10Streaming           HTTP10StreamingMode
+	streamErrorMarker         string
+	strictDecoding            *strictDecodingOptions
+	textCharset               *textCharsetOptions
+	defaultCacheControl       string
+	streamFirstByteTimeout    time.Duration
+	clientIP                  *clientIPOptions
+	errorBodyTemplate         *ErrorBodyTemplate
+	routeMarshalers           map[string]Marshaler
+	methodNotAllowedHandler   MethodNotAllowedHandlerFunc
+	pathLengthFallbacks       map[string][]string
+	disableMethodOverride     bool
+	operationLocation         string
+	trailingSlash             trailingSlashMode
+	authorizationHandler      AuthorizationHandlerFunc
+	httpStatusMapper          HTTPStatusMapperFunc
+	streamFlush               streamFlushMode
+	routingTracer             RoutingTracerFunc
+	errorLogger               ErrorLoggerFunc
+	maxRequestBodySize        int64
+	requestTimeoutHeader      string
+	rejectTrace               bool
+	headHandling              bool
+	maxStreamDuration         time.Duration
+	validateContentLength     bool
+	incomingHeaderMapper      HeaderMapperFunc
+	unmarshalFallbacks        []unmarshalFallback
+	strictContentNegotiation  bool
+	strictPercentDecoding     bool
+	optionsDiscovery          bool
+	maxTrailerSize            int
+	pathParamRewriter         PathParamRewriterFunc
+	serverHeader              *string
+	lengthPrefixedStreaming   bool
+	chaosLatency              ChaosLatencyFunc
+	descriptiveDecodeErrors   bool
+	paginationLinks           PaginationLinksFunc
+	grpcMessageHeader         bool
+	getRetry                  *getRetryOptions
+	traceIDExtractor          TraceIDExtractorFunc
+	maxQueryParams            int
+	pathPrefix                PathPrefixFunc
+	deprecatedFieldWarnings   bool
+	statusMetadataKey         string
+	errorMarshaler            Marshaler
+	dedupeResponseHeaders     bool
+	rejectUnexpectedBody      bool
+	sseKeepAlive              time.Duration
 }
 
 // ServeMuxOption is an option that can be given to a ServeMux on construction.
@@ -58,6 +130,11 @@ func WithForwardResponseOption(forwardResponseOption func(context.Context, http.
 // SetQueryParameterParser sets the query parameter parser, used to populate message from query parameters.
 // Configuring this will mean the generated swagger output is no longer correct, and it should be
 // done with careful consideration.
+//
+// A parser can handle some parameters by itself, e.g. comma-separated values of repeated fields,
+// and delegate the other ones to a DefaultQueryParser. Since the generated handlers, which call
+// PopulateQueryParameters, are not given the ServeMux, the parser is set for the whole process:
+// it is used by all ServeMuxes, whichever was created with the option.
 func SetQueryParameterParser(queryParameterParser QueryParameterParser) ServeMuxOption {
 	return func(serveMux *ServeMux) {
 		currentQueryParser = queryParameterParser
@@ -70,8 +147,13 @@ type HeaderMatcherFunc func(string) (string, bool)
 // DefaultHeaderMatcher is used to pass http request headers to/from gRPC context. This adds permanent HTTP header
 // keys (as specified by the IANA) to gRPC context with grpcgateway- prefix. HTTP headers that start with
 // 'Grpc-Metadata-' are mapped to gRPC metadata after removing prefix 'Grpc-Metadata-'.
+// Hop-by-hop headers (RFC 7230), such as Connection, Keep-Alive, Proxy-*, TE, Trailer,
+// Transfer-Encoding and Upgrade, are not passed.
 func DefaultHeaderMatcher(key string) (string, bool) {
 	key = textproto.CanonicalMIMEHeaderKey(key)
+	if isHopByHopHeader(key) {
+		return "", false
+	}
 	if isPermanentHTTPHeader(key) {
 		return MetadataPrefix + key, true
 	} else if strings.HasPrefix(key, MetadataHeaderPrefix) {
@@ -80,16 +162,46 @@ func DefaultHeaderMatcher(key string) (string, bool) {
 	return "", false
 }
 
+// PrefixHeaderMatcher returns a HeaderMatcherFunc which forwards the headers whose names start with
+// prefix, e.g. "X-Tenant-" for "X-Tenant-Id", under their names without the prefix, e.g. "Id".
+// Names are compared in their canonical form, so the case of prefix and of the headers does not matter.
+func PrefixHeaderMatcher(prefix string) HeaderMatcherFunc {
+	prefix = textproto.CanonicalMIMEHeaderKey(prefix)
+	return func(key string) (string, bool) {
+		key = textproto.CanonicalMIMEHeaderKey(key)
+		if len(key) > len(prefix) && strings.HasPrefix(key, prefix) {
+			return key[len(prefix):], true
+		}
+		return "", false
+	}
+}
+
 // WithIncomingHeaderMatcher returns a ServeMuxOption representing a headerMatcher for incoming request to gateway.
 //
-// This matcher will be called with each header in http.Request. If matcher returns true, that header will be
-// passed to gRPC context. To transform the header before passing to gRPC context, matcher should return modified header.
+// This matcher will be called with each header in http.Request, with the header name in its canonical
+// form, as returned by textproto.CanonicalMIMEHeaderKey, even if it was added to the request in another
+// form. If matcher returns true, that header will be passed to gRPC context. To transform the header before passing to gRPC context, matcher should return modified header.
 func WithIncomingHeaderMatcher(fn HeaderMatcherFunc) ServeMuxOption {
 	return func(mux *ServeMux) {
 		mux.incomingHeaderMatcher = fn
 	}
 }
 
+// HeaderMapperFunc returns the names under which an incoming header is passed to the incoming
+// header matcher, so that one header can be forwarded as several metadata entries.
+type HeaderMapperFunc func(string) []string
+
+// WithIncomingHeaderMapper returns a ServeMuxOption which maps each header in http.Request, with
+// its name in its canonical form, to the names under which it is passed to the incoming header
+// matcher, e.g. "Authorization" to "Authorization" and "Grpc-Metadata-User-Token". Each name is
+// then handled like the name of a header, e.g. "Grpc-Metadata-" is removed by DefaultHeaderMatcher.
+// A header mapped to no names is not forwarded. By default, headers keep their own names.
+func WithIncomingHeaderMapper(fn HeaderMapperFunc) ServeMuxOption {
+	return func(mux *ServeMux) {
+		mux.incomingHeaderMapper = fn
+	}
+}
+
 // WithOutgoingHeaderMatcher returns a ServeMuxOption representing a headerMatcher for outgoing response from gateway.
 //
 // This matcher will be called with each header in response header metadata. If matcher returns true, that header will be
@@ -129,6 +241,61 @@ func WithDisablePathLengthFallback() ServeMuxOption {
 	}
 }
 
+// WithDisableMethodOverride returns a ServeMuxOption that ignores the header
+// "X-HTTP-Method-Override" of the requests which fall back because of their path length.
+// They are then routed as if they had no such header.
+func WithDisableMethodOverride() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.disableMethodOverride = true
+	}
+}
+
+type trailingSlashMode int
+
+const (
+	trailingSlashExact trailingSlashMode = iota
+	trailingSlashRedirect
+	trailingSlashMatch
+)
+
+// WithTrailingSlashRedirect returns a ServeMuxOption that redirects the requests whose path
+// matches no pattern, but would match one with a trailing slash added or removed, to that path.
+// The redirect is permanent, with the status 301 for "GET" and "HEAD" requests and 308, which
+// keeps the method and the body, for the other ones. Paths which end with a verb, like
+// "/v1/jobs/1:cancel", do not get a trailing slash.
+func WithTrailingSlashRedirect() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.trailingSlash = trailingSlashRedirect
+	}
+}
+
+// WithTrailingSlashMatch returns a ServeMuxOption that routes the requests whose path matches no
+// pattern, but would match one with a trailing slash added or removed, as if they had that path.
+// Paths which end with a verb, like "/v1/jobs/1:cancel", do not get a trailing slash.
+func WithTrailingSlashMatch() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.trailingSlash = trailingSlashMatch
+	}
+}
+
+// WithPathLengthFallbackMethod returns a ServeMuxOption that makes the requests of the HTTP method
+// from with a form-encoded body, e.g. from clients which cannot send the method to, fall back to
+// the handlers registered for to when no handler of from matches their path. The parameters of
+// the request are then read from the form.
+//
+// By default, "POST" requests fall back to the handlers of any method, e.g. "GET" for requests
+// whose URL would be too long. The fallbacks set with this option are tried first, in the order
+// they are set. WithDisablePathLengthFallback disables them all.
+func WithPathLengthFallbackMethod(from, to string) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		if serveMux.pathLengthFallbacks == nil {
+			serveMux.pathLengthFallbacks = make(map[string][]string)
+		}
+		from, to = strings.ToUpper(from), strings.ToUpper(to)
+		serveMux.pathLengthFallbacks[from] = append(serveMux.pathLengthFallbacks[from], to)
+	}
+}
+
 // WithStreamErrorHandler returns a ServeMuxOption that will use the given custom stream
 // error handler, which allows for customizing the error trailer for server-streaming
 // calls.
@@ -152,6 +319,362 @@ func WithLastMatchWins() ServeMuxOption {
 	}
 }
 
+// WithMaxIncomingHeaders returns a ServeMuxOption that limits the number of HTTP header
+// fields and their total size in bytes, counting the key and the value of every field.
+// Requests exceeding either limit are rejected with a 431 status code before anything else is
+// done with them: their request ID is not echoed, the response is not compressed, and neither the
+// builtin endpoints such as the one of WithVersionEndpoint nor the handlers are served. The
+// ProtoErrorHandler, if one is configured, is given a
+// "ResourceExhausted" error to write the body.
+//
+// A non-positive value disables the corresponding limit.
+func WithMaxIncomingHeaders(count int, totalBytes int) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.maxHeaderCount = count
+		serveMux.maxHeaderBytes = totalBytes
+	}
+}
+
+// WithUnknownVerbStatus returns a ServeMuxOption that makes the mux report requests whose path
+// matches a registered path pattern except for its custom verb, like "/v1/jobs/123:" or
+// "/v1/jobs/123:unknown", with the given HTTP status code instead of treating them as unknown paths.
+// The code should be either http.StatusBadRequest or http.StatusNotFound.
+//
+// If a ProtoErrorHandler is configured, it is given an "InvalidArgument" error for
+// http.StatusBadRequest and a "NotFound" error otherwise.
+func WithUnknownVerbStatus(code int) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.unknownVerbStatus = code
+	}
+}
+
+// UnexpectedBodyPolicy tells the mux what to do with requests which have a body although
+// their HTTP method is not expected to have one.
+type UnexpectedBodyPolicy int
+
+const (
+	// IgnoreUnexpectedBody leaves the body to the handler, which does not read it
+	// unless the HTTP binding of the method has a body. This is the default.
+	IgnoreUnexpectedBody UnexpectedBodyPolicy = iota
+	// RejectUnexpectedBody rejects the request with an "InvalidArgument" error.
+	RejectUnexpectedBody
+)
+
+// WithUnexpectedBodyPolicy returns a ServeMuxOption that applies policy to requests
+// which match a registered pattern and carry a body, although their HTTP method is one
+// of methods. If no method is given, GET, HEAD and DELETE are assumed; leave out DELETE
+// if some DELETE bindings have a body. A body of unknown length, e.g. chunked, is only
+// rejected if it is not empty.
+func WithUnexpectedBodyPolicy(policy UnexpectedBodyPolicy, methods ...string) ServeMuxOption {
+	if len(methods) == 0 {
+		methods = []string{"GET", "HEAD", "DELETE"}
+	}
+	return func(serveMux *ServeMux) {
+		serveMux.unexpectedBodyPolicy = policy
+		serveMux.unexpectedBodyMethods = methods
+	}
+}
+
+// WithRejectUnexpectedBody returns a ServeMuxOption that rejects the requests which carry a body
+// with an "InvalidArgument" error, when they are routed to a handler registered with
+// WithNoBodyBinding, i.e. whose HTTP binding has no body and takes its fields from the path and
+// the query only, since such a body is likely a client bug. By default the body is ignored.
+//
+// Unlike WithUnexpectedBodyPolicy, which goes by the HTTP method of the request, it applies to
+// the bindings without body whatever their method. The generated handlers of those bindings are
+// registered with WithNoBodyBinding.
+func WithRejectUnexpectedBody() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.rejectUnexpectedBody = true
+	}
+}
+
+// WithNoBodyBinding returns a HandlerOption that declares that the HTTP binding of the handler
+// has no body, for WithRejectUnexpectedBody. The generator gives it to the handlers of such
+// bindings.
+func WithNoBodyBinding() HandlerOption {
+	return func(o *handlerOptions) {
+		o.noBodyBinding = true
+	}
+}
+
+// WithServerStreaming returns a HandlerOption that declares that the handler serves a
+// server-streaming method, for WithMaxStreamDuration. The generator gives it to the handlers of
+// such methods.
+func WithServerStreaming() HandlerOption {
+	return func(o *handlerOptions) {
+		o.serverStreaming = true
+	}
+}
+
+// WithTimingTrailers returns a ServeMuxOption that makes server-streaming responses end with
+// an "X-Response-Time-Ms" trailer, holding the number of milliseconds elapsed since the mux
+// received the request. The trailer is announced before the first write of the response.
+func WithTimingTrailers() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.timingTrailers = true
+	}
+}
+
+// UnescapingMode tells how the escaped characters of request paths are handled when matching
+// the paths against the registered patterns and capturing path parameters.
+type UnescapingMode int
+
+const (
+	// UnescapingModeLegacy unescapes the whole path before matching it, as net/http does
+	// for URL.Path. An escaped slash "%2F" is thus a separator of path segments, and
+	// "/v1/objects/a%2Fb" does not match "/v1/objects/{name}". This is the default.
+	UnescapingModeLegacy UnescapingMode = iota
+	// UnescapingModeAllExceptReserved matches the escaped path, so that "%2F" never separates
+	// segments. The values captured by single-segment wildcards like "{name}" are fully
+	// unescaped, and the values captured by multi-segment wildcards like "{name=**}" are
+	// unescaped except for the reserved characters of RFC 6570, such as "%2F".
+	UnescapingModeAllExceptReserved
+	// UnescapingModeAllExceptSlash is like UnescapingModeAllExceptReserved, except that only
+	// "%2F" is left escaped in the values captured by multi-segment wildcards.
+	UnescapingModeAllExceptSlash
+	// UnescapingModeAllCharacters is like UnescapingModeAllExceptReserved, except that the
+	// values captured by multi-segment wildcards are fully unescaped, too.
+	UnescapingModeAllCharacters
+)
+
+// WithUnescapingMode returns a ServeMuxOption that sets how escaped characters in request
+// paths are handled. See UnescapingMode.
+func WithUnescapingMode(mode UnescapingMode) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.unescapingMode = mode
+	}
+}
+
+// WithStrictPercentDecoding returns a ServeMuxOption that rejects the requests whose raw path
+// contains a malformed percent-encoding, such as "%ZZ", with "400 Bad Request" and an
+// "InvalidArgument" error, rather than matching the path re-escaped from the decoded one.
+func WithStrictPercentDecoding() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.strictPercentDecoding = true
+	}
+}
+
+// checkPercentEncoding returns an "InvalidArgument" error if the raw path of r contains a
+// malformed percent-encoding.
+func checkPercentEncoding(r *http.Request) error {
+	raw := r.URL.RawPath
+	if raw == "" {
+		raw = r.URL.EscapedPath()
+	}
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '%' {
+			continue
+		}
+		if i+2 >= len(raw) || !isHex(raw[i+1]) || !isHex(raw[i+2]) {
+			return status.Errorf(codes.InvalidArgument, "invalid URL escape in %q", raw)
+		}
+		i += 2
+	}
+	return nil
+}
+
+// PathParamRewriterFunc returns the value which populates the request field of the path parameter
+// name, captured as value, or an error if value is invalid.
+type PathParamRewriterFunc func(name, value string) (string, error)
+
+// WithPathParamRewriter returns a ServeMuxOption that calls fn with each path parameter captured
+// by the pattern of the matched handler, before the handler populates the request message, e.g. to
+// expand a short legacy ID into a full resource name. The request is replied with "400 Bad Request"
+// and an "InvalidArgument" error if fn returns an error.
+func WithPathParamRewriter(fn PathParamRewriterFunc) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.pathParamRewriter = fn
+	}
+}
+
+// rewritePathParams returns pathParams rewritten by the path parameter rewriter of s.
+func (s *ServeMux) rewritePathParams(pathParams map[string]string) (map[string]string, error) {
+	names := make([]string, 0, len(pathParams))
+	for name := range pathParams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rewritten := make(map[string]string, len(pathParams))
+	for _, name := range names {
+		value, err := s.pathParamRewriter(name, pathParams[name])
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid path parameter %q: %v", name, err)
+		}
+		rewritten[name] = value
+	}
+	return rewritten, nil
+}
+
+const (
+	// DebugRouteHeader is the request header asking for the MatchedRouteHeader in the response.
+	DebugRouteHeader = "X-Debug-Route"
+	// MatchedRouteHeader is the response header holding the pattern which matched the request.
+	MatchedRouteHeader = "X-Matched-Route"
+)
+
+// WithDebugRouteHeader returns a ServeMuxOption that sets the MatchedRouteHeader of responses to
+// the path pattern of the handler that served the request, as returned by Pattern.String.
+// The header is only set for requests with a DebugRouteHeader of "true" for which trusted returns
+// true, so that internal routes are not disclosed to any client.
+func WithDebugRouteHeader(trusted func(r *http.Request) bool) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.debugRoute = trusted
+	}
+}
+
+// NilResponseBehavior tells how a successful unary call returning a nil message is responded to.
+type NilResponseBehavior int
+
+const (
+	// NilResponseEmpty responds with an empty message of the response type, e.g. "{}" in JSON.
+	// This is the default.
+	NilResponseEmpty NilResponseBehavior = iota
+	// NilResponseNull responds with what the marshaler produces for nil, e.g. "null" in JSON.
+	NilResponseNull
+	// NilResponseError responds with an "Internal" error.
+	NilResponseError
+)
+
+// WithNilResponseBehavior returns a ServeMuxOption that sets how nil response messages are responded to.
+func WithNilResponseBehavior(behavior NilResponseBehavior) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.nilResponseBehavior = behavior
+	}
+}
+
+// MethodNotAllowedHandlerFunc responds to a request whose path matches registered patterns, but
+// of other methods than the one of the request. allowed lists these methods.
+type MethodNotAllowedHandlerFunc func(w http.ResponseWriter, r *http.Request, allowed []string)
+
+// WithMethodNotAllowedHandler returns a ServeMuxOption that responds with fn to the requests whose
+// path only matches patterns registered for other methods. The header Allow of the response is
+// already set when fn is called; fn is expected to respond with http.StatusMethodNotAllowed.
+//
+// Without it, such requests are reported like unknown paths, but with http.StatusMethodNotAllowed:
+// a ProtoErrorHandlerFunc set with WithProtoErrorHandler gets ErrUnknownURI, and otherwise
+// OtherErrorHandler is called with http.StatusMethodNotAllowed.
+func WithMethodNotAllowedHandler(fn MethodNotAllowedHandlerFunc) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.methodNotAllowedHandler = fn
+	}
+}
+
+// WithStreamErrorMarker returns a ServeMuxOption that adds the field key, set to true, to the
+// record which reports an error in a server-streaming response. With the key "final",
+// the record is like {"error": {...}, "final": true}, which no data record can be mistaken for,
+// whatever the fields of the streamed messages are.
+func WithStreamErrorMarker(key string) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.streamErrorMarker = key
+	}
+}
+
+// HTTP10StreamingMode tells how server-streaming responses are sent to HTTP/1.0 clients,
+// which may not support the chunked transfer encoding of streams.
+type HTTP10StreamingMode int
+
+const (
+	// HTTP10StreamingDefault streams the response as for other clients. This is the default.
+	HTTP10StreamingDefault HTTP10StreamingMode = iota
+	// HTTP10StreamingBuffer sends the whole stream at once, with a Content-Length, once the
+	// stream has ended. The messages are thus held in memory until the end of the stream.
+	HTTP10StreamingBuffer
+	// HTTP10StreamingReject responds with an "Unimplemented" error without calling the stream.
+	HTTP10StreamingReject
+)
+
+// WithHTTP10StreamingMode returns a ServeMuxOption that sets how server-streaming responses are
+// sent to HTTP/1.0 clients.
+func WithHTTP10StreamingMode(mode HTTP10StreamingMode) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.http10Streaming = mode
+	}
+}
+
+// WithStreamFirstByteTimeout returns a ServeMuxOption that aborts a server-streaming response
+// with a "DeadlineExceeded" error if its first message is not received within timeout.
+// Since nothing has been written yet, the client gets the HTTP status of the error.
+// The timeout does not apply once the first message is received.
+func WithStreamFirstByteTimeout(timeout time.Duration) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.streamFirstByteTimeout = timeout
+	}
+}
+
+// WithMaxStreamDuration returns a ServeMuxOption that terminates server-streaming responses with
+// a "DeadlineExceeded" error once they have lasted for d, however active they are, so that
+// runaway streams are stopped. The handlers registered with WithServerStreaming get a request
+// context with a deadline d after the request is routed, which bounds the call to the backend,
+// and the context is canceled when the handler returns. The generated handlers of
+// server-streaming methods are registered with WithServerStreaming.
+func WithMaxStreamDuration(d time.Duration) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.maxStreamDuration = d
+	}
+}
+
+// WithLengthPrefixedStreaming returns a ServeMuxOption that frames each record of
+// server-streaming responses, errors included, as its length in bytes in decimal, a newline, the
+// record and another newline, e.g. "13\n{\"result\":{}}\n", so that clients can read exact frames
+// without scanning for delimiters. The delimiter of the marshaler is not used then. Server-sent
+// events are not affected.
+func WithLengthPrefixedStreaming() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.lengthPrefixedStreaming = true
+	}
+}
+
+// streamFlushMode is when server-streaming responses are flushed.
+type streamFlushMode int
+
+const (
+	// streamFlushDefault flushes after each message and requires an http.Flusher.
+	streamFlushDefault streamFlushMode = iota
+	streamFlushEach
+	streamFlushEnd
+)
+
+// WithStreamFlush returns a ServeMuxOption that controls the flushing of server-streaming
+// responses. If flush is true, each message is flushed once it has been written with its
+// delimiter, so that it reaches the client immediately. If flush is false, the response is only
+// flushed at the end of the stream and is otherwise buffered by the server.
+// In both cases, the response is also streamed by http.ResponseWriters which are not
+// http.Flushers, while they are rejected with 500 by default.
+func WithStreamFlush(flush bool) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		if flush {
+			serveMux.streamFlush = streamFlushEach
+		} else {
+			serveMux.streamFlush = streamFlushEnd
+		}
+	}
+}
+
+// WithRequestTimeoutHeader returns a ServeMuxOption that sets the deadline of the gRPC calls
+// from the Go duration, like "2s", in the request header name, e.g. "X-Request-Timeout".
+// The deadline is sent to the backend in the grpc-timeout metadata. A malformed duration is
+// rejected with "400 Bad Request". The header never extends a shorter deadline, e.g. given by
+// the Grpc-Timeout header or by the context of the request.
+func WithRequestTimeoutHeader(name string) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.requestTimeoutHeader = textproto.CanonicalMIMEHeaderKey(name)
+	}
+}
+
+// WithResponseValidator returns a ServeMuxOption that calls validate with each unary response
+// message, after the forward response options and before the message is marshaled. If validate
+// returns an error, the client gets an "Internal" error instead of the response.
+//
+// It is meant for contract testing, e.g. to detect fields which must not be leaked to clients.
+// Leave it unset where the cost of the validation is not wanted.
+func WithResponseValidator(validate func(proto.Message) error) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.responseValidator = validate
+	}
+}
+
 // NewServeMux returns a new ServeMux whose internal mapping is empty.
 func NewServeMux(opts ...ServeMuxOption) *ServeMux {
 	serveMux := &ServeMux{
@@ -159,6 +682,7 @@ func NewServeMux(opts ...ServeMuxOption) *ServeMux {
 		forwardResponseOptions: make([]func(context.Context, http.ResponseWriter, proto.Message) error, 0),
 		marshalers:             makeMarshalerMIMERegistry(),
 		streamErrorHandler:     DefaultHTTPStreamErrorHandler,
+		clock:                  wallClock{},
 	}
 
 	for _, opt := range opts {
@@ -179,110 +703,540 @@ func NewServeMux(opts ...ServeMuxOption) *ServeMux {
 }
 
 // Handle associates "h" to the pair of HTTP method and path pattern.
-func (s *ServeMux) Handle(meth string, pat Pattern, h HandlerFunc) {
+// "opts" configure how the responses of "h" are handled, e.g. WithRouteCacheControl.
+func (s *ServeMux) Handle(meth string, pat Pattern, h HandlerFunc, opts ...HandlerOption) {
 	if s.lastMatchWins {
-		s.handlers[meth] = append([]handler{handler{pat: pat, h: h}}, s.handlers[meth]...)
+		s.handlers[meth] = append([]handler{s.newHandler(meth, pat, h, opts)}, s.handlers[meth]...)
 	} else {
-		s.handlers[meth] = append(s.handlers[meth], handler{pat: pat, h: h})
+		s.handlers[meth] = append(s.handlers[meth], s.newHandler(meth, pat, h, opts))
+	}
+}
+
+// Registration is a handler to register with ServeMux.HandleBulk.
+type Registration struct {
+	// Method is the HTTP method the handler serves.
+	Method string
+	// Pattern is the path pattern the handler serves.
+	Pattern Pattern
+	// Handler handles the requests matching Method and Pattern.
+	Handler HandlerFunc
+	// Options are the options of the handler.
+	Options []HandlerOption
+}
+
+// HandleBulk registers all of regs, as if Handle was called for each of them in order.
+// The registrations are validated together first: if one has no method or handler, or if two
+// have the same method and pattern, HandleBulk returns an error and registers none of them.
+func (s *ServeMux) HandleBulk(regs []Registration) error {
+	counts := make(map[string]int)
+	seen := make(map[string]bool, len(regs))
+	for i, reg := range regs {
+		if reg.Method == "" {
+			return fmt.Errorf("registration %d for %s has no method", i, reg.Pattern)
+		}
+		if reg.Handler == nil {
+			return fmt.Errorf("registration %d for %s %s has no handler", i, reg.Method, reg.Pattern)
+		}
+		key := routeKey(reg.Method, reg.Pattern)
+		if seen[key] {
+			return fmt.Errorf("duplicate registration for %s", key)
+		}
+		seen[key] = true
+		counts[reg.Method]++
+	}
+
+	added := make(map[string][]handler, len(counts))
+	for meth, n := range counts {
+		added[meth] = make([]handler, 0, n+len(s.handlers[meth]))
+	}
+	for _, reg := range regs {
+		added[reg.Method] = append(added[reg.Method], s.newHandler(reg.Method, reg.Pattern, reg.Handler, reg.Options))
+	}
+	for meth, hs := range added {
+		if s.lastMatchWins {
+			for i, j := 0, len(hs)-1; i < j; i, j = i+1, j-1 {
+				hs[i], hs[j] = hs[j], hs[i]
+			}
+			s.handlers[meth] = append(hs, s.handlers[meth]...)
+		} else {
+			s.handlers[meth] = append(s.handlers[meth], hs...)
+		}
+	}
+	return nil
+}
+
+// HandleAll registers h for meth and each of pats, e.g. both "/v1/items/{id}" and "/items/{id}",
+// as if Handle was called for each pattern in order, also with WithLastMatchWins. Like
+// HandleBulk, it returns an error and registers none of them if a pattern is given twice.
+// HTTPPathPattern gives the individual pattern that a request matched.
+func (s *ServeMux) HandleAll(meth string, pats []Pattern, h HandlerFunc, opts ...HandlerOption) error {
+	regs := make([]Registration, 0, len(pats))
+	for _, pat := range pats {
+		regs = append(regs, Registration{Method: meth, Pattern: pat, Handler: h, Options: opts})
 	}
+	return s.HandleBulk(regs)
+}
+
+// HandleVerb associates "h" to the pair of HTTP method and the path pattern "pat" with the
+// custom verb "verb", e.g. "cancel" for "/v1/jobs/{id}:cancel". Any verb already set in
+// "pat" is replaced, so that several verbs can be registered with the same base pattern.
+func (s *ServeMux) HandleVerb(meth string, pat Pattern, verb string, h HandlerFunc, opts ...HandlerOption) {
+	pat.verb = verb
+	s.Handle(meth, pat, h, opts...)
 }
 
 // ServeHTTP dispatches the request to the first handler whose pattern matches to r.Method and r.Path.
 func (s *ServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	if s.serverHeader != nil {
+		w = &serverHeaderResponseWriter{ResponseWriter: w, value: *s.serverHeader}
+	}
+	if err := s.checkIncomingHeaders(r); err != nil {
+		// A ProtoErrorHandler would reply to "ResourceExhausted" with 429.
+		tooLarge := &statusResponseWriter{ResponseWriter: w, code: http.StatusRequestHeaderFieldsTooLarge}
+		s.routingError(tooLarge, r, err, status.Convert(err).Message(), http.StatusRequestHeaderFieldsTooLarge)
+		return
+	}
+	if s.timingTrailers {
+		r = r.WithContext(context.WithValue(r.Context(), requestStartKey{}, s.clock.Now()))
+	}
+	if s.requestID != nil {
+		r = s.requestID.withRequestID(w, r)
+	}
+	if c := s.responseCompressor(r); c != nil {
+		cw := newCompressResponseWriter(w, c)
+		defer func() {
+			if err := cw.close(); err != nil {
+				grpclog.Infof("Failed to compress response: %v", err)
+			}
+		}()
+		w = cw
+	}
 
+	if s.strictPercentDecoding {
+		if err := checkPercentEncoding(r); err != nil {
+			s.routingError(w, r, err, status.Convert(err).Message(), http.StatusBadRequest)
+			return
+		}
+	}
+	if s.pathPrefix != nil {
+		stripped, ok := s.stripPathPrefix(r)
+		if !ok {
+			s.routingError(w, r, ErrUnknownURI, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		r = stripped
+	}
 	path := r.URL.Path
+	if s.unescapingMode != UnescapingModeLegacy {
+		path = r.URL.EscapedPath()
+	}
 	if !strings.HasPrefix(path, "/") {
-		if s.protoErrorHandler != nil {
-			_, outboundMarshaler := MarshalerForRequest(s, r)
-			sterr := status.Error(codes.InvalidArgument, http.StatusText(http.StatusBadRequest))
-			s.protoErrorHandler(ctx, s, outboundMarshaler, w, r, sterr)
-		} else {
-			OtherErrorHandler(w, r, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
-		}
+		sterr := status.Error(codes.InvalidArgument, http.StatusText(http.StatusBadRequest))
+		s.routingError(w, r, sterr, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 
-	components := strings.Split(path[1:], "/")
-	l := len(components)
-	var verb string
-	if idx := strings.LastIndex(components[l-1], ":"); idx == 0 {
-		if s.protoErrorHandler != nil {
-			_, outboundMarshaler := MarshalerForRequest(s, r)
-			s.protoErrorHandler(ctx, s, outboundMarshaler, w, r, ErrUnknownURI)
-		} else {
-			OtherErrorHandler(w, r, http.StatusText(http.StatusNotFound), http.StatusNotFound)
-		}
+	if h, ok := s.builtinEndpoints[path]; ok && r.Method == "GET" {
+		h(w, r)
 		return
-	} else if idx > 0 {
-		c := components[l-1]
-		components[l-1], verb = c[:idx], c[idx+1:]
 	}
+	r = s.checkContentLength(r)
+	r = s.limitRequestBody(r)
+
+	s.serveRoute(w, r, path, false)
+}
 
-	if override := r.Header.Get("X-HTTP-Method-Override"); override != "" && s.isPathLengthFallback(r) {
+// serveRoute dispatches r, whose path is path, to the matching handler. retried is true if path
+// is the other form, with or without a trailing slash, of the path of the request.
+func (s *ServeMux) serveRoute(w http.ResponseWriter, r *http.Request, path string, retried bool) {
+	components, verb, hasVerb, ok := splitPath(path)
+	if !ok {
+		s.traceRouting(r, nil, "")
+		s.routingError(w, r, ErrUnknownURI, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	if hasVerb && verb == "" && s.unknownVerbStatus != 0 && s.matchesIgnoringVerb(components) {
+		s.traceRouting(r, components, verb)
+		s.unknownVerbError(w, r, verb)
+		return
+	}
+
+	if override := r.Header.Get("X-HTTP-Method-Override"); override != "" && !s.disableMethodOverride && s.isPathLengthFallback(r) {
 		r.Method = strings.ToUpper(override)
-		if err := r.ParseForm(); err != nil {
-			if s.protoErrorHandler != nil {
-				_, outboundMarshaler := MarshalerForRequest(s, r)
-				sterr := status.Error(codes.InvalidArgument, err.Error())
-				s.protoErrorHandler(ctx, s, outboundMarshaler, w, r, sterr)
-			} else {
-				OtherErrorHandler(w, r, err.Error(), http.StatusBadRequest)
-			}
+		if !s.parseForm(w, r) {
 			return
 		}
 	}
 	for _, h := range s.handlers[r.Method] {
-		pathParams, err := h.pat.Match(components, verb)
+		pathParams, err := h.pat.match(components, verb, s.unescapingMode)
 		if err != nil {
 			continue
 		}
-		h.h(w, r, pathParams)
+		if s.hasUnexpectedBody(r, h) {
+			msg := fmt.Sprintf("unexpected request body for method %s", r.Method)
+			s.routingError(w, r, status.Error(codes.InvalidArgument, msg), msg, http.StatusBadRequest)
+			return
+		}
+		s.serveMatched(w, r, h, pathParams)
+		return
+	}
+
+	if r.Method == "TRACE" && s.rejectTrace {
+		s.rejectTraceRequest(w, components, verb)
 		return
 	}
 
+	if r.Method == "HEAD" && s.headHandling {
+		for _, h := range s.handlers["GET"] {
+			pathParams, err := h.pat.match(components, verb, s.unescapingMode)
+			if err != nil {
+				continue
+			}
+			s.serveMatched(&headResponseWriter{ResponseWriter: w}, r, h, pathParams)
+			return
+		}
+	}
+
+	if r.Method == "OPTIONS" && s.optionsDiscovery && r.Header.Get("Access-Control-Request-Method") == "" {
+		if s.serveOptionsDiscovery(w, components, verb) {
+			return
+		}
+	}
+
+	if s.isPathLengthFallback(r) {
+		for _, m := range s.pathLengthFallbacks[r.Method] {
+			for _, h := range s.handlers[m] {
+				pathParams, err := h.pat.match(components, verb, s.unescapingMode)
+				if err != nil {
+					continue
+				}
+				if !s.parseForm(w, r) {
+					return
+				}
+				s.serveMatched(w, r, h, pathParams)
+				return
+			}
+		}
+	}
+
 	// lookup other methods to handle fallback from GET to POST and
 	// to determine if it is MethodNotAllowed or NotFound.
+	var allowed []string
 	for m, handlers := range s.handlers {
 		if m == r.Method {
 			continue
 		}
 		for _, h := range handlers {
-			pathParams, err := h.pat.Match(components, verb)
+			pathParams, err := h.pat.match(components, verb, s.unescapingMode)
 			if err != nil {
 				continue
 			}
 			// X-HTTP-Method-Override is optional. Always allow fallback to POST.
-			if s.isPathLengthFallback(r) {
-				if err := r.ParseForm(); err != nil {
-					if s.protoErrorHandler != nil {
-						_, outboundMarshaler := MarshalerForRequest(s, r)
-						sterr := status.Error(codes.InvalidArgument, err.Error())
-						s.protoErrorHandler(ctx, s, outboundMarshaler, w, r, sterr)
-					} else {
-						OtherErrorHandler(w, r, err.Error(), http.StatusBadRequest)
-					}
+			if s.isPathLengthFallback(r) && r.Method == "POST" {
+				if !s.parseForm(w, r) {
 					return
 				}
-				h.h(w, r, pathParams)
+				s.serveMatched(w, r, h, pathParams)
 				return
 			}
-			if s.protoErrorHandler != nil {
-				_, outboundMarshaler := MarshalerForRequest(s, r)
-				s.protoErrorHandler(ctx, s, outboundMarshaler, w, r, ErrUnknownURI)
-			} else {
-				OtherErrorHandler(w, r, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			allowed = append(allowed, m)
+			break
+		}
+	}
+	if len(allowed) > 0 {
+		s.traceRouting(r, components, verb)
+		s.methodNotAllowed(w, r, allowed)
+		return
+	}
+
+	if hasVerb && s.unknownVerbStatus != 0 && s.matchesIgnoringVerb(components) {
+		s.traceRouting(r, components, verb)
+		s.unknownVerbError(w, r, verb)
+		return
+	}
+
+	if s.trailingSlash != trailingSlashExact && !retried {
+		if alt, ok := s.trailingSlashAlternative(path); ok {
+			s.serveTrailingSlashAlternative(w, r, alt)
+			return
+		}
+	}
+
+	s.traceRouting(r, components, verb)
+	s.routingError(w, r, ErrUnknownURI, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+}
+
+// trailingSlashAlternative returns path with its trailing slash removed, or with a trailing slash
+// added, if it matches the pattern of a handler of any method.
+func (s *ServeMux) trailingSlashAlternative(path string) (string, bool) {
+	var alt string
+	switch {
+	case path == "/":
+		return "", false
+	case strings.HasSuffix(path, "/"):
+		alt = path[:len(path)-1]
+	case strings.Contains(path[strings.LastIndex(path, "/"):], ":"):
+		// Keep verbs at the end of paths.
+		return "", false
+	default:
+		alt = path + "/"
+	}
+
+	components, verb, _, ok := splitPath(alt)
+	if !ok {
+		return "", false
+	}
+	for _, handlers := range s.handlers {
+		for _, h := range handlers {
+			if _, err := h.pat.match(components, verb, s.unescapingMode); err == nil {
+				return alt, true
 			}
+		}
+	}
+	return "", false
+}
+
+// serveTrailingSlashAlternative redirects or routes r to alt, the other form of its path.
+func (s *ServeMux) serveTrailingSlashAlternative(w http.ResponseWriter, r *http.Request, alt string) {
+	u := *r.URL
+	if s.unescapingMode == UnescapingModeLegacy {
+		u.Path, u.RawPath = alt, ""
+	} else {
+		unescaped, err := url.PathUnescape(alt)
+		if err != nil {
+			s.routingError(w, r, ErrUnknownURI, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		u.Path, u.RawPath = unescaped, alt
+	}
+
+	if s.trailingSlash == trailingSlashRedirect {
+		code := http.StatusPermanentRedirect
+		if r.Method == "GET" || r.Method == "HEAD" {
+			code = http.StatusMovedPermanently
+		}
+		// The location is relative, so that it is right behind proxies too.
+		u.Scheme, u.Host, u.User = "", "", nil
+		http.Redirect(w, r, u.String(), code)
+		return
+	}
+	r = r.WithContext(r.Context())
+	r.URL = &u
+	s.serveRoute(w, r, alt, true)
+}
+
+// splitPath splits path into its components and its verb. It returns false if the verb
+// has no path segment before it.
+func splitPath(path string) (components []string, verb string, hasVerb bool, ok bool) {
+	components = strings.Split(path[1:], "/")
+	l := len(components)
+	if idx := strings.LastIndex(components[l-1], ":"); idx == 0 {
+		return nil, "", false, false
+	} else if idx > 0 {
+		c := components[l-1]
+		components[l-1], verb = c[:idx], c[idx+1:]
+		hasVerb = true
+	}
+	return components, verb, hasVerb, true
+}
+
+// serveMatched calls the handler h, whose pattern matches r.
+func (s *ServeMux) serveMatched(w http.ResponseWriter, r *http.Request, h handler, pathParams map[string]string) {
+	if s.pathParamRewriter != nil {
+		rewritten, err := s.rewritePathParams(pathParams)
+		if err != nil {
+			s.routingError(w, r, err, status.Convert(err).Message(), http.StatusBadRequest)
+			return
+		}
+		pathParams = rewritten
+	}
+	r = withMatchedRoute(r, h, pathParams)
+	limitRouteBody(r, h)
+	if s.deprecatedFieldWarnings {
+		w, r = withDeprecatedFieldWarnings(w, r)
+	}
+	if s.strictDecoding != nil {
+		r = s.strictDecoding.apply(r)
+	}
+	if err := s.checkQueryParams(r); err != nil {
+		s.routingError(w, r, err, status.Convert(err).Message(), http.StatusBadRequest)
+		return
+	}
+	if s.queryFields != nil {
+		filtered, err := s.queryFields.filterRequest(r)
+		if err != nil {
+			s.routingError(w, r, err, status.Convert(err).Message(), http.StatusBadRequest)
 			return
 		}
+		r = filtered
+	}
+	if s.debugRoute != nil && r.Header.Get(DebugRouteHeader) == "true" && s.debugRoute(r) {
+		w.Header().Set(MatchedRouteHeader, h.pat.String())
+	}
+	for _, key := range h.opts.requiredHeaders {
+		if r.Header.Get(key) == "" {
+			msg := fmt.Sprintf("missing required header %q", key)
+			s.routingError(w, r, status.Error(codes.InvalidArgument, msg), msg, http.StatusBadRequest)
+			return
+		}
+	}
+	if h.marshaler != nil {
+		r = r.WithContext(context.WithValue(r.Context(), routeMarshalerKey{}, h.marshaler))
+	} else if s.strictContentNegotiation {
+		if _, outbound, err := NegotiateMarshalers(s, r); err != nil {
+			s.negotiationError(w, r, outbound, err)
+			return
+		}
+	}
+	if s.chaosLatency != nil {
+		if err := s.injectLatency(r); err != nil {
+			s.routingError(w, r, err, status.Convert(err).Message(), http.StatusRequestTimeout)
+			return
+		}
+	}
+	if h.opts.serverStreaming && s.maxStreamDuration > 0 {
+		ctx, cancel := withTimeout(r.Context(), s.clock, s.maxStreamDuration)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+	if value := s.cacheControl(h); value != "" {
+		w = &cacheControlResponseWriter{ResponseWriter: w, value: value}
+	}
+	if s.getRetry != nil && s.getRetry.retryable(r) {
+		s.serveWithRetry(w, r, h.h, pathParams)
+		return
+	}
+	h.h(w, r, pathParams)
+}
+
+// methodNotAllowed reports a request whose path matches patterns registered for the methods
+// allowed only. The methods are listed in the header Allow of the response.
+func (s *ServeMux) methodNotAllowed(w http.ResponseWriter, r *http.Request, allowed []string) {
+	sort.Strings(allowed)
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	if s.methodNotAllowedHandler != nil {
+		s.methodNotAllowedHandler(w, r, allowed)
+		return
+	}
+	s.routingError(w, r, ErrUnknownURI, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+// WithRejectTRACE returns a ServeMuxOption that rejects "TRACE" requests with
+// "405 Method Not Allowed" and an empty body, unless a handler is registered for "TRACE" and
+// their path, since echoing requests is a security risk. It is recommended, but not the default
+// for compatibility.
+func WithRejectTRACE() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.rejectTrace = true
+	}
+}
+
+// WithHEADHandling returns a ServeMuxOption that replies to "HEAD" requests with the handler
+// registered for "GET" and their path, if there is no handler for "HEAD". The response has the
+// status and the headers, e.g. Content-Length, set by the handler, but no body.
+func WithHEADHandling() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.headHandling = true
+	}
+}
+
+// headResponseWriter discards the body of the response to a "HEAD" request.
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+// Flush sends the headers to the client.
+func (w *headResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// rejectTraceRequest replies to a "TRACE" request, whose path has the given components and verb,
+// with "405 Method Not Allowed" and the methods registered for the path in the header Allow.
+func (s *ServeMux) rejectTraceRequest(w http.ResponseWriter, components []string, verb string) {
+	var allowed []string
+	for m, handlers := range s.handlers {
+		for _, h := range handlers {
+			if _, err := h.pat.match(components, verb, s.unescapingMode); err == nil {
+				allowed = append(allowed, m)
+				break
+			}
+		}
 	}
+	if len(allowed) > 0 {
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+	}
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
+
+// matchesIgnoringVerb returns true if the path components match the pattern of
+// any registered handler, regardless of the verb of the pattern.
+// Patterns without a verb which do not assume a colon verb are skipped, since
+// a colon in the last segment is no verb syntax for them.
+func (s *ServeMux) matchesIgnoringVerb(components []string) bool {
+	for _, handlers := range s.handlers {
+		for _, h := range handlers {
+			if h.pat.matchIgnoringVerb(components, s.unescapingMode) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unknownVerbError reports a request whose path only differs from a registered
+// pattern by its verb, as configured with WithUnknownVerbStatus.
+func (s *ServeMux) unknownVerbError(w http.ResponseWriter, r *http.Request, verb string) {
+	msg := fmt.Sprintf("unknown verb %q", verb)
+	if verb == "" {
+		msg = "empty verb"
+	}
+	code := codes.NotFound
+	if s.unknownVerbStatus == http.StatusBadRequest {
+		code = codes.InvalidArgument
+	}
+	s.routingError(w, r, status.Error(code, msg), msg, s.unknownVerbStatus)
+}
 
+// routingError reports err, which was found while routing r to a handler.
+// It is given to the ProtoErrorHandler if one is configured. Otherwise msg and code
+// are given to OtherErrorHandler.
+func (s *ServeMux) routingError(w http.ResponseWriter, r *http.Request, err error, msg string, code int) {
 	if s.protoErrorHandler != nil {
 		_, outboundMarshaler := MarshalerForRequest(s, r)
-		s.protoErrorHandler(ctx, s, outboundMarshaler, w, r, ErrUnknownURI)
-	} else {
-		OtherErrorHandler(w, r, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		s.protoErrorHandler(r.Context(), s, s.errorMarshalerFor(outboundMarshaler), w, r, err)
+		return
 	}
+	OtherErrorHandler(w, r, msg, code)
+}
+
+// checkIncomingHeaders returns a "ResourceExhausted" error if the headers of r
+// exceed the limits configured with WithMaxIncomingHeaders.
+func (s *ServeMux) checkIncomingHeaders(r *http.Request) error {
+	if s.maxHeaderCount <= 0 && s.maxHeaderBytes <= 0 {
+		return nil
+	}
+	var count, size int
+	for key, vals := range r.Header {
+		for _, val := range vals {
+			count++
+			size += len(key) + len(val)
+		}
+	}
+	if s.maxHeaderCount > 0 && count > s.maxHeaderCount {
+		return status.Errorf(codes.ResourceExhausted, "too many header fields: %d exceeds the limit of %d", count, s.maxHeaderCount)
+	}
+	if s.maxHeaderBytes > 0 && size > s.maxHeaderBytes {
+		return status.Errorf(codes.ResourceExhausted, "header fields too large: %d bytes exceeds the limit of %d", size, s.maxHeaderBytes)
+	}
+	return nil
 }
 
 // GetForwardResponseOptions returns the ForwardResponseOptions associated with this ServeMux.
@@ -290,11 +1244,89 @@ func (s *ServeMux) GetForwardResponseOptions() []func(context.Context, http.Resp
 	return s.forwardResponseOptions
 }
 
+// hasUnexpectedBody returns true if r, routed to h, must be rejected according to the
+// UnexpectedBodyPolicy of the mux, or to WithRejectUnexpectedBody.
+func (s *ServeMux) hasUnexpectedBody(r *http.Request, h handler) bool {
+	rejectBinding := s.rejectUnexpectedBody && h.opts.noBodyBinding
+	if s.unexpectedBodyPolicy != RejectUnexpectedBody && !rejectBinding {
+		return false
+	}
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+		return false
+	}
+	if !rejectBinding && !s.isUnexpectedBodyMethod(r.Method) {
+		return false
+	}
+	return r.ContentLength > 0 || peekBody(r)
+}
+
+func (s *ServeMux) isUnexpectedBodyMethod(method string) bool {
+	for _, m := range s.unexpectedBodyMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// peekBody returns true if the body of r, whose length is unknown, e.g. a chunked body, is not
+// empty. The byte it reads is put back into r.Body.
+func peekBody(r *http.Request) bool {
+	br := bufio.NewReader(r.Body)
+	_, err := br.Peek(1)
+	r.Body = &peekedBody{Reader: br, Closer: r.Body}
+	return err == nil
+}
+
+// peekedBody is a request body of which some bytes were buffered by the reader.
+type peekedBody struct {
+	io.Reader
+	io.Closer
+}
+
 func (s *ServeMux) isPathLengthFallback(r *http.Request) bool {
-	return !s.disablePathLengthFallback && r.Method == "POST" && r.Header.Get("Content-Type") == "application/x-www-form-urlencoded"
+	if s.disablePathLengthFallback || r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+		return false
+	}
+	return r.Method == "POST" || len(s.pathLengthFallbacks[r.Method]) > 0
 }
 
 type handler struct {
-	pat Pattern
-	h   HandlerFunc
+	pat  Pattern
+	h    HandlerFunc
+	opts handlerOptions
+	// marshaler is the marshaler set with WithRouteMarshaler, if any.
+	marshaler Marshaler
+}
+
+// HandlerOption is an option for a handler registered with ServeMux.Handle.
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	cacheControl    string
+	requiredHeaders []string
+	maxBodySize     int64
+	requestType     string
+	responseType    string
+	noBodyBinding   bool
+	serverStreaming bool
+}
+
+// WithRequiredHeaders returns a HandlerOption that rejects the requests to the handler which lack
+// any of the headers, e.g. "X-Tenant-ID", or have them empty, with an "InvalidArgument" error
+// before the handler is called.
+func WithRequiredHeaders(headers ...string) HandlerOption {
+	return func(o *handlerOptions) {
+		for _, h := range headers {
+			o.requiredHeaders = append(o.requiredHeaders, textproto.CanonicalMIMEHeaderKey(h))
+		}
+	}
+}
+
+func (s *ServeMux) newHandler(meth string, pat Pattern, h HandlerFunc, opts []HandlerOption) handler {
+	hd := handler{pat: pat, h: h, marshaler: s.routeMarshalers[routeKey(meth, pat)]}
+	for _, opt := range opts {
+		opt(&hd.opts)
+	}
+	return hd
 }
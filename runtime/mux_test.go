@@ -2,15 +2,22 @@ package runtime_test
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/grpc-ecosystem/grpc-gateway/utilities"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -335,6 +342,100 @@ func TestMuxServeHTTP(t *testing.T) {
 			respContent: "POST /foo/{id=*}:verb",
 			muxOpts:     []runtime.ServeMuxOption{runtime.WithLastMatchWins()},
 		},
+		{
+			patterns: []stubPattern{
+				{
+					method: "POST",
+					ops:    []int{int(utilities.OpLitPush), 0, int(utilities.OpPush), 0, int(utilities.OpConcatN), 1, int(utilities.OpCapture), 1},
+					pool:   []string{"jobs", "id"},
+					verb:   "cancel",
+				},
+			},
+			reqMethod:  "POST",
+			reqPath:    "/jobs/123:pause",
+			respStatus: http.StatusNotFound,
+		},
+		{
+			patterns: []stubPattern{
+				{
+					method: "POST",
+					ops:    []int{int(utilities.OpLitPush), 0, int(utilities.OpPush), 0, int(utilities.OpConcatN), 1, int(utilities.OpCapture), 1},
+					pool:   []string{"jobs", "id"},
+					verb:   "cancel",
+				},
+			},
+			reqMethod:   "POST",
+			reqPath:     "/jobs/123:pause",
+			respStatus:  http.StatusBadRequest,
+			respContent: "unknown verb \"pause\"\n",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithUnknownVerbStatus(http.StatusBadRequest)},
+		},
+		{
+			patterns: []stubPattern{
+				{
+					method: "POST",
+					ops:    []int{int(utilities.OpLitPush), 0, int(utilities.OpPush), 0, int(utilities.OpConcatN), 1, int(utilities.OpCapture), 1},
+					pool:   []string{"jobs", "id"},
+					verb:   "cancel",
+				},
+			},
+			reqMethod:   "POST",
+			reqPath:     "/jobs/123:pause",
+			respStatus:  http.StatusNotFound,
+			respContent: "unknown verb \"pause\"\n",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithUnknownVerbStatus(http.StatusNotFound)},
+		},
+		{
+			patterns: []stubPattern{
+				{
+					method: "POST",
+					ops:    []int{int(utilities.OpLitPush), 0, int(utilities.OpPush), 0, int(utilities.OpConcatN), 1, int(utilities.OpCapture), 1},
+					pool:   []string{"jobs", "id"},
+					verb:   "cancel",
+				},
+				{
+					method: "GET",
+					ops:    []int{int(utilities.OpLitPush), 0, int(utilities.OpPush), 0, int(utilities.OpConcatN), 1, int(utilities.OpCapture), 1},
+					pool:   []string{"jobs", "id"},
+				},
+			},
+			reqMethod:   "POST",
+			reqPath:     "/jobs/123:",
+			respStatus:  http.StatusBadRequest,
+			respContent: "empty verb\n",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithUnknownVerbStatus(http.StatusBadRequest)},
+		},
+		{
+			patterns: []stubPattern{
+				{
+					method: "POST",
+					ops:    []int{int(utilities.OpLitPush), 0, int(utilities.OpPush), 0, int(utilities.OpConcatN), 1, int(utilities.OpCapture), 1},
+					pool:   []string{"jobs", "id"},
+					verb:   "cancel",
+				},
+			},
+			reqMethod:   "POST",
+			reqPath:     "/jobs/123:pause",
+			respStatus:  http.StatusBadRequest,
+			respContent: "POST /jobs/123:pause",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithUnknownVerbStatus(http.StatusBadRequest)},
+			errHandler:  unknownPathIs404,
+		},
+		{
+			patterns: []stubPattern{
+				{
+					method: "POST",
+					ops:    []int{int(utilities.OpLitPush), 0, int(utilities.OpPush), 0, int(utilities.OpConcatN), 1, int(utilities.OpCapture), 1},
+					pool:   []string{"jobs", "id"},
+					verb:   "cancel",
+				},
+			},
+			reqMethod:   "POST",
+			reqPath:     "/other/123:pause",
+			respStatus:  http.StatusNotFound,
+			respContent: "Not Found\n",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithUnknownVerbStatus(http.StatusBadRequest)},
+		},
 	} {
 		opts := spec.muxOpts
 		if spec.disablePathLengthFallback {
@@ -396,42 +497,1511 @@ func unknownPathIs404(ctx context.Context, mux *runtime.ServeMux, m runtime.Mars
 	fmt.Fprintf(w, "%s %s", r.Method, r.URL.Path)
 }
 
-var defaultHeaderMatcherTests = []struct {
-	name     string
-	in       string
-	outValue string
-	outValid bool
-}{
-	{
-		"permanent HTTP header should return prefixed",
-		"Accept",
-		"grpcgateway-Accept",
-		true,
-	},
-	{
-		"key prefixed with MetadataHeaderPrefix should return without the prefix",
-		"Grpc-Metadata-Custom-Header",
-		"Custom-Header",
-		true,
-	},
-	{
-		"non-permanent HTTP header key without prefix should not return",
-		"Custom-Header",
-		"",
-		false,
-	},
+func TestMuxMethodNotAllowed(t *testing.T) {
+	foo := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+	bar := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"bar"}, ""))
+	customHandler := func(w http.ResponseWriter, r *http.Request, allowed []string) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		fmt.Fprintf(w, "%s not in %v", r.Method, allowed)
+	}
+
+	for _, spec := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		method  string
+		path    string
+		headers map[string]string
+
+		wantStatus  int
+		wantAllow   string
+		wantContent string
+	}{
+		{
+			name:       "method not allowed",
+			method:     "DELETE",
+			path:       "/foo",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantAllow:  "GET, PUT",
+		},
+		{
+			name:        "custom handler",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithMethodNotAllowedHandler(customHandler)},
+			method:      "DELETE",
+			path:        "/foo",
+			wantStatus:  http.StatusMethodNotAllowed,
+			wantAllow:   "GET, PUT",
+			wantContent: "DELETE not in [GET PUT]",
+		},
+		{
+			name: "custom handler with a proto error handler",
+			muxOpts: []runtime.ServeMuxOption{
+				runtime.WithMethodNotAllowedHandler(customHandler),
+				runtime.WithProtoErrorHandler(runtime.DefaultHTTPProtoErrorHandler),
+			},
+			method:      "DELETE",
+			path:        "/foo",
+			wantStatus:  http.StatusMethodNotAllowed,
+			wantAllow:   "GET, PUT",
+			wantContent: "DELETE not in [GET PUT]",
+		},
+		{
+			name: "unknown path with a proto error handler",
+			muxOpts: []runtime.ServeMuxOption{
+				runtime.WithMethodNotAllowedHandler(customHandler),
+				runtime.WithProtoErrorHandler(runtime.DefaultHTTPProtoErrorHandler),
+			},
+			method:     "DELETE",
+			path:       "/baz",
+			wantStatus: http.StatusNotImplemented,
+		},
+		{
+			name:        "path length fallback",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithMethodNotAllowedHandler(customHandler)},
+			method:      "POST",
+			path:        "/bar",
+			headers:     map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			wantStatus:  http.StatusOK,
+			wantContent: "GET /bar",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.muxOpts...)
+			for _, route := range []struct {
+				method string
+				pat    runtime.Pattern
+			}{
+				{method: "GET", pat: foo},
+				{method: "PUT", pat: foo},
+				{method: "GET", pat: bar},
+			} {
+				route := route
+				mux.Handle(route.method, route.pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+					fmt.Fprintf(w, "%s %s", route.method, route.pat)
+				})
+			}
+
+			r := httptest.NewRequest(spec.method, "http://host.example"+spec.path, nil)
+			for k, v := range spec.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.wantStatus; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+			if got, want := w.Header().Get("Allow"), spec.wantAllow; got != want {
+				t.Errorf("w.Header().Get(%q) = %q; want %q", "Allow", got, want)
+			}
+			if spec.wantContent != "" {
+				if got, want := w.Body.String(), spec.wantContent; got != want {
+					t.Errorf("w.Body = %q; want %q", got, want)
+				}
+			}
+		})
+	}
 }
 
-func TestDefaultHeaderMatcher(t *testing.T) {
-	for _, tt := range defaultHeaderMatcherTests {
-		t.Run(tt.name, func(t *testing.T) {
-			out, valid := runtime.DefaultHeaderMatcher(tt.in)
-			if out != tt.outValue {
-				t.Errorf("got %v, want %v", out, tt.outValue)
+func TestMuxRejectTRACE(t *testing.T) {
+	pattern := func(lit string) runtime.Pattern {
+		return runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{lit}, ""))
+	}
+	for _, spec := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		reqPath string
+
+		wantStatus int
+		wantAllow  string
+		wantBody   string
+	}{
+		{
+			name:       "rejected",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithRejectTRACE()},
+			reqPath:    "/foo",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantAllow:  "GET, POST",
+		},
+		{
+			name:       "rejected for an unknown path",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithRejectTRACE()},
+			reqPath:    "/unknown",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "registered handler",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithRejectTRACE()},
+			reqPath:    "/traced",
+			wantStatus: http.StatusOK,
+			wantBody:   "TRACE /traced",
+		},
+		{
+			name:       "not rejected by default",
+			reqPath:    "/foo",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantAllow:  "GET, POST",
+			wantBody:   "Method Not Allowed\n",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.muxOpts...)
+			handler := func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				fmt.Fprintf(w, "%s %s", r.Method, r.URL.Path)
 			}
-			if valid != tt.outValid {
-				t.Errorf("got %v, want %v", valid, tt.outValid)
+			mux.Handle("GET", pattern("foo"), handler)
+			mux.Handle("POST", pattern("foo"), handler)
+			mux.Handle("TRACE", pattern("traced"), handler)
+
+			r := httptest.NewRequest("TRACE", "http://host.example"+spec.reqPath, strings.NewReader("secret"))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.wantStatus; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+			if got, want := w.Header().Get("Allow"), spec.wantAllow; got != want {
+				t.Errorf("w.Header().Get(%q) = %q; want %q", "Allow", got, want)
+			}
+			if got, want := w.Body.String(), spec.wantBody; got != want {
+				t.Errorf("w.Body = %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestMuxHEADHandling(t *testing.T) {
+	pattern := func(lit string) runtime.Pattern {
+		return runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{lit}, ""))
+	}
+	for _, spec := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		method  string
+		reqPath string
+		headers map[string]string
+
+		wantStatus int
+		wantHeader string
+		wantBody   string
+	}{
+		{
+			name:       "GET handler",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithHEADHandling()},
+			method:     "HEAD",
+			reqPath:    "/foo",
+			wantStatus: http.StatusAccepted,
+			wantHeader: "get",
+		},
+		{
+			name:       "registered HEAD handler",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithHEADHandling()},
+			method:     "HEAD",
+			reqPath:    "/bar",
+			wantStatus: http.StatusOK,
+			wantHeader: "head",
+		},
+		{
+			name:    "method override",
+			muxOpts: []runtime.ServeMuxOption{runtime.WithHEADHandling()},
+			method:  "POST",
+			reqPath: "/foo",
+			headers: map[string]string{
+				"Content-Type":           "application/x-www-form-urlencoded",
+				"X-HTTP-Method-Override": "HEAD",
+			},
+			wantStatus: http.StatusAccepted,
+			wantHeader: "get",
+		},
+		{
+			name:       "disabled",
+			method:     "HEAD",
+			reqPath:    "/foo",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantBody:   "Method Not Allowed\n",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.muxOpts...)
+			mux.Handle("GET", pattern("foo"), func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				w.Header().Set("X-Handler", "get")
+				w.Header().Set("Content-Length", "5")
+				w.WriteHeader(http.StatusAccepted)
+				fmt.Fprint(w, "hello")
+			})
+			mux.Handle("GET", pattern("bar"), func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				w.Header().Set("X-Handler", "get")
+			})
+			mux.Handle("HEAD", pattern("bar"), func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				w.Header().Set("X-Handler", "head")
+			})
+
+			r := httptest.NewRequest(spec.method, "http://host.example"+spec.reqPath, nil)
+			for k, v := range spec.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.wantStatus; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+			if got, want := w.Header().Get("X-Handler"), spec.wantHeader; got != want {
+				t.Errorf("w.Header().Get(%q) = %q; want %q", "X-Handler", got, want)
+			}
+			if spec.wantHeader == "get" {
+				if got, want := w.Header().Get("Content-Length"), "5"; got != want {
+					t.Errorf("w.Header().Get(%q) = %q; want %q", "Content-Length", got, want)
+				}
+			}
+			if got, want := w.Body.String(), spec.wantBody; got != want {
+				t.Errorf("w.Body = %q; want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestMuxPathLengthFallbackMethod(t *testing.T) {
+	foo := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+	const form = "application/x-www-form-urlencoded"
+
+	for _, spec := range []struct {
+		name        string
+		muxOpts     []runtime.ServeMuxOption
+		method      string
+		contentType string
+
+		wantStatus  int
+		wantContent string
+	}{
+		{
+			name:        "custom fallback of POST",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithPathLengthFallbackMethod("POST", "DELETE")},
+			method:      "POST",
+			contentType: form,
+			wantStatus:  http.StatusOK,
+			wantContent: "DELETE /foo",
+		},
+		{
+			name: "custom fallbacks in order",
+			muxOpts: []runtime.ServeMuxOption{
+				runtime.WithPathLengthFallbackMethod("POST", "PATCH"),
+				runtime.WithPathLengthFallbackMethod("post", "get"),
+			},
+			method:      "POST",
+			contentType: form,
+			wantStatus:  http.StatusOK,
+			wantContent: "GET /foo",
+		},
+		{
+			name:        "custom method",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithPathLengthFallbackMethod("PUT", "DELETE")},
+			method:      "PUT",
+			contentType: form,
+			wantStatus:  http.StatusOK,
+			wantContent: "DELETE /foo",
+		},
+		{
+			name:        "custom method without fallback",
+			method:      "PUT",
+			contentType: form,
+			wantStatus:  http.StatusMethodNotAllowed,
+		},
+		{
+			name:        "not a form",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithPathLengthFallbackMethod("PUT", "DELETE")},
+			method:      "PUT",
+			contentType: "application/json",
+			wantStatus:  http.StatusMethodNotAllowed,
+		},
+		{
+			name: "disabled",
+			muxOpts: []runtime.ServeMuxOption{
+				runtime.WithPathLengthFallbackMethod("PUT", "DELETE"),
+				runtime.WithDisablePathLengthFallback(),
+			},
+			method:      "PUT",
+			contentType: form,
+			wantStatus:  http.StatusMethodNotAllowed,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.muxOpts...)
+			for _, method := range []string{"GET", "DELETE"} {
+				method := method
+				mux.Handle(method, foo, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+					fmt.Fprintf(w, "%s %s", method, foo)
+				})
+			}
+
+			r := httptest.NewRequest(spec.method, "http://host.example/foo", strings.NewReader("a=b"))
+			r.Header.Set("Content-Type", spec.contentType)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.wantStatus; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+			if spec.wantContent != "" {
+				if got, want := w.Body.String(), spec.wantContent; got != want {
+					t.Errorf("w.Body = %q; want %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMuxDisableMethodOverride(t *testing.T) {
+	foo := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+	for _, spec := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		want    string
+	}{
+		{
+			name: "honored",
+			want: "DELETE /foo",
+		},
+		{
+			name:    "ignored",
+			muxOpts: []runtime.ServeMuxOption{runtime.WithDisableMethodOverride()},
+			want:    "POST /foo",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.muxOpts...)
+			for _, method := range []string{"POST", "DELETE"} {
+				method := method
+				mux.Handle(method, foo, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+					fmt.Fprintf(w, "%s %s", method, foo)
+				})
+			}
+
+			r := httptest.NewRequest("POST", "http://host.example/foo", strings.NewReader("a=b"))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.Header.Set("X-HTTP-Method-Override", "DELETE")
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Body.String(), spec.want; got != want {
+				t.Errorf("w.Body = %q; want %q", got, want)
 			}
 		})
 	}
 }
+
+func TestMuxTrailingSlash(t *testing.T) {
+	lit, push := int(utilities.OpLitPush), int(utilities.OpPush)
+	concat, capture := int(utilities.OpConcatN), int(utilities.OpCapture)
+	routes := []struct {
+		method string
+		pat    runtime.Pattern
+	}{
+		// "/v1/users"
+		{method: "GET", pat: runtime.MustPattern(runtime.NewPattern(1, []int{lit, 0, lit, 1}, []string{"v1", "users"}, ""))},
+		{method: "POST", pat: runtime.MustPattern(runtime.NewPattern(1, []int{lit, 0, lit, 1}, []string{"v1", "users"}, ""))},
+		// "/v1/users/{id}"
+		{method: "GET", pat: runtime.MustPattern(runtime.NewPattern(1, []int{lit, 0, lit, 1, push, 0, concat, 1, capture, 2}, []string{"v1", "users", "id"}, ""))},
+		// "/v1/groups/"
+		{method: "GET", pat: runtime.MustPattern(runtime.NewPattern(1, []int{lit, 0, lit, 1, lit, 2}, []string{"v1", "groups", ""}, ""))},
+		// "/v1/jobs/{id}:cancel"
+		{method: "POST", pat: runtime.MustPattern(runtime.NewPattern(1, []int{lit, 0, lit, 1, push, 0, concat, 1, capture, 2}, []string{"v1", "jobs", "id"}, "cancel"))},
+	}
+
+	for _, spec := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		method  string
+		path    string
+
+		wantStatus   int
+		wantContent  string
+		wantLocation string
+	}{
+		{
+			name:       "exact by default",
+			method:     "GET",
+			path:       "/v1/groups",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:        "match without trailing slash",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithTrailingSlashMatch()},
+			method:      "GET",
+			path:        "/v1/groups",
+			wantStatus:  http.StatusOK,
+			wantContent: "GET /v1/groups/",
+		},
+		{
+			name:        "match with trailing slash",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithTrailingSlashMatch()},
+			method:      "POST",
+			path:        "/v1/jobs/1:cancel/",
+			wantStatus:  http.StatusOK,
+			wantContent: "POST /v1/jobs/{id=*}:cancel",
+		},
+		{
+			name:        "captured segment",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithTrailingSlashMatch()},
+			method:      "GET",
+			path:        "/v1/users/foo",
+			wantStatus:  http.StatusOK,
+			wantContent: "GET /v1/users/{id=*}",
+		},
+		{
+			name:        "verb",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithTrailingSlashMatch()},
+			method:      "POST",
+			path:        "/v1/jobs/1:cancel",
+			wantStatus:  http.StatusOK,
+			wantContent: "POST /v1/jobs/{id=*}:cancel",
+		},
+		{
+			name:       "method not allowed for the other form",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithTrailingSlashMatch()},
+			method:     "DELETE",
+			path:       "/v1/groups",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "no match in either form",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithTrailingSlashMatch()},
+			method:     "GET",
+			path:       "/v1/others/",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:         "redirect GET",
+			muxOpts:      []runtime.ServeMuxOption{runtime.WithTrailingSlashRedirect()},
+			method:       "GET",
+			path:         "/v1/groups?page=2",
+			wantStatus:   http.StatusMovedPermanently,
+			wantLocation: "/v1/groups/?page=2",
+		},
+		{
+			name:         "redirect POST",
+			muxOpts:      []runtime.ServeMuxOption{runtime.WithTrailingSlashRedirect()},
+			method:       "POST",
+			path:         "/v1/jobs/1:cancel/",
+			wantStatus:   http.StatusPermanentRedirect,
+			wantLocation: "/v1/jobs/1:cancel",
+		},
+		{
+			name:        "no redirect of exact match",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithTrailingSlashRedirect()},
+			method:      "GET",
+			path:        "/v1/users",
+			wantStatus:  http.StatusOK,
+			wantContent: "GET /v1/users",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.muxOpts...)
+			for _, route := range routes {
+				route := route
+				mux.HandleVerb(route.method, route.pat, route.pat.Verb(), func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+					fmt.Fprintf(w, "%s %s", route.method, route.pat)
+				})
+			}
+
+			r := httptest.NewRequest(spec.method, "http://host.example"+spec.path, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.wantStatus; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+			if spec.wantContent != "" {
+				if got, want := w.Body.String(), spec.wantContent; got != want {
+					t.Errorf("w.Body = %q; want %q", got, want)
+				}
+			}
+			if got, want := w.Header().Get("Location"), spec.wantLocation; got != want {
+				t.Errorf("w.Header().Get(%q) = %q; want %q", "Location", got, want)
+			}
+		})
+	}
+}
+
+func TestMuxMaxIncomingHeaders(t *testing.T) {
+	for _, spec := range []struct {
+		name       string
+		count      int
+		totalBytes int
+		headers    map[string]string
+		path       string
+		errHandler runtime.ProtoErrorHandlerFunc
+
+		respStatus int
+	}{
+		{
+			name:       "within limits",
+			count:      2,
+			totalBytes: 64,
+			headers:    map[string]string{"X-Foo": "foo", "X-Bar": "bar"},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "too many headers",
+			count:      2,
+			headers:    map[string]string{"X-Foo": "foo", "X-Bar": "bar", "X-Baz": "baz"},
+			respStatus: http.StatusRequestHeaderFieldsTooLarge,
+		},
+		{
+			name:       "headers too large",
+			totalBytes: 15,
+			headers:    map[string]string{"X-Foo": "foo", "X-Bar": "bar"},
+			respStatus: http.StatusRequestHeaderFieldsTooLarge,
+		},
+		{
+			name:       "size exactly at the limit",
+			totalBytes: 16,
+			headers:    map[string]string{"X-Foo": "foo", "X-Bar": "bar"},
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "too many headers with proto error handler",
+			count:      1,
+			headers:    map[string]string{"X-Foo": "foo", "X-Bar": "bar"},
+			errHandler: unknownPathIs404,
+			respStatus: http.StatusRequestHeaderFieldsTooLarge,
+		},
+		{
+			name:       "too many headers for a builtin endpoint",
+			count:      1,
+			path:       "/version",
+			headers:    map[string]string{"X-Foo": "foo", "X-Bar": "bar"},
+			respStatus: http.StatusRequestHeaderFieldsTooLarge,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			opts := []runtime.ServeMuxOption{
+				runtime.WithMaxIncomingHeaders(spec.count, spec.totalBytes),
+				runtime.WithVersionEndpoint("/version", runtime.VersionInfo{Version: "v1.2.3"}),
+			}
+			if spec.errHandler != nil {
+				opts = append(opts, runtime.WithProtoErrorHandler(spec.errHandler))
+			}
+			mux := runtime.NewServeMux(opts...)
+			pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+			mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			path := "/foo"
+			if spec.path != "" {
+				path = spec.path
+			}
+			r := httptest.NewRequest("GET", "http://host.example"+path, nil)
+			for name, value := range spec.headers {
+				r.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.respStatus; got != want {
+				t.Errorf("w.Code = %d; want %d; headers=%v", got, want, spec.headers)
+			}
+		})
+	}
+}
+
+func TestMuxMaxIncomingHeadersCheckedFirst(t *testing.T) {
+	mux := runtime.NewServeMux(
+		runtime.WithMaxIncomingHeaders(1, 0),
+		runtime.WithRequestID("", nil),
+		runtime.WithResponseCompression("gzip", runtime.GzipCompressor(gzip.DefaultCompression)),
+	)
+	r := httptest.NewRequest("GET", "http://host.example/foo", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	r.Header.Set(runtime.RequestIDHeader, "id")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	if got, want := w.Code, http.StatusRequestHeaderFieldsTooLarge; got != want {
+		t.Errorf("w.Code = %d; want %d", got, want)
+	}
+	if got := w.Header().Get(runtime.RequestIDHeader); got != "" {
+		t.Errorf("%s = %q; want the request ID not echoed", runtime.RequestIDHeader, got)
+	}
+	if got := w.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("Content-Encoding = %q; want the response not compressed", got)
+	}
+}
+
+func TestMuxHandleVerb(t *testing.T) {
+	mux := runtime.NewServeMux()
+	// "/v1/jobs/{id}"
+	base := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{
+			int(utilities.OpLitPush), 0,
+			int(utilities.OpLitPush), 1,
+			int(utilities.OpPush), 0,
+			int(utilities.OpConcatN), 1,
+			int(utilities.OpCapture), 2,
+		},
+		[]string{"v1", "jobs", "id"},
+		"",
+	))
+	for _, verb := range []string{"cancel", "retry"} {
+		func(verb string) {
+			mux.HandleVerb("POST", base, verb, func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+				fmt.Fprintf(w, "%s %s", verb, pathParams["id"])
+			})
+		}(verb)
+	}
+
+	for _, spec := range []struct {
+		reqPath     string
+		respStatus  int
+		respContent string
+	}{
+		{
+			reqPath:     "/v1/jobs/123:cancel",
+			respStatus:  http.StatusOK,
+			respContent: "cancel 123",
+		},
+		{
+			reqPath:     "/v1/jobs/123:retry",
+			respStatus:  http.StatusOK,
+			respContent: "retry 123",
+		},
+		{
+			reqPath:    "/v1/jobs/123:pause",
+			respStatus: http.StatusNotFound,
+		},
+		{
+			reqPath:    "/v1/jobs/123",
+			respStatus: http.StatusNotFound,
+		},
+	} {
+		r := httptest.NewRequest("POST", "http://host.example"+spec.reqPath, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		if got, want := w.Code, spec.respStatus; got != want {
+			t.Errorf("w.Code = %d; want %d; path=%q", got, want, spec.reqPath)
+		}
+		if spec.respContent != "" {
+			if got, want := w.Body.String(), spec.respContent; got != want {
+				t.Errorf("w.Body = %q; want %q; path=%q", got, want, spec.reqPath)
+			}
+		}
+	}
+
+	if got, want := base.Verb(), ""; got != want {
+		t.Errorf("base.Verb() = %q; want %q", got, want)
+	}
+}
+
+func TestMuxHandleBulk(t *testing.T) {
+	pattern := func(lit string) runtime.Pattern {
+		return runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{lit}, ""))
+	}
+	handler := func(name string) runtime.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			fmt.Fprint(w, name)
+		}
+	}
+
+	mux := runtime.NewServeMux()
+	err := mux.HandleBulk([]runtime.Registration{
+		{Method: "GET", Pattern: pattern("foo"), Handler: handler("get foo")},
+		{Method: "POST", Pattern: pattern("foo"), Handler: handler("post foo")},
+		{Method: "GET", Pattern: pattern("bar"), Handler: handler("get bar")},
+	})
+	if err != nil {
+		t.Fatalf("mux.HandleBulk(regs) failed with %v; want success", err)
+	}
+	for _, spec := range []struct {
+		method      string
+		reqPath     string
+		respStatus  int
+		respContent string
+	}{
+		{method: "GET", reqPath: "/foo", respStatus: http.StatusOK, respContent: "get foo"},
+		{method: "POST", reqPath: "/foo", respStatus: http.StatusOK, respContent: "post foo"},
+		{method: "GET", reqPath: "/bar", respStatus: http.StatusOK, respContent: "get bar"},
+		{method: "POST", reqPath: "/bar", respStatus: http.StatusMethodNotAllowed},
+		{method: "GET", reqPath: "/baz", respStatus: http.StatusNotFound},
+	} {
+		r := httptest.NewRequest(spec.method, "http://host.example"+spec.reqPath, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		if got, want := w.Code, spec.respStatus; got != want {
+			t.Errorf("w.Code = %d; want %d; method=%q, path=%q", got, want, spec.method, spec.reqPath)
+		}
+		if spec.respContent != "" {
+			if got, want := w.Body.String(), spec.respContent; got != want {
+				t.Errorf("w.Body = %q; want %q; method=%q, path=%q", got, want, spec.method, spec.reqPath)
+			}
+		}
+	}
+
+	for _, spec := range []struct {
+		name string
+		regs []runtime.Registration
+	}{
+		{
+			name: "no method",
+			regs: []runtime.Registration{{Pattern: pattern("foo"), Handler: handler("foo")}},
+		},
+		{
+			name: "no handler",
+			regs: []runtime.Registration{{Method: "GET", Pattern: pattern("foo")}},
+		},
+		{
+			name: "duplicate",
+			regs: []runtime.Registration{
+				{Method: "GET", Pattern: pattern("qux"), Handler: handler("qux")},
+				{Method: "GET", Pattern: pattern("qux"), Handler: handler("qux again")},
+			},
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux()
+			if err := mux.HandleBulk(spec.regs); err == nil {
+				t.Fatalf("mux.HandleBulk(regs) not failed; want an error")
+			}
+			r := httptest.NewRequest("GET", "http://host.example/qux", nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			if got, want := w.Code, http.StatusNotFound; got != want {
+				t.Errorf("w.Code = %d; want %d; the failed registrations must not be registered", got, want)
+			}
+		})
+	}
+}
+
+func TestMuxHandleAll(t *testing.T) {
+	// "/v1/items/{id}"
+	versioned := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{
+			int(utilities.OpLitPush), 0,
+			int(utilities.OpLitPush), 1,
+			int(utilities.OpPush), 0,
+			int(utilities.OpConcatN), 1,
+			int(utilities.OpCapture), 2,
+		},
+		[]string{"v1", "items", "id"},
+		"",
+	))
+	// "/items/{id}"
+	unversioned := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{
+			int(utilities.OpLitPush), 0,
+			int(utilities.OpPush), 0,
+			int(utilities.OpConcatN), 1,
+			int(utilities.OpCapture), 1,
+		},
+		[]string{"items", "id"},
+		"",
+	))
+	// "/items/special"
+	special := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{int(utilities.OpLitPush), 0, int(utilities.OpLitPush), 1},
+		[]string{"items", "special"},
+		"",
+	))
+	handler := func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		pattern, _ := runtime.HTTPPathPattern(r.Context())
+		fmt.Fprintf(w, "%s %s", pattern, pathParams["id"])
+	}
+
+	for _, spec := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		reqPath string
+
+		respContent string
+	}{
+		{
+			name:        "first alias",
+			reqPath:     "/v1/items/foo",
+			respContent: versioned.String() + " foo",
+		},
+		{
+			name:        "second alias",
+			reqPath:     "/items/foo",
+			respContent: unversioned.String() + " foo",
+		},
+		{
+			name:        "first match wins",
+			reqPath:     "/items/special",
+			respContent: unversioned.String() + " special",
+		},
+		{
+			name:        "last match wins",
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithLastMatchWins()},
+			reqPath:     "/items/special",
+			respContent: special.String() + " ",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.muxOpts...)
+			if err := mux.HandleAll("GET", []runtime.Pattern{versioned, unversioned, special}, handler); err != nil {
+				t.Fatalf("mux.HandleAll(%q, pats, handler) failed with %v; want success", "GET", err)
+			}
+			r := httptest.NewRequest("GET", "http://host.example"+spec.reqPath, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, http.StatusOK; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+			if got, want := w.Body.String(), spec.respContent; got != want {
+				t.Errorf("w.Body = %q; want %q", got, want)
+			}
+		})
+	}
+
+	mux := runtime.NewServeMux()
+	if err := mux.HandleAll("GET", []runtime.Pattern{versioned, versioned}, handler); err == nil {
+		t.Errorf("mux.HandleAll(%q, pats, handler) not failed with a duplicate pattern; want an error", "GET")
+	}
+}
+
+func TestMuxUnexpectedBody(t *testing.T) {
+	for _, spec := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		method  string
+		body    string
+		// chunked is true if the body has an unknown length, like chunked bodies.
+		chunked bool
+
+		respStatus int
+	}{
+		{
+			name:       "ignored by default",
+			method:     "GET",
+			body:       `{"id": "foo"}`,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "ignored",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithUnexpectedBodyPolicy(runtime.IgnoreUnexpectedBody)},
+			method:     "GET",
+			body:       `{"id": "foo"}`,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "rejected",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithUnexpectedBodyPolicy(runtime.RejectUnexpectedBody)},
+			method:     "GET",
+			body:       `{"id": "foo"}`,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejected without body",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithUnexpectedBodyPolicy(runtime.RejectUnexpectedBody)},
+			method:     "GET",
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "rejected with chunked body",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithUnexpectedBodyPolicy(runtime.RejectUnexpectedBody)},
+			method:     "GET",
+			body:       `{"id": "foo"}`,
+			chunked:    true,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "rejected with empty chunked body",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithUnexpectedBodyPolicy(runtime.RejectUnexpectedBody)},
+			method:     "DELETE",
+			chunked:    true,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "rejected for other method",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithUnexpectedBodyPolicy(runtime.RejectUnexpectedBody)},
+			method:     "POST",
+			body:       `{"id": "foo"}`,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "rejected for given methods only",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithUnexpectedBodyPolicy(runtime.RejectUnexpectedBody, "GET")},
+			method:     "DELETE",
+			body:       `{"id": "foo"}`,
+			respStatus: http.StatusOK,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.muxOpts...)
+			pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+			for _, m := range []string{"GET", "POST", "DELETE"} {
+				mux.Handle(m, pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+					w.WriteHeader(http.StatusOK)
+				})
+			}
+
+			var body io.Reader
+			if spec.body != "" {
+				body = strings.NewReader(spec.body)
+			}
+			r := httptest.NewRequest(spec.method, "http://host.example/foo", body)
+			if spec.chunked {
+				r.Body = ioutil.NopCloser(strings.NewReader(spec.body))
+				r.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.respStatus; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestMuxRejectUnexpectedBody(t *testing.T) {
+	for _, spec := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		method  string
+		path    string
+		body    string
+
+		respStatus int
+	}{
+		{
+			name:       "ignored by default",
+			method:     "GET",
+			path:       "/query",
+			body:       `{"id": "foo"}`,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "rejected",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithRejectUnexpectedBody()},
+			method:     "GET",
+			path:       "/query",
+			body:       `{"id": "foo"}`,
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "accepted without body",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithRejectUnexpectedBody()},
+			method:     "GET",
+			path:       "/query",
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "accepted for a body binding",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithRejectUnexpectedBody()},
+			method:     "POST",
+			path:       "/body",
+			body:       `{"id": "foo"}`,
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "accepted for an undeclared binding",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithRejectUnexpectedBody()},
+			method:     "GET",
+			path:       "/body",
+			body:       `{"id": "foo"}`,
+			respStatus: http.StatusOK,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.muxOpts...)
+			handler := func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				w.WriteHeader(http.StatusOK)
+			}
+			query := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"query"}, ""))
+			mux.Handle("GET", query, handler, runtime.WithNoBodyBinding())
+			body := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"body"}, ""))
+			mux.Handle("GET", body, handler)
+			mux.Handle("POST", body, handler)
+
+			var reqBody io.Reader
+			if spec.body != "" {
+				reqBody = strings.NewReader(spec.body)
+			}
+			r := httptest.NewRequest(spec.method, "http://host.example"+spec.path, reqBody)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.respStatus; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+		})
+	}
+}
+
+var defaultHeaderMatcherTests = []struct {
+	name     string
+	in       string
+	outValue string
+	outValid bool
+}{
+	{
+		"permanent HTTP header should return prefixed",
+		"Accept",
+		"grpcgateway-Accept",
+		true,
+	},
+	{
+		"key prefixed with MetadataHeaderPrefix should return without the prefix",
+		"Grpc-Metadata-Custom-Header",
+		"Custom-Header",
+		true,
+	},
+	{
+		"non-permanent HTTP header key without prefix should not return",
+		"Custom-Header",
+		"",
+		false,
+	},
+	{
+		"hop-by-hop header should not return",
+		"Connection",
+		"",
+		false,
+	},
+	{
+		"proxy header should not return",
+		"proxy-authorization",
+		"",
+		false,
+	},
+}
+
+func TestDefaultHeaderMatcher(t *testing.T) {
+	for _, tt := range defaultHeaderMatcherTests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, valid := runtime.DefaultHeaderMatcher(tt.in)
+			if out != tt.outValue {
+				t.Errorf("got %v, want %v", out, tt.outValue)
+			}
+			if valid != tt.outValid {
+				t.Errorf("got %v, want %v", valid, tt.outValid)
+			}
+		})
+	}
+}
+
+func TestPrefixHeaderMatcher(t *testing.T) {
+	for _, spec := range []struct {
+		prefix string
+		in     string
+
+		want      string
+		wantValid bool
+	}{
+		{prefix: "X-Tenant-", in: "X-Tenant-Id", want: "Id", wantValid: true},
+		{prefix: "x-tenant-", in: "X-Tenant-Id", want: "Id", wantValid: true},
+		{prefix: "X-Tenant-", in: "x-tenant-id", want: "Id", wantValid: true},
+		{prefix: "X-TENANT-", in: "x-Tenant-region", want: "Region", wantValid: true},
+		{prefix: "X-Tenant-", in: "X-Tenant-"},
+		{prefix: "X-Tenant-", in: "X-Other-Id"},
+	} {
+		got, valid := runtime.PrefixHeaderMatcher(spec.prefix)(spec.in)
+		if got != spec.want || valid != spec.wantValid {
+			t.Errorf("runtime.PrefixHeaderMatcher(%q)(%q) = %q, %t; want %q, %t", spec.prefix, spec.in, got, valid, spec.want, spec.wantValid)
+		}
+	}
+}
+
+func TestIncomingHeaderMatcherCanonicalKeys(t *testing.T) {
+	var keys []string
+	mux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+		keys = append(keys, key)
+		return runtime.PrefixHeaderMatcher("x-tenant-")(key)
+	}))
+	r := httptest.NewRequest("GET", "http://host.example/foo", nil)
+	// Added without canonicalization, as some middlewares do.
+	r.Header["x-tenant-id"] = []string{"tenant"}
+	r.Header["X-TENANT-REGION"] = []string{"eu"}
+
+	ctx, err := runtime.AnnotateContext(context.Background(), mux, r)
+	if err != nil {
+		t.Fatalf("runtime.AnnotateContext failed with %v; want success", err)
+	}
+	for _, key := range keys {
+		if want := textproto.CanonicalMIMEHeaderKey(key); key != want {
+			t.Errorf("matcher called with %q; want %q", key, want)
+		}
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if got, want := md.Get("id"), []string{"tenant"}; !reflect.DeepEqual(got, want) {
+		t.Errorf(`md.Get("id") = %q; want %q`, got, want)
+	}
+	if got, want := md.Get("region"), []string{"eu"}; !reflect.DeepEqual(got, want) {
+		t.Errorf(`md.Get("region") = %q; want %q`, got, want)
+	}
+}
+
+func TestMuxDebugRouteHeader(t *testing.T) {
+	// "/v1/objects/{name}"
+	pat := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{
+			int(utilities.OpLitPush), 0,
+			int(utilities.OpLitPush), 1,
+			int(utilities.OpPush), 0,
+			int(utilities.OpConcatN), 1,
+			int(utilities.OpCapture), 2,
+		},
+		[]string{"v1", "objects", "name"},
+		"",
+	))
+	trusted := func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "trusted"
+	}
+
+	for _, spec := range []struct {
+		name    string
+		opts    []runtime.ServeMuxOption
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:    "trusted debug request",
+			opts:    []runtime.ServeMuxOption{runtime.WithDebugRouteHeader(trusted)},
+			headers: map[string]string{"X-Debug-Route": "true", "Authorization": "trusted"},
+			want:    "/v1/objects/{name=*}",
+		},
+		{
+			name:    "untrusted debug request",
+			opts:    []runtime.ServeMuxOption{runtime.WithDebugRouteHeader(trusted)},
+			headers: map[string]string{"X-Debug-Route": "true"},
+		},
+		{
+			name:    "trusted request without debug header",
+			opts:    []runtime.ServeMuxOption{runtime.WithDebugRouteHeader(trusted)},
+			headers: map[string]string{"Authorization": "trusted"},
+		},
+		{
+			name:    "option not set",
+			headers: map[string]string{"X-Debug-Route": "true", "Authorization": "trusted"},
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.opts...)
+			mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {})
+
+			r := httptest.NewRequest("GET", "http://host.example/v1/objects/foo", nil)
+			for k, v := range spec.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got := w.Header().Get(runtime.MatchedRouteHeader); got != spec.want {
+				t.Errorf("w.Header().Get(%q) = %q; want %q", runtime.MatchedRouteHeader, got, spec.want)
+			}
+		})
+	}
+}
+
+func TestMuxRequiredHeaders(t *testing.T) {
+	pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+
+	for _, spec := range []struct {
+		name        string
+		headers     map[string]string
+		wantStatus  int
+		wantHandled bool
+	}{
+		{
+			name:        "all present",
+			headers:     map[string]string{"X-Tenant-ID": "tenant", "X-Region": "eu"},
+			wantStatus:  http.StatusOK,
+			wantHandled: true,
+		},
+		{
+			name:        "case insensitive",
+			headers:     map[string]string{"x-tenant-id": "tenant", "x-region": "eu"},
+			wantStatus:  http.StatusOK,
+			wantHandled: true,
+		},
+		{
+			name:       "one missing",
+			headers:    map[string]string{"X-Tenant-ID": "tenant"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "empty",
+			headers:    map[string]string{"X-Tenant-ID": "", "X-Region": "eu"},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "all missing",
+			wantStatus: http.StatusBadRequest,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			var handled bool
+			mux := runtime.NewServeMux()
+			mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				handled = true
+			}, runtime.WithRequiredHeaders("X-Tenant-ID", "x-region"))
+
+			r := httptest.NewRequest("GET", "http://host.example/foo", nil)
+			for k, v := range spec.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.wantStatus; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+			if got, want := handled, spec.wantHandled; got != want {
+				t.Errorf("handled = %t; want %t", got, want)
+			}
+		})
+	}
+
+	// Routes without the option are not affected.
+	mux := runtime.NewServeMux()
+	mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {})
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "http://host.example/foo", nil))
+	if got, want := w.Code, http.StatusOK; got != want {
+		t.Errorf("w.Code = %d; want %d without required headers", got, want)
+	}
+}
+
+func TestMuxUnescapingMode(t *testing.T) {
+	// "/v1/objects/{name}"
+	single := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{
+			int(utilities.OpLitPush), 0,
+			int(utilities.OpLitPush), 1,
+			int(utilities.OpPush), 0,
+			int(utilities.OpConcatN), 1,
+			int(utilities.OpCapture), 2,
+		},
+		[]string{"v1", "objects", "name"},
+		"",
+	))
+	// "/v1/files/{path=**}"
+	multi := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{
+			int(utilities.OpLitPush), 0,
+			int(utilities.OpLitPush), 1,
+			int(utilities.OpPushM), 0,
+			int(utilities.OpConcatN), 1,
+			int(utilities.OpCapture), 2,
+		},
+		[]string{"v1", "files", "path"},
+		"",
+	))
+
+	for _, spec := range []struct {
+		mode        runtime.UnescapingMode
+		reqPath     string
+		respStatus  int
+		respContent string
+	}{
+		{
+			mode:       runtime.UnescapingModeLegacy,
+			reqPath:    "/v1/objects/a%2Fb",
+			respStatus: http.StatusNotFound,
+		},
+		{
+			mode:        runtime.UnescapingModeLegacy,
+			reqPath:     "/v1/objects/a%20b",
+			respStatus:  http.StatusOK,
+			respContent: "name=a b",
+		},
+		{
+			mode:        runtime.UnescapingModeLegacy,
+			reqPath:     "/v1/files/a%2Fb/c",
+			respStatus:  http.StatusOK,
+			respContent: "path=a/b/c",
+		},
+		{
+			mode:        runtime.UnescapingModeAllExceptReserved,
+			reqPath:     "/v1/objects/a%2Fb",
+			respStatus:  http.StatusOK,
+			respContent: "name=a/b",
+		},
+		{
+			mode:       runtime.UnescapingModeAllExceptReserved,
+			reqPath:    "/v1/objects/a%2Fb/c",
+			respStatus: http.StatusNotFound,
+		},
+		{
+			mode:        runtime.UnescapingModeAllExceptReserved,
+			reqPath:     "/v1/files/a%2Fb%3Ac%20d/e",
+			respStatus:  http.StatusOK,
+			respContent: "path=a%2Fb%3Ac d/e",
+		},
+		{
+			mode:        runtime.UnescapingModeAllExceptSlash,
+			reqPath:     "/v1/objects/a%2Fb",
+			respStatus:  http.StatusOK,
+			respContent: "name=a/b",
+		},
+		{
+			mode:        runtime.UnescapingModeAllExceptSlash,
+			reqPath:     "/v1/files/a%2Fb%3Ac%20d/e",
+			respStatus:  http.StatusOK,
+			respContent: "path=a%2Fb:c d/e",
+		},
+		{
+			mode:        runtime.UnescapingModeAllCharacters,
+			reqPath:     "/v1/objects/a%2Fb",
+			respStatus:  http.StatusOK,
+			respContent: "name=a/b",
+		},
+		{
+			mode:        runtime.UnescapingModeAllCharacters,
+			reqPath:     "/v1/files/a%2Fb%3Ac%20d/e",
+			respStatus:  http.StatusOK,
+			respContent: "path=a/b:c d/e",
+		},
+	} {
+		mux := runtime.NewServeMux(runtime.WithUnescapingMode(spec.mode))
+		mux.Handle("GET", single, func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			fmt.Fprintf(w, "name=%s", pathParams["name"])
+		})
+		mux.Handle("GET", multi, func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			fmt.Fprintf(w, "path=%s", pathParams["path"])
+		})
+
+		r := httptest.NewRequest("GET", "http://host.example"+spec.reqPath, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		if got, want := w.Code, spec.respStatus; got != want {
+			t.Errorf("w.Code = %d; want %d; mode=%d, path=%q", got, want, spec.mode, spec.reqPath)
+		}
+		if spec.respContent != "" {
+			if got, want := w.Body.String(), spec.respContent; got != want {
+				t.Errorf("w.Body = %q; want %q; mode=%d, path=%q", got, want, spec.mode, spec.reqPath)
+			}
+		}
+	}
+}
+
+func TestMuxStrictPercentDecoding(t *testing.T) {
+	// "/v1/objects/{name}"
+	pat := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{
+			int(utilities.OpLitPush), 0,
+			int(utilities.OpLitPush), 1,
+			int(utilities.OpPush), 0,
+			int(utilities.OpConcatN), 1,
+			int(utilities.OpCapture), 2,
+		},
+		[]string{"v1", "objects", "name"},
+		"",
+	))
+
+	for _, spec := range []struct {
+		strict bool
+		// path and rawPath are set in the URL of the request as is, like a lenient parser does.
+		path    string
+		rawPath string
+
+		respStatus  int
+		respContent string
+	}{
+		{strict: true, path: "/v1/objects/A", rawPath: "/v1/objects/%41", respStatus: http.StatusOK, respContent: "name=A"},
+		{strict: true, path: "/v1/objects/%ZZ", rawPath: "/v1/objects/%ZZ", respStatus: http.StatusBadRequest},
+		{strict: true, path: "/v1/objects/%4", rawPath: "/v1/objects/%4", respStatus: http.StatusBadRequest},
+		{path: "/v1/objects/A", rawPath: "/v1/objects/%41", respStatus: http.StatusOK, respContent: "name=A"},
+		{path: "/v1/objects/%ZZ", rawPath: "/v1/objects/%ZZ", respStatus: http.StatusOK, respContent: "name=%ZZ"},
+	} {
+		opts := []runtime.ServeMuxOption{runtime.WithUnescapingMode(runtime.UnescapingModeAllCharacters)}
+		if spec.strict {
+			opts = append(opts, runtime.WithStrictPercentDecoding())
+		}
+		mux := runtime.NewServeMux(opts...)
+		mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			fmt.Fprintf(w, "name=%s", pathParams["name"])
+		})
+
+		r := httptest.NewRequest("GET", "http://host.example/", nil)
+		r.URL.Path, r.URL.RawPath = spec.path, spec.rawPath
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		if got, want := w.Code, spec.respStatus; got != want {
+			t.Errorf("w.Code = %d; want %d; strict=%t, path=%q", got, want, spec.strict, spec.rawPath)
+		}
+		if spec.respContent != "" {
+			if got, want := w.Body.String(), spec.respContent; got != want {
+				t.Errorf("w.Body = %q; want %q; strict=%t, path=%q", got, want, spec.strict, spec.rawPath)
+			}
+		}
+	}
+}
+
+func TestMuxPathParamRewriter(t *testing.T) {
+	// "/v1/{parent}/objects/{name}"
+	pat := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{
+			int(utilities.OpLitPush), 0,
+			int(utilities.OpPush), 0,
+			int(utilities.OpConcatN), 1,
+			int(utilities.OpCapture), 1,
+			int(utilities.OpLitPush), 2,
+			int(utilities.OpPush), 0,
+			int(utilities.OpConcatN), 1,
+			int(utilities.OpCapture), 3,
+		},
+		[]string{"v1", "parent", "objects", "name"},
+		"",
+	))
+	// The short IDs "s-<id>" of names are expanded into full resource names.
+	rewriter := func(name, value string) (string, error) {
+		if name != "name" || !strings.HasPrefix(value, "s-") {
+			return value, nil
+		}
+		id := strings.TrimPrefix(value, "s-")
+		if id == "" {
+			return "", fmt.Errorf("empty short ID")
+		}
+		return "legacy/objects/" + id, nil
+	}
+
+	for _, spec := range []struct {
+		rewriter runtime.PathParamRewriterFunc
+		path     string
+
+		respStatus  int
+		respContent string
+	}{
+		{rewriter: rewriter, path: "/v1/p/objects/s-42", respStatus: http.StatusOK, respContent: "parent=p name=legacy/objects/42"},
+		{rewriter: rewriter, path: "/v1/p/objects/42", respStatus: http.StatusOK, respContent: "parent=p name=42"},
+		{rewriter: rewriter, path: "/v1/p/objects/s-", respStatus: http.StatusBadRequest},
+		{path: "/v1/p/objects/s-42", respStatus: http.StatusOK, respContent: "parent=p name=s-42"},
+	} {
+		var opts []runtime.ServeMuxOption
+		if spec.rewriter != nil {
+			opts = append(opts, runtime.WithPathParamRewriter(spec.rewriter))
+		}
+		mux := runtime.NewServeMux(opts...)
+		mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			fmt.Fprintf(w, "parent=%s name=%s", pathParams["parent"], pathParams["name"])
+		})
+
+		r := httptest.NewRequest("GET", "http://host.example"+spec.path, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		if got, want := w.Code, spec.respStatus; got != want {
+			t.Errorf("w.Code = %d; want %d; path=%q", got, want, spec.path)
+		}
+		if spec.respContent != "" {
+			if got, want := w.Body.String(), spec.respContent; got != want {
+				t.Errorf("w.Body = %q; want %q; path=%q", got, want, spec.path)
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package runtime
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/longrunning"
+)
+
+// WithOperationLocation returns a ServeMuxOption that responds to the unary methods which return
+// a google.longrunning.Operation which is not done yet with the status 202 Accepted and the header
+// Location, set to the URL at which the client can poll the operation.
+//
+// The URL is pathTemplate with "{name}" replaced by the name of the operation, e.g. with the
+// template "/v1/{name}", the URL of the operation "operations/123" is "/v1/operations/123".
+// The segments of the name are escaped.
+func WithOperationLocation(pathTemplate string) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.operationLocation = pathTemplate
+	}
+}
+
+// handleOperationLocation sets the status and the header Location of the response with the
+// operation resp, if it is one. It returns true if it did.
+func handleOperationLocation(w http.ResponseWriter, mux *ServeMux, resp interface{}) bool {
+	op, ok := resp.(*longrunning.Operation)
+	if !ok || mux.operationLocation == "" || op.GetDone() {
+		return false
+	}
+	segments := strings.Split(op.GetName(), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	w.Header().Set("Location", strings.Replace(mux.operationLocation, "{name}", strings.Join(segments, "/"), -1))
+	w.WriteHeader(http.StatusAccepted)
+	return true
+}
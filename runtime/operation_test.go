@@ -0,0 +1,73 @@
+package runtime_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+	"google.golang.org/genproto/googleapis/longrunning"
+)
+
+func TestWithOperationLocation(t *testing.T) {
+	for _, spec := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		resp    proto.Message
+
+		wantStatus   int
+		wantLocation string
+	}{
+		{
+			name:         "pending operation",
+			muxOpts:      []runtime.ServeMuxOption{runtime.WithOperationLocation("/v1/{name}")},
+			resp:         &longrunning.Operation{Name: "operations/123"},
+			wantStatus:   http.StatusAccepted,
+			wantLocation: "/v1/operations/123",
+		},
+		{
+			name:         "escaped name",
+			muxOpts:      []runtime.ServeMuxOption{runtime.WithOperationLocation("https://api.example.com/v1/{name}:wait")},
+			resp:         &longrunning.Operation{Name: "operations/a b?"},
+			wantStatus:   http.StatusAccepted,
+			wantLocation: "https://api.example.com/v1/operations/a%20b%3F:wait",
+		},
+		{
+			name:       "done operation",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithOperationLocation("/v1/{name}")},
+			resp:       &longrunning.Operation{Name: "operations/123", Done: true},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "other message",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithOperationLocation("/v1/{name}")},
+			resp:       &pb.SimpleMessage{Id: "foo"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "option not set",
+			resp:       &longrunning.Operation{Name: "operations/123"},
+			wantStatus: http.StatusOK,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+			req := httptest.NewRequest("POST", "http://example.com/v1/jobs", nil)
+			w := httptest.NewRecorder()
+			runtime.ForwardResponseMessage(ctx, runtime.NewServeMux(spec.muxOpts...), &runtime.JSONPb{}, w, req, spec.resp)
+
+			if got, want := w.Code, spec.wantStatus; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+			if got, want := w.Header().Get("Location"), spec.wantLocation; got != want {
+				t.Errorf("w.Header().Get(%q) = %q; want %q", "Location", got, want)
+			}
+			if w.Body.Len() == 0 {
+				t.Errorf("w.Body is empty; want the response message")
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package runtime
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/grpclog"
+)
+
+// WithOptionsDiscovery returns a ServeMuxOption that replies to "OPTIONS" requests, for which no
+// handler is registered, with a JSON description of the handlers registered for their path: the
+// allowed methods, which are also listed in the header Allow, and the patterns and message types
+// of the handlers, e.g.
+//
+//	{"methods":["GET"],"routes":[{"method":"GET","pattern":"/v1/{name=messages/*}","requestType":"example.GetMessageRequest","responseType":"example.Message"}]}
+//
+// The message types are those set with WithMessageTypes, if any. CORS preflight requests, which
+// have the header Access-Control-Request-Method, are routed as usual.
+func WithOptionsDiscovery() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.optionsDiscovery = true
+	}
+}
+
+// WithMessageTypes returns a HandlerOption that sets the full names of the request and response
+// messages of the handler, e.g. "example.GetMessageRequest", as described by WithOptionsDiscovery.
+// The generator gives it to the handlers of all methods.
+func WithMessageTypes(request, response string) HandlerOption {
+	return func(o *handlerOptions) {
+		o.requestType = request
+		o.responseType = response
+	}
+}
+
+// RouteDescription describes a handler in the replies of WithOptionsDiscovery.
+type RouteDescription struct {
+	Method       string `json:"method"`
+	Pattern      string `json:"pattern"`
+	RequestType  string `json:"requestType,omitempty"`
+	ResponseType string `json:"responseType,omitempty"`
+}
+
+// serveOptionsDiscovery replies to an "OPTIONS" request with the description of the handlers whose
+// pattern matches the path components and verb. It returns false if there is none.
+func (s *ServeMux) serveOptionsDiscovery(w http.ResponseWriter, components []string, verb string) bool {
+	var methods []string
+	for m := range s.handlers {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+
+	allowed := []string{}
+	routes := []RouteDescription{}
+	for _, m := range methods {
+		matched := false
+		for _, h := range s.handlers[m] {
+			if _, err := h.pat.match(components, verb, s.unescapingMode); err != nil {
+				continue
+			}
+			matched = true
+			routes = append(routes, RouteDescription{
+				Method:       m,
+				Pattern:      h.pat.String(),
+				RequestType:  h.opts.requestType,
+				ResponseType: h.opts.responseType,
+			})
+		}
+		if matched {
+			allowed = append(allowed, m)
+		}
+	}
+	if len(routes) == 0 {
+		return false
+	}
+
+	body, err := json.Marshal(struct {
+		Methods []string           `json:"methods"`
+		Routes  []RouteDescription `json:"routes"`
+	}{allowed, routes})
+	if err != nil {
+		grpclog.Errorf("Failed to marshal the routes: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return true
+	}
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+	return true
+}
@@ -0,0 +1,97 @@
+package runtime_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+)
+
+func TestMuxOptionsDiscovery(t *testing.T) {
+	// "/v1/messages/{id}"
+	pat := runtime.MustPattern(runtime.NewPattern(1, []int{
+		int(utilities.OpLitPush), 0,
+		int(utilities.OpLitPush), 1,
+		int(utilities.OpPush), 0,
+		int(utilities.OpConcatN), 1,
+		int(utilities.OpCapture), 2,
+	}, []string{"v1", "messages", "id"}, ""))
+	handler := func(w http.ResponseWriter, r *http.Request, _ map[string]string) {}
+
+	for _, spec := range []struct {
+		name      string
+		discovery bool
+		path      string
+		headers   map[string]string
+
+		wantStatus int
+		wantAllow  string
+		wantBody   string
+	}{
+		{
+			name:       "registered route",
+			discovery:  true,
+			path:       "/v1/messages/1",
+			wantStatus: http.StatusOK,
+			wantAllow:  "DELETE, GET",
+			wantBody: `{"methods":["DELETE","GET"],"routes":[` +
+				`{"method":"DELETE","pattern":"/v1/messages/{id=*}"},` +
+				`{"method":"GET","pattern":"/v1/messages/{id=*}","requestType":"example.GetMessageRequest","responseType":"example.Message"}]}`,
+		},
+		{
+			name:       "unknown route",
+			discovery:  true,
+			path:       "/v1/other",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "CORS preflight",
+			discovery:  true,
+			path:       "/v1/messages/1",
+			headers:    map[string]string{"Access-Control-Request-Method": "GET"},
+			wantStatus: http.StatusMethodNotAllowed,
+			wantAllow:  "DELETE, GET",
+		},
+		{
+			name:       "no discovery",
+			path:       "/v1/messages/1",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantAllow:  "DELETE, GET",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			var opts []runtime.ServeMuxOption
+			if spec.discovery {
+				opts = append(opts, runtime.WithOptionsDiscovery())
+			}
+			mux := runtime.NewServeMux(opts...)
+			mux.Handle("GET", pat, handler, runtime.WithMessageTypes("example.GetMessageRequest", "example.Message"))
+			mux.Handle("DELETE", pat, handler)
+
+			r := httptest.NewRequest("OPTIONS", "http://example.com"+spec.path, nil)
+			for k, v := range spec.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.wantStatus; got != want {
+				t.Fatalf("w.Code = %d; want %d", got, want)
+			}
+			if got, want := w.Header().Get("Allow"), spec.wantAllow; got != want {
+				t.Errorf("Allow = %q; want %q", got, want)
+			}
+			if spec.wantBody == "" {
+				return
+			}
+			if got, want := w.Header().Get("Content-Type"), "application/json"; got != want {
+				t.Errorf("Content-Type = %q; want %q", got, want)
+			}
+			if got, want := w.Body.String(), spec.wantBody; got != want {
+				t.Errorf("w.Body = %s; want %s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// PageTokenParameter is the query parameter which holds the page token in the Link headers
+// written with WithPaginationLinks.
+const PageTokenParameter = "page_token"
+
+// PaginationLinksFunc returns the page tokens of the pages next to and previous to the page
+// msg, the response to r. An empty token means there is no such page.
+type PaginationLinksFunc func(ctx context.Context, r *http.Request, msg proto.Message) (next, prev string)
+
+// WithPaginationLinks returns a ServeMuxOption that writes the Link headers (RFC 5988) of the
+// pages next to and previous to the responses forwarded by ForwardResponseMessage, with the page
+// tokens returned by fn, e.g. the next_page_token of a list response. The links are the URL of
+// the request with its PageTokenParameter set to the token, such as
+// `</v1/books?page_size=10&page_token=abc>; rel="next"`.
+func WithPaginationLinks(fn PaginationLinksFunc) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.paginationLinks = fn
+	}
+}
+
+// handlePaginationLinks writes the Link headers of the pages next to and previous to resp.
+func handlePaginationLinks(ctx context.Context, w http.ResponseWriter, r *http.Request, mux *ServeMux, resp proto.Message) {
+	next, prev := mux.paginationLinks(ctx, r, resp)
+	for _, link := range []struct {
+		rel, token string
+	}{
+		{rel: "next", token: next},
+		{rel: "prev", token: prev},
+	} {
+		if link.token == "" {
+			continue
+		}
+		u := *r.URL
+		q := u.Query()
+		q.Set(PageTokenParameter, link.token)
+		u.RawQuery = q.Encode()
+		w.Header().Add("Link", fmt.Sprintf("<%s>; rel=%q", u.RequestURI(), link.rel))
+	}
+}
@@ -0,0 +1,63 @@
+package runtime_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+)
+
+func TestWithPaginationLinks(t *testing.T) {
+	for _, spec := range []struct {
+		name      string
+		url       string
+		next      string
+		prev      string
+		wantLinks []string
+	}{
+		{
+			name:      "next page",
+			url:       "http://example.com/v1/books?page_size=10",
+			next:      "abc",
+			wantLinks: []string{`</v1/books?page_size=10&page_token=abc>; rel="next"`},
+		},
+		{
+			name: "next and previous pages",
+			url:  "http://example.com/v1/books?page_size=10&page_token=abc",
+			next: "def",
+			prev: "a b",
+			wantLinks: []string{
+				`</v1/books?page_size=10&page_token=def>; rel="next"`,
+				`</v1/books?page_size=10&page_token=a+b>; rel="prev"`,
+			},
+		},
+		{
+			name: "last page",
+			url:  "http://example.com/v1/books",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			var got proto.Message
+			mux := runtime.NewServeMux(runtime.WithPaginationLinks(func(ctx context.Context, r *http.Request, msg proto.Message) (string, string) {
+				got = msg
+				return spec.next, spec.prev
+			}))
+			msg := &pb.SimpleMessage{Id: "foo"}
+			ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+			w := httptest.NewRecorder()
+			runtime.ForwardResponseMessage(ctx, mux, &runtime.JSONPb{}, w, httptest.NewRequest("GET", spec.url, nil), msg)
+
+			if got != msg {
+				t.Errorf("pagination links called with %v; want %v", got, msg)
+			}
+			if links := w.Header()["Link"]; !reflect.DeepEqual(links, spec.wantLinks) {
+				t.Errorf("Link headers = %q; want %q", links, spec.wantLinks)
+			}
+		})
+	}
+}
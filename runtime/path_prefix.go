@@ -0,0 +1,57 @@
+package runtime
+
+import (
+	"net/http"
+	"strings"
+)
+
+// PathPrefixFunc returns the base path of r, e.g. the one of a tenant, or "" if it has none.
+type PathPrefixFunc func(r *http.Request) string
+
+// WithDynamicPathPrefix returns a ServeMuxOption that strips the base path returned by fn from the
+// path of each request before it is routed, e.g. "/tenants/acme" derived from a header set by a
+// tenant-specific reverse proxy, so that "/tenants/acme/v1/items" is routed as "/v1/items". The
+// path is kept as is when fn returns "". Requests whose path is not under the base path are
+// replied with "404 Not Found". As with http.StripPrefix, the handlers see the stripped path.
+func WithDynamicPathPrefix(fn PathPrefixFunc) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.pathPrefix = fn
+	}
+}
+
+// stripPathPrefix returns r with the base path returned by the PathPrefixFunc stripped from its
+// path. It returns false if the path is not under the base path.
+func (s *ServeMux) stripPathPrefix(r *http.Request) (*http.Request, bool) {
+	prefix := strings.TrimSuffix(s.pathPrefix(r), "/")
+	if prefix == "" {
+		return r, true
+	}
+	path, ok := trimPathPrefix(r.URL.Path, prefix)
+	if !ok {
+		return r, false
+	}
+	// The escaped path is derived from the stripped one if prefix is escaped otherwise in it.
+	rawPath, _ := trimPathPrefix(r.URL.RawPath, prefix)
+
+	r2 := r.WithContext(r.Context())
+	u := *r.URL
+	u.Path, u.RawPath = path, rawPath
+	r2.URL = &u
+	return r2, true
+}
+
+// trimPathPrefix returns path without prefix, which has no trailing slash. It returns false if
+// prefix is not made of whole segments of path.
+func trimPathPrefix(path, prefix string) (string, bool) {
+	if !strings.HasPrefix(path, prefix) {
+		return "", false
+	}
+	rest := path[len(prefix):]
+	switch {
+	case rest == "":
+		return "/", true
+	case rest[0] != '/':
+		return "", false
+	}
+	return rest, true
+}
@@ -0,0 +1,110 @@
+package runtime_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+)
+
+func TestWithDynamicPathPrefix(t *testing.T) {
+	tenantPrefix := func(r *http.Request) string {
+		if tenant := r.Header.Get("X-Tenant"); tenant != "" {
+			return "/tenants/" + tenant
+		}
+		return ""
+	}
+	for _, spec := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		tenant  string
+		path    string
+
+		wantCode int
+		wantPath string
+		wantID   string
+	}{
+		{
+			name:     "prefix stripped",
+			tenant:   "acme",
+			path:     "/tenants/acme/v1/items/1",
+			wantCode: http.StatusOK,
+			wantPath: "/v1/items/1",
+			wantID:   "1",
+		},
+		{
+			name:     "escaped path",
+			muxOpts:  []runtime.ServeMuxOption{runtime.WithUnescapingMode(runtime.UnescapingModeAllCharacters)},
+			tenant:   "acme",
+			path:     "/tenants/acme/v1/items/a%2Fb",
+			wantCode: http.StatusOK,
+			wantPath: "/v1/items/a/b",
+			wantID:   "a/b",
+		},
+		{
+			name:     "no prefix",
+			path:     "/v1/items/1",
+			wantCode: http.StatusOK,
+			wantPath: "/v1/items/1",
+			wantID:   "1",
+		},
+		{
+			name:     "other tenant",
+			tenant:   "acme",
+			path:     "/tenants/other/v1/items/1",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "prefix of a segment",
+			tenant:   "acme",
+			path:     "/tenants/acmecorp/v1/items/1",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "path without prefix",
+			tenant:   "acme",
+			path:     "/v1/items/1",
+			wantCode: http.StatusNotFound,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(append(spec.muxOpts, runtime.WithDynamicPathPrefix(tenantPrefix))...)
+			// "/v1/items/{id}"
+			pat := runtime.MustPattern(runtime.NewPattern(
+				1,
+				[]int{
+					int(utilities.OpLitPush), 0,
+					int(utilities.OpLitPush), 1,
+					int(utilities.OpPush), 0,
+					int(utilities.OpConcatN), 1,
+					int(utilities.OpCapture), 2,
+				},
+				[]string{"v1", "items", "id"},
+				"",
+			))
+			var gotPath, gotID string
+			mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+				gotPath, gotID = r.URL.Path, pathParams["id"]
+			})
+
+			r := httptest.NewRequest("GET", "http://example.com"+spec.path, nil)
+			if spec.tenant != "" {
+				r.Header.Set("X-Tenant", spec.tenant)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.wantCode; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+			if gotPath != spec.wantPath {
+				t.Errorf("r.URL.Path = %q; want %q", gotPath, spec.wantPath)
+			}
+			if gotID != spec.wantID {
+				t.Errorf("pathParams[\"id\"] = %q; want %q", gotID, spec.wantID)
+			}
+		})
+	}
+}
@@ -161,6 +161,12 @@ func MustPattern(p Pattern, err error) Pattern {
 // If it matches, the function returns a mapping from field paths to their captured values.
 // If otherwise, the function returns an error.
 func (p Pattern) Match(components []string, verb string) (map[string]string, error) {
+	return p.match(components, verb, UnescapingModeLegacy)
+}
+
+// match is Match for components which are still escaped, unless mode is UnescapingModeLegacy.
+// The captured values are unescaped according to mode.
+func (p Pattern) match(components []string, verb string, mode UnescapingMode) (map[string]string, error) {
 	if p.verb != verb {
 		if p.assumeColonVerb || p.verb != "" {
 			return nil, ErrNotMatch
@@ -191,6 +197,11 @@ func (p Pattern) Match(components []string, verb string) (map[string]string, err
 				if lit := p.pool[op.operand]; c != lit {
 					return nil, ErrNotMatch
 				}
+			} else {
+				var err error
+				if c, err = unescape(c, mode, false); err != nil {
+					return nil, err
+				}
 			}
 			stack = append(stack, c)
 			pos++
@@ -200,7 +211,11 @@ func (p Pattern) Match(components []string, verb string) (map[string]string, err
 				return nil, ErrNotMatch
 			}
 			end -= p.tailLen
-			stack = append(stack, strings.Join(components[pos:end], "/"))
+			c, err := unescape(strings.Join(components[pos:end], "/"), mode, true)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, c)
 			pos = end
 		case utilities.OpConcatN:
 			n := op.operand
@@ -222,6 +237,17 @@ func (p Pattern) Match(components []string, verb string) (map[string]string, err
 	return bindings, nil
 }
 
+// matchIgnoringVerb is match with the verb of p instead of the verb of the path. It fails for
+// the patterns without a verb which do not assume a colon verb, since a colon in the last
+// segment is no verb syntax for them and the path cannot be matched without its verb.
+func (p Pattern) matchIgnoringVerb(components []string, mode UnescapingMode) bool {
+	if p.verb == "" && !p.assumeColonVerb {
+		return false
+	}
+	_, err := p.match(components, p.verb, mode)
+	return err == nil
+}
+
 // Verb returns the verb part of the Pattern.
 func (p Pattern) Verb() string { return p.verb }
 
@@ -260,3 +286,63 @@ func AssumeColonVerbOpt(val bool) PatternOpt {
 		o.assumeColonVerb = val
 	})
 }
+
+// unescape unescapes the path segments s, as captured by a single-segment wildcard, or by
+// a multi-segment one if multisegment is true, according to mode.
+func unescape(s string, mode UnescapingMode, multisegment bool) (string, error) {
+	if mode == UnescapingModeLegacy || !strings.Contains(s, "%") {
+		return s, nil
+	}
+	if !multisegment {
+		// An escaped slash in a single segment can only be part of the value.
+		mode = UnescapingModeAllCharacters
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			return "", fmt.Errorf("invalid URL escape in %q", s)
+		}
+		c := unhex(s[i+1])<<4 | unhex(s[i+2])
+		if shouldUnescape(c, mode) {
+			b.WriteByte(c)
+		} else {
+			b.WriteString(s[i : i+3])
+		}
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// shouldUnescape tells whether the escaped character c is unescaped in mode.
+func shouldUnescape(c byte, mode UnescapingMode) bool {
+	switch mode {
+	case UnescapingModeAllExceptReserved:
+		// Reserved characters of RFC 6570.
+		return !strings.ContainsRune(":/?#[]@!$&'()*+,;=", rune(c))
+	case UnescapingModeAllExceptSlash:
+		return c != '/'
+	default:
+		return true
+	}
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
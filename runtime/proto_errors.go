@@ -41,6 +41,12 @@ func DefaultHTTPProtoErrorHandler(ctx context.Context, mux *ServeMux, marshaler
 		s = status.New(codes.Unknown, err.Error())
 	}
 
+	md, ok := ServerMetadataFromContext(ctx)
+	if !ok {
+		grpclog.Infof("Failed to extract ServerMetadata from context")
+	}
+	setStatusDetailsHeader(w, s)
+
 	w.Header().Del("Trailer")
 
 	contentType := marshaler.ContentType()
@@ -53,9 +59,9 @@ func DefaultHTTPProtoErrorHandler(ctx context.Context, mux *ServeMux, marshaler
 	}
 	w.Header().Set("Content-Type", contentType)
 
-	buf, merr := marshaler.Marshal(s.Proto())
+	body, buf, merr := mux.marshalStatusBody(ctx, marshaler, s, s.Proto())
 	if merr != nil {
-		grpclog.Infof("Failed to marshal error message %q: %v", s.Proto(), merr)
+		grpclog.Infof("Failed to marshal error message %q: %v", body, merr)
 		w.WriteHeader(http.StatusInternalServerError)
 		if _, err := io.WriteString(w, fallback); err != nil {
 			grpclog.Infof("Failed to write response: %v", err)
@@ -63,14 +69,11 @@ func DefaultHTTPProtoErrorHandler(ctx context.Context, mux *ServeMux, marshaler
 		return
 	}
 
-	md, ok := ServerMetadataFromContext(ctx)
-	if !ok {
-		grpclog.Infof("Failed to extract ServerMetadata from context")
-	}
-
 	handleForwardResponseServerMetadata(w, mux, md)
+	md = mux.limitTrailers(md)
 	handleForwardResponseTrailerHeader(w, md)
-	st := HTTPStatusFromCode(s.Code())
+	mux.handleGRPCMessageHeader(w, s)
+	st := mux.httpStatus(s)
 	w.WriteHeader(st)
 	if _, err := w.Write(buf); err != nil {
 		grpclog.Infof("Failed to write response: %v", err)
@@ -17,7 +17,7 @@ import (
 
 var valuesKeyRegexp = regexp.MustCompile("^(.*)\\[(.*)\\]$")
 
-var currentQueryParser QueryParameterParser = &defaultQueryParser{}
+var currentQueryParser QueryParameterParser = &DefaultQueryParser{}
 
 // QueryParameterParser defines interface for all query parameter parsers
 type QueryParameterParser interface {
@@ -30,11 +30,15 @@ func PopulateQueryParameters(msg proto.Message, values url.Values, filter *utili
 	return currentQueryParser.Parse(msg, values, filter)
 }
 
-type defaultQueryParser struct{}
+// DefaultQueryParser is the QueryParameterParser used by default. The keys of the parameters are
+// the paths of the fields, like "nested.field", and repeated fields are given by repeating the
+// parameter, like "ids=1&ids=2". Well-known types, like google.protobuf.Timestamp, are parsed
+// from their JSON representation.
+type DefaultQueryParser struct{}
 
 // Parse populates "values" into "msg".
 // A value is ignored if its key starts with one of the elements in "filter".
-func (*defaultQueryParser) Parse(msg proto.Message, values url.Values, filter *utilities.DoubleArray) error {
+func (*DefaultQueryParser) Parse(msg proto.Message, values url.Values, filter *utilities.DoubleArray) error {
 	for key, values := range values {
 		match := valuesKeyRegexp.FindStringSubmatch(key)
 		if len(match) == 3 {
@@ -134,11 +138,16 @@ func fieldByProtoName(m reflect.Value, name string) (reflect.Value, *proto.Prope
 	// look up field name in oneof map
 	for _, op := range props.OneofTypes {
 		if name == op.Prop.OrigName || name == op.Prop.JSONName {
-			v := reflect.New(op.Type.Elem())
 			field := m.Field(op.Field)
 			if !field.IsNil() {
+				// Setting the same member again, e.g. through another of
+				// its names or through its nested fields, updates it.
+				if field.Elem().Type() == op.Type {
+					return field.Elem().Elem().Field(0), op.Prop, nil
+				}
 				return reflect.Value{}, nil, fmt.Errorf("field already set for %s oneof", props.Prop[op.Field].OrigName)
 			}
+			v := reflect.New(op.Type.Elem())
 			field.Set(v)
 			return v.Elem().Field(0), op.Prop, nil
 		}
@@ -202,13 +211,16 @@ func populateRepeatedField(f reflect.Value, values []string, props *proto.Proper
 	if !ok {
 		return fmt.Errorf("unsupported field type %s", elemType)
 	}
-	f.Set(reflect.MakeSlice(f.Type(), len(values), len(values)).Convert(f.Type()))
+	// The values are appended, since several parameters can name the same field, e.g. by its
+	// proto and its JSON name.
+	n := f.Len()
+	f.Set(reflect.AppendSlice(f, reflect.MakeSlice(f.Type(), len(values), len(values))))
 	for i, v := range values {
 		result := conv.Call([]reflect.Value{reflect.ValueOf(v)})
 		if err := result[1].Interface(); err != nil {
 			return err.(error)
 		}
-		f.Index(i).Set(result[0].Convert(f.Index(i).Type()))
+		f.Index(n + i).Set(result[0].Convert(f.Index(n + i).Type()))
 	}
 	return nil
 }
@@ -380,13 +392,14 @@ func populateFieldEnum(f reflect.Value, value string, enumValMap map[string]int3
 
 func populateFieldEnumRepeated(f reflect.Value, values []string, enumValMap map[string]int32) error {
 	elemType := f.Type().Elem()
-	f.Set(reflect.MakeSlice(f.Type(), len(values), len(values)).Convert(f.Type()))
+	n := f.Len()
+	f.Set(reflect.AppendSlice(f, reflect.MakeSlice(f.Type(), len(values), len(values))))
 	for i, v := range values {
 		result, err := convertEnum(v, elemType, enumValMap)
 		if err != nil {
 			return err
 		}
-		f.Index(i).Set(result)
+		f.Index(n + i).Set(result)
 	}
 	return nil
 }
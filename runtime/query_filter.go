@@ -0,0 +1,178 @@
+package runtime
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/grpclog"
+	"google.golang.org/grpc/status"
+)
+
+// queryFieldFilter restricts the fields which can be set through query parameters.
+type queryFieldFilter struct {
+	allow  [][]string
+	deny   [][]string
+	reject bool
+}
+
+// WithQueryFieldAllowlist returns a ServeMuxOption that only lets query parameters set the
+// fields in paths, given as dot-separated field paths like "user.name". A path also allows
+// the fields nested in it. Other query parameters are ignored, or rejected if
+// WithRejectDisallowedQueryFields is also given.
+//
+// Paths are compared ignoring case and underscores, so that "user.display_name" matches both the
+// proto name and the JSON name "user.displayName" of the field.
+func WithQueryFieldAllowlist(paths ...string) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		f := serveMux.queryFieldFilter()
+		f.allow = append(f.allow, normalizeQueryFieldPaths(paths)...)
+	}
+}
+
+// WithQueryFieldDenylist returns a ServeMuxOption that prevents query parameters from setting
+// the fields in paths, or the fields nested in them, even if they are in the allowlist.
+// Such query parameters are ignored, or rejected if WithRejectDisallowedQueryFields is also given.
+// Paths are given and compared as for WithQueryFieldAllowlist.
+func WithQueryFieldDenylist(paths ...string) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		f := serveMux.queryFieldFilter()
+		f.deny = append(f.deny, normalizeQueryFieldPaths(paths)...)
+	}
+}
+
+// WithRejectDisallowedQueryFields returns a ServeMuxOption that rejects requests with query
+// parameters which are not allowed by WithQueryFieldAllowlist or WithQueryFieldDenylist with an
+// "InvalidArgument" error, instead of ignoring those parameters.
+func WithRejectDisallowedQueryFields() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.queryFieldFilter().reject = true
+	}
+}
+
+// WithMaxQueryParams returns a ServeMuxOption that rejects requests with more than n distinct
+// query parameters with "400 Bad Request" and an "InvalidArgument" error, before the request
+// message is populated from them, to guard against requests with huge numbers of parameters.
+// A parameter given several times counts once. The limit of 0, the default, means no limit.
+func WithMaxQueryParams(n int) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.maxQueryParams = n
+	}
+}
+
+// checkQueryParams returns an "InvalidArgument" error if r has more query parameters than the
+// limit set by WithMaxQueryParams. The form of r is counted instead if it has already been parsed.
+func (s *ServeMux) checkQueryParams(r *http.Request) error {
+	if s.maxQueryParams <= 0 {
+		return nil
+	}
+	values := r.Form
+	if values == nil {
+		values = r.URL.Query()
+	}
+	if n := len(values); n > s.maxQueryParams {
+		return status.Errorf(codes.InvalidArgument, "too many query parameters: %d, the limit is %d", n, s.maxQueryParams)
+	}
+	return nil
+}
+
+func (s *ServeMux) queryFieldFilter() *queryFieldFilter {
+	if s.queryFields == nil {
+		s.queryFields = &queryFieldFilter{}
+	}
+	return s.queryFields
+}
+
+// filterRequest returns r without its disallowed query parameters, or an error if such
+// parameters must be rejected. The form of r is filtered too if it has already been parsed.
+func (f *queryFieldFilter) filterRequest(r *http.Request) (*http.Request, error) {
+	query, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		// Left to the handler, which reports the malformed query.
+		return r, nil
+	}
+	removed, err := f.filterValues(query)
+	if err != nil {
+		return nil, err
+	}
+	formRemoved := false
+	if r.Form != nil {
+		if formRemoved, err = f.filterValues(r.Form); err != nil {
+			return nil, err
+		}
+	}
+	if !removed && !formRemoved {
+		return r, nil
+	}
+
+	r2 := r.WithContext(r.Context())
+	u := *r.URL
+	u.RawQuery = query.Encode()
+	r2.URL = &u
+	return r2, nil
+}
+
+// filterValues removes the disallowed keys of values and returns true if any was removed.
+func (f *queryFieldFilter) filterValues(values url.Values) (bool, error) {
+	removed := false
+	for key := range values {
+		if f.allowed(key) {
+			continue
+		}
+		if f.reject {
+			return false, status.Errorf(codes.InvalidArgument, "query parameter %q is not allowed", key)
+		}
+		grpclog.Infof("ignoring disallowed query parameter %q", key)
+		delete(values, key)
+		removed = true
+	}
+	return removed, nil
+}
+
+func (f *queryFieldFilter) allowed(key string) bool {
+	// Map entries are given as "field[key]".
+	if match := valuesKeyRegexp.FindStringSubmatch(key); len(match) == 3 {
+		key = match[1]
+	}
+	path := normalizeQueryFieldPath(key)
+	if len(f.allow) > 0 && !hasQueryFieldPrefix(path, f.allow) {
+		return false
+	}
+	return !hasQueryFieldPrefix(path, f.deny)
+}
+
+func hasQueryFieldPrefix(path []string, prefixes [][]string) bool {
+	for _, prefix := range prefixes {
+		if len(prefix) > len(path) {
+			continue
+		}
+		matched := true
+		for i := range prefix {
+			if prefix[i] != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeQueryFieldPaths(paths []string) [][]string {
+	normalized := make([][]string, 0, len(paths))
+	for _, p := range paths {
+		normalized = append(normalized, normalizeQueryFieldPath(p))
+	}
+	return normalized
+}
+
+func normalizeQueryFieldPath(path string) []string {
+	segments := strings.Split(path, ".")
+	for i, s := range segments {
+		segments[i] = strings.ToLower(strings.Replace(s, "_", "", -1))
+	}
+	return segments
+}
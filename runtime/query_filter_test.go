@@ -0,0 +1,187 @@
+package runtime_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+)
+
+func TestQueryFieldFilter(t *testing.T) {
+	for _, spec := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		method  string
+		query   string
+		form    string
+
+		respStatus int
+		want       url.Values
+	}{
+		{
+			name:       "no filter",
+			query:      "name=foo&is_admin=true",
+			respStatus: http.StatusOK,
+			want:       url.Values{"name": {"foo"}, "is_admin": {"true"}},
+		},
+		{
+			name:       "denied field ignored",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithQueryFieldDenylist("is_admin")},
+			query:      "name=foo&is_admin=true",
+			respStatus: http.StatusOK,
+			want:       url.Values{"name": {"foo"}},
+		},
+		{
+			name:       "denied field by JSON name",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithQueryFieldDenylist("is_admin")},
+			query:      "name=foo&isAdmin=true",
+			respStatus: http.StatusOK,
+			want:       url.Values{"name": {"foo"}},
+		},
+		{
+			name:       "denied nested field",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithQueryFieldDenylist("user")},
+			query:      "name=foo&user.is_admin=true&user.roles[admin]=true",
+			respStatus: http.StatusOK,
+			want:       url.Values{"name": {"foo"}},
+		},
+		{
+			name:       "denied field rejected",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithQueryFieldDenylist("is_admin"), runtime.WithRejectDisallowedQueryFields()},
+			query:      "name=foo&is_admin=true",
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "allowed fields",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithQueryFieldAllowlist("name", "filter")},
+			query:      "name=foo&filter.page_size=10&is_admin=true",
+			respStatus: http.StatusOK,
+			want:       url.Values{"name": {"foo"}, "filter.page_size": {"10"}},
+		},
+		{
+			name:       "allowed fields rejected",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithQueryFieldAllowlist("name"), runtime.WithRejectDisallowedQueryFields()},
+			query:      "name=foo&is_admin=true",
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "allowed fields only",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithQueryFieldAllowlist("name"), runtime.WithRejectDisallowedQueryFields()},
+			query:      "name=foo",
+			respStatus: http.StatusOK,
+			want:       url.Values{"name": {"foo"}},
+		},
+		{
+			name:       "deny overrides allow",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithQueryFieldAllowlist("user"), runtime.WithQueryFieldDenylist("user.is_admin")},
+			query:      "user.name=foo&user.is_admin=true",
+			respStatus: http.StatusOK,
+			want:       url.Values{"user.name": {"foo"}},
+		},
+		{
+			name:       "path length fallback form",
+			muxOpts:    []runtime.ServeMuxOption{runtime.WithQueryFieldDenylist("is_admin")},
+			method:     "POST",
+			form:       "name=foo&is_admin=true",
+			respStatus: http.StatusOK,
+			want:       url.Values{"name": {"foo"}},
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.muxOpts...)
+			var got url.Values
+			pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+			mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				if err := r.ParseForm(); err != nil {
+					t.Errorf("r.ParseForm() failed with %v; want success", err)
+				}
+				got = r.Form
+			})
+
+			var r *http.Request
+			if spec.method == "POST" {
+				r = httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader(spec.form))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				r = httptest.NewRequest("GET", "http://example.com/foo?"+spec.query, nil)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.respStatus; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+			if spec.want != nil && !reflect.DeepEqual(got, spec.want) {
+				t.Errorf("r.Form = %v; want %v", got, spec.want)
+			}
+		})
+	}
+}
+
+func TestWithMaxQueryParams(t *testing.T) {
+	for _, spec := range []struct {
+		name   string
+		limit  int
+		method string
+		query  string
+		form   string
+
+		respStatus int
+	}{
+		{
+			name:       "no limit",
+			query:      "a=1&b=2&c=3",
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "at the limit",
+			limit:      2,
+			query:      "a=1&b=2",
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "repeated parameter counted once",
+			limit:      2,
+			query:      "a=1&a=2&b=3",
+			respStatus: http.StatusOK,
+		},
+		{
+			name:       "beyond the limit",
+			limit:      2,
+			query:      "a=1&b=2&c=3",
+			respStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "path length fallback form beyond the limit",
+			limit:      2,
+			method:     "POST",
+			form:       "a=1&b=2&c=3",
+			respStatus: http.StatusBadRequest,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(runtime.WithMaxQueryParams(spec.limit))
+			pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+			mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {})
+
+			var r *http.Request
+			if spec.method == "POST" {
+				r = httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader(spec.form))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				r = httptest.NewRequest("GET", "http://example.com/foo?"+spec.query, nil)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.respStatus; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+		})
+	}
+}
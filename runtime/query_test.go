@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -399,6 +401,17 @@ func TestPopulateParameters(t *testing.T) {
 			want:    &proto3Message{},
 			wanterr: errors.New("field already set for oneof_value oneof"),
 		},
+		{
+			// Setting the same oneof member by both of its names is allowed
+			values: url.Values{
+				"oneof_string_value": {"foobar"},
+				"oneofStringValue":   {"foobar"},
+			},
+			filter: utilities.NewDoubleArray(nil),
+			want: &proto3Message{
+				OneofValue: &proto3Message_OneofStringValue{"foobar"},
+			},
+		},
 	} {
 		msg := proto.Clone(spec.want)
 		msg.Reset()
@@ -420,6 +433,59 @@ func TestPopulateParameters(t *testing.T) {
 	}
 }
 
+func TestPopulateParametersAccumulatesNestedRepeatedFields(t *testing.T) {
+	for _, spec := range []struct {
+		name   string
+		values url.Values
+		field  func(msg *proto3Message) []string
+		want   []string
+	}{
+		{
+			name:   "repeated parameter",
+			values: url.Values{"nested.repeated_value": {"A", "B"}},
+			field:  func(msg *proto3Message) []string { return msg.Nested.RepeatedValue },
+			want:   []string{"A", "B"},
+		},
+		{
+			name:   "proto and JSON names",
+			values: url.Values{"nested.repeated_value": {"A"}, "nested.repeatedValue": {"B", "C"}},
+			field:  func(msg *proto3Message) []string { return msg.Nested.RepeatedValue },
+			want:   []string{"A", "B", "C"},
+		},
+		{
+			name:   "deeply nested",
+			values: url.Values{"nested.nested.nested.repeated_value": {"A"}, "nested.nested.nested.repeatedValue": {"B"}},
+			field:  func(msg *proto3Message) []string { return msg.Nested.Nested.Nested.RepeatedValue },
+			want:   []string{"A", "B"},
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			msg := &proto3Message{}
+			if err := runtime.PopulateQueryParameters(msg, spec.values, utilities.NewDoubleArray(nil)); err != nil {
+				t.Fatalf("runtime.PopulateQueryParameters(msg, %v, nil) failed with %v; want success", spec.values, err)
+			}
+			got := append([]string(nil), spec.field(msg)...)
+			// The order of the values of different parameters is not defined.
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, spec.want) {
+				t.Errorf("repeated field = %q; want %q", got, spec.want)
+			}
+		})
+	}
+}
+
+func TestPopulateFieldFromPathAccumulatesRepeatedEnums(t *testing.T) {
+	msg := &proto3Message{}
+	for _, v := range []string{"EnumValue_Y", "EnumValue_Z"} {
+		if err := runtime.PopulateFieldFromPath(msg, "nested.repeated_enum", v); err != nil {
+			t.Fatalf("runtime.PopulateFieldFromPath(msg, %q, %q) failed with %v; want success", "nested.repeated_enum", v, err)
+		}
+	}
+	if got, want := msg.Nested.RepeatedEnum, []EnumValue{EnumValue_Y, EnumValue_Z}; !reflect.DeepEqual(got, want) {
+		t.Errorf("nested.repeated_enum = %v; want %v", got, want)
+	}
+}
+
 func TestPopulateParametersWithNativeTypes(t *testing.T) {
 	timeT := time.Date(2016, time.December, 15, 12, 23, 32, 49, time.UTC)
 	timeStr := timeT.Format(time.RFC3339Nano)
@@ -648,6 +714,73 @@ func TestPopulateQueryParametersWithInvalidNestedParameters(t *testing.T) {
 	}
 }
 
+// commaQueryParser parses the comma-separated values of repeated_value and delegates the other
+// parameters to the default parser.
+type commaQueryParser struct {
+	runtime.DefaultQueryParser
+}
+
+func (p *commaQueryParser) Parse(msg proto.Message, values url.Values, filter *utilities.DoubleArray) error {
+	rest := make(url.Values)
+	for key, vs := range values {
+		if key != "repeated_value" {
+			rest[key] = vs
+			continue
+		}
+		for _, v := range vs {
+			rest[key] = append(rest[key], strings.Split(v, ",")...)
+		}
+	}
+	return p.DefaultQueryParser.Parse(msg, rest, filter)
+}
+
+func TestSetQueryParameterParser(t *testing.T) {
+	runtime.NewServeMux(runtime.SetQueryParameterParser(&commaQueryParser{}))
+	defer runtime.NewServeMux(runtime.SetQueryParameterParser(&runtime.DefaultQueryParser{}))
+
+	timeT := time.Date(2016, time.December, 15, 12, 23, 32, 49, time.UTC)
+	timePb, err := ptypes.TimestampProto(timeT)
+	if err != nil {
+		t.Fatalf("Couldn't setup timestamp in Protobuf format: %v", err)
+	}
+	for _, spec := range []struct {
+		values url.Values
+		filter *utilities.DoubleArray
+		want   proto.Message
+	}{
+		{
+			values: url.Values{
+				"repeated_value":  {"a,b", "c"},
+				"timestamp_value": {timeT.Format(time.RFC3339Nano)},
+			},
+			filter: utilities.NewDoubleArray(nil),
+			want: &proto3Message{
+				RepeatedValue:  []string{"a", "b", "c"},
+				TimestampValue: timePb,
+			},
+		},
+		{
+			values: url.Values{
+				"repeated_value": {"a,b"},
+				"string_value":   {"str"},
+			},
+			filter: utilities.NewDoubleArray([][]string{{"repeated_value"}}),
+			want: &proto3Message{
+				StringValue: "str",
+			},
+		},
+	} {
+		msg := &proto3Message{}
+		if err := runtime.PopulateQueryParameters(msg, spec.values, spec.filter); err != nil {
+			t.Errorf("runtime.PopulateQueryParameters(msg, %v, %v) failed with %v; want success", spec.values, spec.filter, err)
+			continue
+		}
+		if got, want := msg, spec.want; !proto.Equal(got, want) {
+			t.Errorf("runtime.PopulateQueryParameters(msg, %v, %v) = %v; want %v", spec.values, spec.filter, got, want)
+		}
+	}
+}
+
 type proto3Message struct {
 	Nested             *proto2Message           `protobuf:"bytes,1,opt,name=nested,json=nested" json:"nested,omitempty"`
 	NestedNonNull      proto2Message            `protobuf:"bytes,15,opt,name=nested_non_null,json=nestedNonNull" json:"nested_non_null,omitempty"`
@@ -0,0 +1,94 @@
+package runtime
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// RequestIDHeader is the default HTTP header carrying request IDs.
+const RequestIDHeader = "X-Request-Id"
+
+// RequestIDFormatter returns the value of the response header which echoes the request ID id.
+type RequestIDFormatter func(id string) string
+
+type requestIDOptions struct {
+	header string
+	format RequestIDFormatter
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a ServeMuxOption that assigns an ID to every request. The ID is read
+// from the given header, or RequestIDHeader if header is empty, and a new random ID is generated
+// if the request does not have one.
+//
+// The ID is passed to the gRPC server as metadata named after the lowercased header, and it is
+// echoed to the client in the response header, in the form produced by format. A nil format
+// echoes the raw ID; TraceparentRequestIDFormatter produces a W3C traceparent value.
+func WithRequestID(header string, format RequestIDFormatter) ServeMuxOption {
+	if header == "" {
+		header = RequestIDHeader
+	}
+	if format == nil {
+		format = RawRequestIDFormatter
+	}
+	return func(serveMux *ServeMux) {
+		serveMux.requestID = &requestIDOptions{
+			header: http.CanonicalHeaderKey(header),
+			format: format,
+		}
+	}
+}
+
+// RawRequestIDFormatter echoes the request ID as is.
+func RawRequestIDFormatter(id string) string {
+	return id
+}
+
+// TraceparentRequestIDFormatter echoes the request ID as a W3C traceparent header value
+// (https://www.w3.org/TR/trace-context/#traceparent-header).
+// IDs made of 32 hexadecimal digits, possibly with dashes like UUIDs, are used as the
+// trace ID. Other IDs are hashed into a trace ID. The parent ID is random.
+func TraceparentRequestIDFormatter(id string) string {
+	return "00-" + traceIDFromRequestID(id) + "-" + randomHex(8) + "-01"
+}
+
+// RequestIDFromContext returns the ID assigned to the request by the ServeMux option WithRequestID.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok
+}
+
+// withRequestID returns r with its ID in its context, and echoes the ID in the response headers.
+func (o *requestIDOptions) withRequestID(w http.ResponseWriter, r *http.Request) *http.Request {
+	id := r.Header.Get(o.header)
+	if id == "" {
+		id = randomHex(16)
+	}
+	w.Header().Set(o.header, o.format(id))
+	return r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+}
+
+func traceIDFromRequestID(id string) string {
+	traceID := strings.ToLower(strings.Replace(id, "-", "", -1))
+	if len(traceID) == 32 && traceID != strings.Repeat("0", 32) {
+		if _, err := hex.DecodeString(traceID); err == nil {
+			return traceID
+		}
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:16])
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms.
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,130 @@
+package runtime_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+	"google.golang.org/grpc/metadata"
+)
+
+var traceparentRegexp = regexp.MustCompile("^00-([0-9a-f]{32})-[0-9a-f]{16}-01$")
+
+func TestRequestID(t *testing.T) {
+	for _, spec := range []struct {
+		name      string
+		header    string
+		format    runtime.RequestIDFormatter
+		reqHeader string
+		reqID     string
+
+		wantHeader      string
+		wantTraceparent bool
+		wantTraceID     string
+	}{
+		{
+			name:       "raw received ID",
+			reqHeader:  "X-Request-Id",
+			reqID:      "abc123",
+			wantHeader: "X-Request-Id",
+		},
+		{
+			name:       "raw generated ID",
+			wantHeader: "X-Request-Id",
+		},
+		{
+			name:       "custom header",
+			header:     "X-Correlation-Id",
+			reqHeader:  "X-Correlation-Id",
+			reqID:      "abc123",
+			wantHeader: "X-Correlation-Id",
+		},
+		{
+			name:            "traceparent from UUID",
+			format:          runtime.TraceparentRequestIDFormatter,
+			reqHeader:       "X-Request-Id",
+			reqID:           "6EC2446F-7E89-4127-B3E6-5C05E6BECBA7",
+			wantHeader:      "X-Request-Id",
+			wantTraceparent: true,
+			wantTraceID:     "6ec2446f7e894127b3e65c05e6becba7",
+		},
+		{
+			name:            "traceparent from other ID",
+			format:          runtime.TraceparentRequestIDFormatter,
+			reqHeader:       "X-Request-Id",
+			reqID:           "abc123",
+			wantHeader:      "X-Request-Id",
+			wantTraceparent: true,
+		},
+		{
+			name:            "traceparent from generated ID",
+			format:          runtime.TraceparentRequestIDFormatter,
+			wantHeader:      "X-Request-Id",
+			wantTraceparent: true,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(runtime.WithRequestID(spec.header, spec.format))
+			var gotID string
+			var gotMD metadata.MD
+			pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+			mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				gotID, _ = runtime.RequestIDFromContext(r.Context())
+				ctx, err := runtime.AnnotateContext(r.Context(), mux, r)
+				if err != nil {
+					t.Fatalf("runtime.AnnotateContext(ctx, mux, r) failed with %v; want success", err)
+				}
+				gotMD, _ = metadata.FromOutgoingContext(ctx)
+			})
+
+			r := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			if spec.reqHeader != "" {
+				r.Header.Set(spec.reqHeader, spec.reqID)
+			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if spec.reqID != "" && gotID != spec.reqID {
+				t.Errorf("RequestIDFromContext(ctx) = %q; want %q", gotID, spec.reqID)
+			}
+			if gotID == "" {
+				t.Fatalf("RequestIDFromContext(ctx) = %q; want an ID", gotID)
+			}
+			mdKey := strings.ToLower(spec.wantHeader)
+			if got, want := gotMD[mdKey], []string{gotID}; !reflect.DeepEqual(got, want) {
+				t.Errorf("md[%q] = %q; want %q", mdKey, got, want)
+			}
+
+			echoed := w.Header().Get(spec.wantHeader)
+			if !spec.wantTraceparent {
+				if echoed != gotID {
+					t.Errorf("w.Header().Get(%q) = %q; want %q", spec.wantHeader, echoed, gotID)
+				}
+				return
+			}
+			match := traceparentRegexp.FindStringSubmatch(echoed)
+			if match == nil {
+				t.Fatalf("w.Header().Get(%q) = %q; want a traceparent value", spec.wantHeader, echoed)
+			}
+			if spec.wantTraceID != "" && match[1] != spec.wantTraceID {
+				t.Errorf("trace ID = %q; want %q", match[1], spec.wantTraceID)
+			}
+		})
+	}
+}
+
+func TestTraceparentRequestIDFormatterIsStable(t *testing.T) {
+	a := traceparentRegexp.FindStringSubmatch(runtime.TraceparentRequestIDFormatter("abc123"))
+	b := traceparentRegexp.FindStringSubmatch(runtime.TraceparentRequestIDFormatter("abc123"))
+	if a == nil || b == nil {
+		t.Fatalf("TraceparentRequestIDFormatter(%q) did not return a traceparent value", "abc123")
+	}
+	if a[1] != b[1] {
+		t.Errorf("trace IDs for the same request ID differ: %q and %q", a[1], b[1])
+	}
+}
@@ -0,0 +1,88 @@
+package runtime
+
+import (
+	"net/http"
+	"net/textproto"
+)
+
+// WithDedupeResponseHeaders returns a ServeMuxOption that keeps the responses of ForwardResponseMessage
+// and ForwardResponseStream from having the same header twice when it is set both by the handler and
+// from the header metadata of the backend. The headers set by the handler take precedence: the
+// metadata is not forwarded for the headers already set on the response, e.g. by a middleware, and
+// the values forwarded from the metadata are removed from the headers to which the forward
+// response options add values. Identical values of the forwarded headers are collapsed too.
+func WithDedupeResponseHeaders() ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.dedupeResponseHeaders = true
+	}
+}
+
+// forwardDedupedServerMetadata adds the header metadata of md to the headers of w which are not set
+// yet, without duplicate values, and returns the headers it added.
+func forwardDedupedServerMetadata(w http.ResponseWriter, mux *ServeMux, md ServerMetadata) http.Header {
+	forwarded := make(http.Header)
+	for k, vs := range md.HeaderMD {
+		h, ok := mux.outgoingHeaderMatcher(k)
+		if !ok {
+			continue
+		}
+		h = textproto.CanonicalMIMEHeaderKey(h)
+		if _, ok := forwarded[h]; !ok && len(w.Header()[h]) > 0 {
+			// Set by the handler.
+			continue
+		}
+		for _, v := range vs {
+			if !containsValue(w.Header()[h], v) {
+				w.Header().Add(h, v)
+				forwarded.Add(h, v)
+			}
+		}
+	}
+	return forwarded
+}
+
+// dedupeResponseHeaders removes the values forwarded from the header metadata from the headers of
+// w to which the forward response options added values, and collapses the identical values of
+// the other ones.
+func dedupeResponseHeaders(w http.ResponseWriter, forwarded http.Header) {
+	for h, mdVals := range forwarded {
+		vals := w.Header()[h]
+		if len(vals) > len(mdVals) {
+			vals = removeValues(vals, mdVals)
+		}
+		var deduped []string
+		for _, v := range vals {
+			if !containsValue(deduped, v) {
+				deduped = append(deduped, v)
+			}
+		}
+		if len(deduped) == 0 {
+			w.Header().Del(h)
+			continue
+		}
+		w.Header()[h] = deduped
+	}
+}
+
+// removeValues returns vals without one occurrence of each of removed.
+func removeValues(vals, removed []string) []string {
+	kept := append([]string(nil), vals...)
+	for _, r := range removed {
+		for i, v := range kept {
+			if v == r {
+				kept = append(kept[:i], kept[i+1:]...)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+func containsValue(vals []string, v string) bool {
+	for _, val := range vals {
+		if val == v {
+			return true
+		}
+	}
+	return false
+}
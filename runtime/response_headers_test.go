@@ -0,0 +1,86 @@
+package runtime_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestWithDedupeResponseHeaders(t *testing.T) {
+	addHeader := func(ctx context.Context, w http.ResponseWriter, _ proto.Message) error {
+		w.Header().Add("X-Foo", "option")
+		return nil
+	}
+	for _, spec := range []struct {
+		name    string
+		dedupe  bool
+		preset  string
+		md      []string
+		options []func(context.Context, http.ResponseWriter, proto.Message) error
+
+		want []string
+	}{
+		{
+			name:    "without dedupe",
+			md:      []string{"md"},
+			options: []func(context.Context, http.ResponseWriter, proto.Message) error{addHeader},
+			want:    []string{"md", "option"},
+		},
+		{
+			name:   "set before forwarding",
+			dedupe: true,
+			preset: "handler",
+			md:     []string{"md"},
+			want:   []string{"handler"},
+		},
+		{
+			name:    "added by a forward response option",
+			dedupe:  true,
+			md:      []string{"md"},
+			options: []func(context.Context, http.ResponseWriter, proto.Message) error{addHeader},
+			want:    []string{"option"},
+		},
+		{
+			name:    "same value added by a forward response option",
+			dedupe:  true,
+			md:      []string{"option"},
+			options: []func(context.Context, http.ResponseWriter, proto.Message) error{addHeader},
+			want:    []string{"option"},
+		},
+		{
+			name:   "identical metadata values",
+			dedupe: true,
+			md:     []string{"a", "a", "b"},
+			want:   []string{"a", "b"},
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			opts := []runtime.ServeMuxOption{runtime.WithOutgoingHeaderMatcher(func(key string) (string, bool) {
+				return key, key == "x-foo"
+			})}
+			if spec.dedupe {
+				opts = append(opts, runtime.WithDedupeResponseHeaders())
+			}
+			mux := runtime.NewServeMux(opts...)
+			md := runtime.ServerMetadata{HeaderMD: metadata.MD{"x-foo": spec.md}}
+			ctx := runtime.NewServerMetadataContext(context.Background(), md)
+			req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			resp := httptest.NewRecorder()
+			if spec.preset != "" {
+				resp.Header().Set("X-Foo", spec.preset)
+			}
+			runtime.ForwardResponseMessage(ctx, mux, &runtime.JSONPb{}, resp, req, &pb.SimpleMessage{Id: "foo"}, spec.options...)
+
+			if got := resp.Header()["X-Foo"]; !reflect.DeepEqual(got, spec.want) {
+				t.Errorf("X-Foo = %q; want %q", got, spec.want)
+			}
+		})
+	}
+}
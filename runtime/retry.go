@@ -0,0 +1,90 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BackoffFunc returns how long to wait before the retry of a request which failed attempt times.
+type BackoffFunc func(attempt int) time.Duration
+
+// getRetryOptions are the options of WithGETRetry.
+type getRetryOptions struct {
+	maxAttempts int
+	backoff     BackoffFunc
+}
+
+// WithGETRetry returns a ServeMuxOption that calls the handlers of GET, HEAD and OPTIONS requests,
+// which are safe to repeat and have no body, up to maxAttempts times in all while the backend
+// fails with "Unavailable", so that transient failures are not returned to the clients. The
+// failures are retried after the duration returned by backoff, if any, measured by the clock of
+// the ServeMux, and the last one is replied as usual.
+//
+// Only the errors replied with HTTPError before the response is written are retried, e.g. not the
+// errors of response streams which have already sent messages, nor the errors of requests canceled
+// while waiting.
+func WithGETRetry(maxAttempts int, backoff BackoffFunc) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.getRetry = &getRetryOptions{maxAttempts: maxAttempts, backoff: backoff}
+	}
+}
+
+// retryState tells MuxOrGlobalHTTPError whether the failure of an attempt is retried.
+type retryState struct {
+	remaining int
+	retry     bool
+}
+
+type retryStateKey struct{}
+
+// retryable returns true if the request can be retried.
+func (o *getRetryOptions) retryable(r *http.Request) bool {
+	if o.maxAttempts <= 1 {
+		return false
+	}
+	switch r.Method {
+	case "GET", "HEAD", "OPTIONS":
+		return true
+	}
+	return false
+}
+
+// serveWithRetry calls h until it does not fail with a retried error, or the attempts are used.
+func (s *ServeMux) serveWithRetry(w http.ResponseWriter, r *http.Request, h HandlerFunc, pathParams map[string]string) {
+	state := &retryState{}
+	r = r.WithContext(context.WithValue(r.Context(), retryStateKey{}, state))
+	for attempt := 1; ; attempt++ {
+		state.remaining, state.retry = s.getRetry.maxAttempts-attempt, false
+		h(w, r, pathParams)
+		if !state.retry {
+			return
+		}
+		var d time.Duration
+		if s.getRetry.backoff != nil {
+			d = s.getRetry.backoff(attempt)
+		}
+		if err := s.wait(r.Context(), d); err != nil {
+			ctx := NewServerMetadataContext(r.Context(), ServerMetadata{})
+			_, outbound := MarshalerForRequest(s, r)
+			HTTPError(ctx, s, outbound, w, r, err)
+			return
+		}
+	}
+}
+
+// retryFailure returns true if err, replied to r, is retried rather than replied.
+func retryFailure(r *http.Request, err error) bool {
+	if r == nil {
+		return false
+	}
+	state, ok := r.Context().Value(retryStateKey{}).(*retryState)
+	if !ok || state.remaining <= 0 || status.Code(err) != codes.Unavailable {
+		return false
+	}
+	state.retry = true
+	return true
+}
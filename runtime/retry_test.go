@@ -0,0 +1,117 @@
+package runtime_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flakyHandler returns a handler for mux which fails with err the first failures times it is
+// called, and counts its calls.
+func flakyHandler(mux *runtime.ServeMux, failures int, err error, calls *int) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		*calls++
+		ctx := runtime.NewServerMetadataContext(r.Context(), runtime.ServerMetadata{})
+		_, outbound := runtime.MarshalerForRequest(mux, r)
+		if *calls <= failures {
+			runtime.HTTPError(ctx, mux, outbound, w, r, err)
+			return
+		}
+		runtime.ForwardResponseMessage(ctx, mux, outbound, w, r, &pb.SimpleMessage{Id: "foo"})
+	}
+}
+
+func TestWithGETRetry(t *testing.T) {
+	pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+	unavailable := status.Error(codes.Unavailable, "backend unavailable")
+	for _, spec := range []struct {
+		name        string
+		method      string
+		maxAttempts int
+		failures    int
+		err         error
+
+		wantStatus int
+		wantCalls  int
+	}{
+		{name: "success on retry", method: "GET", maxAttempts: 3, failures: 2, err: unavailable, wantStatus: http.StatusOK, wantCalls: 3},
+		{name: "attempts used", method: "GET", maxAttempts: 3, failures: 3, err: unavailable, wantStatus: http.StatusServiceUnavailable, wantCalls: 3},
+		{name: "single attempt", method: "GET", maxAttempts: 1, failures: 1, err: unavailable, wantStatus: http.StatusServiceUnavailable, wantCalls: 1},
+		{name: "other error", method: "GET", maxAttempts: 3, failures: 1, err: status.Error(codes.NotFound, "not found"), wantStatus: http.StatusNotFound, wantCalls: 1},
+		{name: "unsafe method", method: "POST", maxAttempts: 3, failures: 1, err: unavailable, wantStatus: http.StatusServiceUnavailable, wantCalls: 1},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			var calls int
+			mux := runtime.NewServeMux(runtime.WithGETRetry(spec.maxAttempts, nil))
+			mux.Handle(spec.method, pat, flakyHandler(mux, spec.failures, spec.err, &calls))
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(spec.method, "http://example.com/foo", nil))
+
+			if got, want := w.Code, spec.wantStatus; got != want {
+				t.Errorf("w.Code = %d; want %d; body=%s", got, want, w.Body)
+			}
+			if got, want := calls, spec.wantCalls; got != want {
+				t.Errorf("handler called %d times; want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestWithGETRetryBackoff(t *testing.T) {
+	pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+	clock := &schedulingClock{fakeClock: newFakeClock(), scheduled: make(chan time.Duration, 1)}
+	backoff := func(attempt int) time.Duration {
+		return time.Duration(attempt) * 10 * time.Millisecond
+	}
+	var calls int
+	mux := runtime.NewServeMux(runtime.WithClock(clock), runtime.WithGETRetry(3, backoff))
+	mux.Handle("GET", pat, flakyHandler(mux, 2, status.Error(codes.Unavailable, "backend unavailable"), &calls))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/foo", nil).WithContext(ctx))
+	}()
+
+	for _, want := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond} {
+		if got := <-clock.scheduled; got != want {
+			t.Errorf("backoff = %v; want %v", got, want)
+		}
+		clock.Advance(want)
+	}
+	<-done
+	if got, want := w.Code, http.StatusOK; got != want {
+		t.Errorf("w.Code = %d; want %d", got, want)
+	}
+
+	// A request canceled during the backoff is not retried.
+	calls = 0
+	ctx, cancel = context.WithCancel(context.Background())
+	w = httptest.NewRecorder()
+	done = make(chan struct{})
+	go func() {
+		defer close(done)
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/foo", nil).WithContext(ctx))
+	}()
+	<-clock.scheduled
+	cancel()
+	<-done
+	if got, want := calls, 1; got != want {
+		t.Errorf("handler called %d times; want %d", got, want)
+	}
+	if got, want := w.Code, http.StatusRequestTimeout; got != want {
+		t.Errorf("w.Code = %d; want %d", got, want)
+	}
+}
@@ -0,0 +1,83 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+)
+
+// matchedRoute is the route to which a ServeMux dispatched a request.
+type matchedRoute struct {
+	matched    bool
+	pattern    Pattern
+	pathParams map[string]string
+}
+
+type matchedRouteKey struct{}
+
+// NewRouteContext returns a copy of ctx in which a ServeMux records the route to which it
+// dispatches a request with this context. It lets middleware wrapped around ServeMux.ServeHTTP
+// get the route with HTTPPattern, HTTPPathPattern and HTTPPathParams once ServeHTTP returns,
+// e.g. to label logs or metrics:
+//
+//	r = r.WithContext(runtime.NewRouteContext(r.Context()))
+//	mux.ServeHTTP(w, r)
+//	if pattern, ok := runtime.HTTPPathPattern(r.Context()); ok {
+//		...
+//	}
+//
+// Handlers do not need it: the contexts of the requests they get always have the route.
+func NewRouteContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, matchedRouteKey{}, &matchedRoute{})
+}
+
+// withMatchedRoute returns r with the route of the handler h, which matched r with pathParams,
+// in its context.
+func withMatchedRoute(r *http.Request, h handler, pathParams map[string]string) *http.Request {
+	route, ok := r.Context().Value(matchedRouteKey{}).(*matchedRoute)
+	if !ok {
+		route = &matchedRoute{}
+		r = r.WithContext(context.WithValue(r.Context(), matchedRouteKey{}, route))
+	}
+	*route = matchedRoute{matched: true, pattern: h.pat, pathParams: pathParams}
+	return r
+}
+
+func routeFromContext(ctx context.Context) (*matchedRoute, bool) {
+	route, ok := ctx.Value(matchedRouteKey{}).(*matchedRoute)
+	if !ok || !route.matched {
+		return nil, false
+	}
+	return route, true
+}
+
+// HTTPPattern returns the pattern of the handler to which the request with ctx was dispatched.
+// It returns false if the request was not dispatched to a handler.
+func HTTPPattern(ctx context.Context) (Pattern, bool) {
+	route, ok := routeFromContext(ctx)
+	if !ok {
+		return Pattern{}, false
+	}
+	return route.pattern, true
+}
+
+// HTTPPathPattern returns the path pattern, e.g. "/v1/objects/{name=*}", of the handler to
+// which the request with ctx was dispatched. Unlike the path of the request, it does not depend
+// on the values of the path parameters. It returns false if the request was not dispatched to
+// a handler.
+func HTTPPathPattern(ctx context.Context) (string, bool) {
+	route, ok := routeFromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	return route.pattern.String(), true
+}
+
+// HTTPPathParams returns the path parameters captured by the pattern of the handler to which the
+// request with ctx was dispatched. It returns false if the request was not dispatched to a handler.
+func HTTPPathParams(ctx context.Context) (map[string]string, bool) {
+	route, ok := routeFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	return route.pathParams, true
+}
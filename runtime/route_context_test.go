@@ -0,0 +1,131 @@
+package runtime_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+)
+
+func TestHTTPPathPattern(t *testing.T) {
+	// "/v1/objects/{name}"
+	pat := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{
+			int(utilities.OpLitPush), 0,
+			int(utilities.OpLitPush), 1,
+			int(utilities.OpPush), 0,
+			int(utilities.OpConcatN), 1,
+			int(utilities.OpCapture), 2,
+		},
+		[]string{"v1", "objects", "name"},
+		"",
+	))
+
+	for _, spec := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		method  string
+		path    string
+		headers map[string]string
+
+		wantMatched bool
+		wantMethod  string
+	}{
+		{
+			name:        "matched",
+			method:      "GET",
+			path:        "/v1/objects/foo",
+			wantMatched: true,
+			wantMethod:  "GET",
+		},
+		{
+			name: "path length fallback",
+			// Picks GET deterministically among the handlers of the path.
+			muxOpts:     []runtime.ServeMuxOption{runtime.WithPathLengthFallbackMethod("POST", "GET")},
+			method:      "POST",
+			path:        "/v1/objects/foo",
+			headers:     map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			wantMatched: true,
+			wantMethod:  "GET",
+		},
+		{
+			name:   "method override",
+			method: "POST",
+			path:   "/v1/objects/foo",
+			headers: map[string]string{
+				"Content-Type":           "application/x-www-form-urlencoded",
+				"X-HTTP-Method-Override": "DELETE",
+			},
+			wantMatched: true,
+			wantMethod:  "DELETE",
+		},
+		{
+			name:   "not matched",
+			method: "GET",
+			path:   "/v1/other/foo",
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(spec.muxOpts...)
+			var handlerMethod string
+			for _, method := range []string{"GET", "DELETE"} {
+				method := method
+				mux.Handle(method, pat, func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+					handlerMethod = method
+					if got, ok := runtime.HTTPPathParams(r.Context()); !ok || !reflect.DeepEqual(got, pathParams) {
+						t.Errorf("runtime.HTTPPathParams(ctx) = %v, %t in the handler; want %v, true", got, ok, pathParams)
+					}
+				})
+			}
+
+			r := httptest.NewRequest(spec.method, "http://host.example"+spec.path, strings.NewReader("a=b"))
+			for k, v := range spec.headers {
+				r.Header.Set(k, v)
+			}
+			// As done by a middleware wrapped around the mux.
+			r = r.WithContext(runtime.NewRouteContext(r.Context()))
+			mux.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got, want := handlerMethod, spec.wantMethod; got != want {
+				t.Errorf("handler of %q called; want %q", got, want)
+			}
+			ctx := r.Context()
+			gotPattern, ok := runtime.HTTPPathPattern(ctx)
+			if ok != spec.wantMatched {
+				t.Fatalf("runtime.HTTPPathPattern(ctx) = %q, %t; want matched %t", gotPattern, ok, spec.wantMatched)
+			}
+			if !spec.wantMatched {
+				if _, ok := runtime.HTTPPattern(ctx); ok {
+					t.Errorf("runtime.HTTPPattern(ctx) succeeded; want false")
+				}
+				if _, ok := runtime.HTTPPathParams(ctx); ok {
+					t.Errorf("runtime.HTTPPathParams(ctx) succeeded; want false")
+				}
+				return
+			}
+			if want := "/v1/objects/{name=*}"; gotPattern != want {
+				t.Errorf("runtime.HTTPPathPattern(ctx) = %q; want %q", gotPattern, want)
+			}
+			if got, ok := runtime.HTTPPattern(ctx); !ok || got.String() != pat.String() {
+				t.Errorf("runtime.HTTPPattern(ctx) = %v, %t; want %v, true", got, ok, pat)
+			}
+			if got, ok := runtime.HTTPPathParams(ctx); !ok || !reflect.DeepEqual(got, map[string]string{"name": "foo"}) {
+				t.Errorf("runtime.HTTPPathParams(ctx) = %v, %t; want %v, true", got, ok, map[string]string{"name": "foo"})
+			}
+		})
+	}
+
+	// Without NewRouteContext, only the handlers have the route.
+	r := httptest.NewRequest("GET", "http://host.example/v1/objects/foo", nil)
+	mux := runtime.NewServeMux()
+	mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {})
+	mux.ServeHTTP(httptest.NewRecorder(), r)
+	if got, ok := runtime.HTTPPathPattern(r.Context()); ok {
+		t.Errorf("runtime.HTTPPathPattern(ctx) = %q, true; want false without NewRouteContext", got)
+	}
+}
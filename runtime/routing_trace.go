@@ -0,0 +1,86 @@
+package runtime
+
+import (
+	"net/http"
+	"sort"
+)
+
+// RouteMismatch is the reason why a pattern did not match a request.
+type RouteMismatch int
+
+const (
+	// RoutePathMismatch means that the path of the request does not match the pattern.
+	RoutePathMismatch RouteMismatch = iota
+	// RouteVerbMismatch means that the path of the request only matches the pattern with
+	// another verb, e.g. "/v1/jobs/1:cancel" for "/v1/jobs/{id}:start".
+	RouteVerbMismatch
+	// RouteMethodMismatch means that the pattern matches the path of the request, but is
+	// registered for another HTTP method.
+	RouteMethodMismatch
+)
+
+func (m RouteMismatch) String() string {
+	switch m {
+	case RoutePathMismatch:
+		return "path mismatch"
+	case RouteVerbMismatch:
+		return "verb mismatch"
+	case RouteMethodMismatch:
+		return "method mismatch"
+	}
+	return "unknown mismatch"
+}
+
+// RouteAttempt is a pattern which was tried to route a request, and why it did not match.
+type RouteAttempt struct {
+	// Method is the HTTP method the pattern is registered for.
+	Method string
+	// Pattern is the string representation of the pattern.
+	Pattern string
+	// Reason is why the pattern did not match the request.
+	Reason RouteMismatch
+}
+
+// RoutingTracerFunc is called with the patterns which were tried to route a request that
+// matched none of them.
+type RoutingTracerFunc func(r *http.Request, attempts []RouteAttempt)
+
+// WithRoutingTracer returns a ServeMuxOption that calls fn when a request cannot be routed to
+// a handler, e.g. to log why it got "404 Not Found" or "405 Method Not Allowed". The attempts
+// are all the registered patterns, ordered by method and then in the order they are tried. fn is
+// not called for the requests which are routed to a handler, so that tracing costs nothing then.
+func WithRoutingTracer(fn RoutingTracerFunc) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.routingTracer = fn
+	}
+}
+
+// traceRouting gives the routing tracer, if any, the attempts to route r, whose path has the
+// given components and verb. components is nil if the path could not be split, e.g. since its
+// verb has no path segment before it, and all the patterns are path mismatches then.
+func (s *ServeMux) traceRouting(r *http.Request, components []string, verb string) {
+	if s.routingTracer == nil {
+		return
+	}
+	methods := make([]string, 0, len(s.handlers))
+	for m := range s.handlers {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+
+	var attempts []RouteAttempt
+	for _, m := range methods {
+		for _, h := range s.handlers[m] {
+			reason := RoutePathMismatch
+			if components != nil {
+				if _, err := h.pat.match(components, verb, s.unescapingMode); err == nil {
+					reason = RouteMethodMismatch
+				} else if h.pat.verb != verb && h.pat.matchIgnoringVerb(components, s.unescapingMode) {
+					reason = RouteVerbMismatch
+				}
+			}
+			attempts = append(attempts, RouteAttempt{Method: m, Pattern: h.pat.String(), Reason: reason})
+		}
+	}
+	s.routingTracer(r, attempts)
+}
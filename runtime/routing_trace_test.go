@@ -0,0 +1,215 @@
+package runtime_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+)
+
+func TestWithRoutingTracer(t *testing.T) {
+	// "/v1/items/{id}"
+	item := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{
+			int(utilities.OpLitPush), 0,
+			int(utilities.OpLitPush), 1,
+			int(utilities.OpPush), 0,
+			int(utilities.OpConcatN), 1,
+			int(utilities.OpCapture), 2,
+		},
+		[]string{"v1", "items", "id"},
+		"",
+	))
+	// "/v1/items/{id}:cancel"
+	cancel := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{
+			int(utilities.OpLitPush), 0,
+			int(utilities.OpLitPush), 1,
+			int(utilities.OpPush), 0,
+			int(utilities.OpConcatN), 1,
+			int(utilities.OpCapture), 2,
+		},
+		[]string{"v1", "items", "id"},
+		"cancel",
+	))
+	// "/v1/users"
+	users := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{int(utilities.OpLitPush), 0, int(utilities.OpLitPush), 1},
+		[]string{"v1", "users"},
+		"",
+	))
+
+	for _, spec := range []struct {
+		name   string
+		method string
+		path   string
+
+		wantCode     int
+		wantTraced   bool
+		wantAttempts []runtime.RouteAttempt
+	}{
+		{
+			name:       "method mismatch",
+			method:     "DELETE",
+			path:       "/v1/items/1",
+			wantCode:   http.StatusMethodNotAllowed,
+			wantTraced: true,
+			wantAttempts: []runtime.RouteAttempt{
+				{Method: "GET", Pattern: item.String(), Reason: runtime.RouteMethodMismatch},
+				{Method: "GET", Pattern: users.String(), Reason: runtime.RoutePathMismatch},
+				{Method: "POST", Pattern: cancel.String(), Reason: runtime.RouteVerbMismatch},
+			},
+		},
+		{
+			name:       "verb mismatch",
+			method:     "POST",
+			path:       "/v1/items/1:start",
+			wantCode:   http.StatusNotFound,
+			wantTraced: true,
+			wantAttempts: []runtime.RouteAttempt{
+				{Method: "GET", Pattern: item.String(), Reason: runtime.RouteVerbMismatch},
+				{Method: "GET", Pattern: users.String(), Reason: runtime.RoutePathMismatch},
+				{Method: "POST", Pattern: cancel.String(), Reason: runtime.RouteVerbMismatch},
+			},
+		},
+		{
+			name:     "matched",
+			method:   "POST",
+			path:     "/v1/items/1:cancel",
+			wantCode: http.StatusOK,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			var traced bool
+			var attempts []runtime.RouteAttempt
+			mux := runtime.NewServeMux(runtime.WithRoutingTracer(func(r *http.Request, a []runtime.RouteAttempt) {
+				traced, attempts = true, a
+			}))
+			handler := func(w http.ResponseWriter, r *http.Request, _ map[string]string) {}
+			mux.Handle("GET", item, handler)
+			mux.Handle("GET", users, handler)
+			mux.Handle("POST", cancel, handler)
+
+			r := httptest.NewRequest(spec.method, "http://example.com"+spec.path, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.wantCode; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+			if traced != spec.wantTraced {
+				t.Fatalf("traced = %t; want %t", traced, spec.wantTraced)
+			}
+			if !reflect.DeepEqual(attempts, spec.wantAttempts) {
+				t.Errorf("attempts = %v; want %v", attempts, spec.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestWithRoutingTracerColonVerbs(t *testing.T) {
+	// "/"
+	root := runtime.MustPattern(runtime.NewPattern(1, nil, nil, ""))
+	// "/v1/files", where a colon in the last segment is no verb.
+	files := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{int(utilities.OpLitPush), 0, int(utilities.OpLitPush), 1},
+		[]string{"v1", "files"},
+		"",
+		runtime.AssumeColonVerbOpt(false),
+	))
+	// "/v1/items/{id}:cancel"
+	cancel := runtime.MustPattern(runtime.NewPattern(
+		1,
+		[]int{
+			int(utilities.OpLitPush), 0,
+			int(utilities.OpLitPush), 1,
+			int(utilities.OpPush), 0,
+			int(utilities.OpConcatN), 1,
+			int(utilities.OpCapture), 2,
+		},
+		[]string{"v1", "items", "id"},
+		"cancel",
+	))
+
+	for _, spec := range []struct {
+		name    string
+		muxOpts []runtime.ServeMuxOption
+		path    string
+
+		wantCode     int
+		wantAttempts []runtime.RouteAttempt
+	}{
+		{
+			name:     "unknown path with verb",
+			path:     "/unknown:verb",
+			wantCode: http.StatusNotFound,
+			wantAttempts: []runtime.RouteAttempt{
+				{Method: "GET", Pattern: root.String(), Reason: runtime.RoutePathMismatch},
+				{Method: "GET", Pattern: files.String(), Reason: runtime.RoutePathMismatch},
+				{Method: "GET", Pattern: cancel.String(), Reason: runtime.RoutePathMismatch},
+			},
+		},
+		{
+			name:     "verb without path segment",
+			path:     "/:verb",
+			wantCode: http.StatusNotFound,
+			wantAttempts: []runtime.RouteAttempt{
+				{Method: "GET", Pattern: root.String(), Reason: runtime.RoutePathMismatch},
+				{Method: "GET", Pattern: files.String(), Reason: runtime.RoutePathMismatch},
+				{Method: "GET", Pattern: cancel.String(), Reason: runtime.RoutePathMismatch},
+			},
+		},
+		{
+			name:     "colon in pattern without verb",
+			muxOpts:  []runtime.ServeMuxOption{runtime.WithUnknownVerbStatus(http.StatusBadRequest)},
+			path:     "/v1/files:upload",
+			wantCode: http.StatusNotFound,
+			wantAttempts: []runtime.RouteAttempt{
+				{Method: "GET", Pattern: root.String(), Reason: runtime.RoutePathMismatch},
+				{Method: "GET", Pattern: files.String(), Reason: runtime.RoutePathMismatch},
+				{Method: "GET", Pattern: cancel.String(), Reason: runtime.RoutePathMismatch},
+			},
+		},
+		{
+			name:     "unknown verb",
+			muxOpts:  []runtime.ServeMuxOption{runtime.WithUnknownVerbStatus(http.StatusBadRequest)},
+			path:     "/v1/items/1:start",
+			wantCode: http.StatusBadRequest,
+			wantAttempts: []runtime.RouteAttempt{
+				{Method: "GET", Pattern: root.String(), Reason: runtime.RoutePathMismatch},
+				{Method: "GET", Pattern: files.String(), Reason: runtime.RoutePathMismatch},
+				{Method: "GET", Pattern: cancel.String(), Reason: runtime.RouteVerbMismatch},
+			},
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			var attempts []runtime.RouteAttempt
+			opts := append([]runtime.ServeMuxOption{runtime.WithRoutingTracer(func(r *http.Request, a []runtime.RouteAttempt) {
+				attempts = a
+			})}, spec.muxOpts...)
+			mux := runtime.NewServeMux(opts...)
+			handler := func(w http.ResponseWriter, r *http.Request, _ map[string]string) {}
+			mux.Handle("GET", root, handler)
+			mux.Handle("GET", files, handler)
+			mux.Handle("GET", cancel, handler)
+
+			r := httptest.NewRequest("GET", "http://example.com"+spec.path, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if got, want := w.Code, spec.wantCode; got != want {
+				t.Errorf("w.Code = %d; want %d", got, want)
+			}
+			if !reflect.DeepEqual(attempts, spec.wantAttempts) {
+				t.Errorf("attempts = %v; want %v", attempts, spec.wantAttempts)
+			}
+		})
+	}
+}
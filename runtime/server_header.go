@@ -0,0 +1,51 @@
+package runtime
+
+import (
+	"net/http"
+)
+
+// WithServerHeader returns a ServeMuxOption that sets the header Server of all the responses of
+// the ServeMux to value, replacing the one set by the handlers, if any, right before the header
+// is written. The header is removed if value is empty, so that the responses do not disclose the
+// server software.
+func WithServerHeader(value string) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.serverHeader = &value
+	}
+}
+
+// serverHeaderResponseWriter sets the header Server of a response before it is written.
+type serverHeaderResponseWriter struct {
+	http.ResponseWriter
+
+	value       string
+	wroteHeader bool
+}
+
+func (w *serverHeaderResponseWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if w.value == "" {
+			w.Header().Del("Server")
+		} else {
+			w.Header().Set("Server", w.value)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *serverHeaderResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *serverHeaderResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,69 @@
+package runtime_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+)
+
+func TestWithServerHeader(t *testing.T) {
+	pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+	for _, tt := range []struct {
+		name string
+		opts []runtime.ServeMuxOption
+		// handlerValue is the header Server set by the handler, if any.
+		handlerValue string
+
+		want    string
+		present bool
+	}{
+		{
+			name:         "no option",
+			handlerValue: "backend/1.0",
+			want:         "backend/1.0",
+			present:      true,
+		},
+		{
+			name:    "header set",
+			opts:    []runtime.ServeMuxOption{runtime.WithServerHeader("gateway")},
+			want:    "gateway",
+			present: true,
+		},
+		{
+			name:         "header replaced",
+			opts:         []runtime.ServeMuxOption{runtime.WithServerHeader("gateway")},
+			handlerValue: "backend/1.0",
+			want:         "gateway",
+			present:      true,
+		},
+		{
+			name:         "header removed",
+			opts:         []runtime.ServeMuxOption{runtime.WithServerHeader("")},
+			handlerValue: "backend/1.0",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(tt.opts...)
+			mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				if tt.handlerValue != "" {
+					w.Header().Set("Server", tt.handlerValue)
+				}
+				w.Write([]byte("{}"))
+			})
+
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/foo", nil))
+
+			got, present := w.Result().Header["Server"]
+			if present != tt.present {
+				t.Fatalf("Server header present = %t; want %t", present, tt.present)
+			}
+			if present && got[0] != tt.want {
+				t.Errorf("Server header = %q; want %q", got[0], tt.want)
+			}
+		})
+	}
+}
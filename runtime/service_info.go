@@ -0,0 +1,226 @@
+package runtime
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/grpclog"
+)
+
+// ServiceInfo describes a gRPC service and the HTTP bindings of its methods,
+// as served by the endpoint registered with WithServiceInfoEndpoint.
+type ServiceInfo struct {
+	Name    string       `json:"name"`
+	Methods []MethodInfo `json:"methods"`
+}
+
+// MethodInfo describes a method of a gRPC service.
+type MethodInfo struct {
+	Name            string        `json:"name"`
+	ClientStreaming bool          `json:"clientStreaming"`
+	ServerStreaming bool          `json:"serverStreaming"`
+	Bindings        []HTTPBinding `json:"bindings"`
+}
+
+// HTTPBinding is an HTTP method and path pattern mapped to a gRPC method by a google.api.http rule.
+type HTTPBinding struct {
+	Method       string `json:"method"`
+	Path         string `json:"path"`
+	Body         string `json:"body,omitempty"`
+	ResponseBody string `json:"responseBody,omitempty"`
+}
+
+// HTTPRule is an HTTP binding of a method of a gRPC service, as returned by HTTPRulesFromService.
+type HTTPRule struct {
+	// RPC is the name of the gRPC method, like "GetItem".
+	RPC string
+	HTTPBinding
+}
+
+// HTTPRulesFromService returns the HTTP bindings found in the google.api.http options of the
+// methods of sd, in the order of the methods and each followed by its additional bindings, e.g. to
+// build a routing table or documentation for the gateway. The methods without a google.api.http
+// option, or whose option cannot be read, have no rules.
+//
+// The descriptor of a generated service can be read from its registered file descriptor, like
+// WithServiceInfoEndpoint does, or with the descriptor package of github.com/golang/protobuf.
+func HTTPRulesFromService(sd *descriptor.ServiceDescriptorProto) []HTTPRule {
+	var rules []HTTPRule
+	for _, m := range sd.GetMethod() {
+		rule, err := httpRule(m)
+		if err != nil {
+			grpclog.Infof("Failed to read the HTTP rule of %s.%s: %v", sd.GetName(), m.GetName(), err)
+			continue
+		}
+		for _, b := range httpBindings(rule) {
+			rules = append(rules, HTTPRule{RPC: m.GetName(), HTTPBinding: b})
+		}
+	}
+	return rules
+}
+
+// WithServiceInfoEndpoint returns a ServeMuxOption that serves at path, for GET requests, a JSON
+// description of the given services: their methods, and the HTTP bindings found in the
+// google.api.http options of the methods. The bindings are read from the file descriptors
+// registered by the generated protobuf code, which are found through the file name the
+// generated gRPC code sets as the Metadata of each grpc.ServiceDesc.
+func WithServiceInfoEndpoint(path string, services []grpc.ServiceDesc) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		infos := make([]ServiceInfo, 0, len(services))
+		for _, desc := range services {
+			infos = append(infos, serviceInfo(desc))
+		}
+		body, err := json.Marshal(struct {
+			Services []ServiceInfo `json:"services"`
+		}{infos})
+		if err != nil {
+			grpclog.Errorf("Failed to marshal service info: %v", err)
+			return
+		}
+		serveMux.handleBuiltin(path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(body)
+		})
+	}
+}
+
+// handleBuiltin registers h to handle GET requests for path before any registered pattern.
+func (s *ServeMux) handleBuiltin(path string, h http.HandlerFunc) {
+	if s.builtinEndpoints == nil {
+		s.builtinEndpoints = make(map[string]http.HandlerFunc)
+	}
+	s.builtinEndpoints[path] = h
+}
+
+func serviceInfo(desc grpc.ServiceDesc) ServiceInfo {
+	info := ServiceInfo{Name: desc.ServiceName, Methods: []MethodInfo{}}
+	sd, err := serviceDescriptor(desc)
+	if err != nil {
+		grpclog.Infof("Failed to find the descriptor of %s: %v", desc.ServiceName, err)
+		for _, m := range desc.Methods {
+			info.Methods = append(info.Methods, MethodInfo{Name: m.MethodName, Bindings: []HTTPBinding{}})
+		}
+		for _, s := range desc.Streams {
+			info.Methods = append(info.Methods, MethodInfo{
+				Name:            s.StreamName,
+				ClientStreaming: s.ClientStreams,
+				ServerStreaming: s.ServerStreams,
+				Bindings:        []HTTPBinding{},
+			})
+		}
+		return info
+	}
+
+	for _, m := range sd.GetMethod() {
+		mi := MethodInfo{
+			Name:            m.GetName(),
+			ClientStreaming: m.GetClientStreaming(),
+			ServerStreaming: m.GetServerStreaming(),
+			Bindings:        []HTTPBinding{},
+		}
+		rule, err := httpRule(m)
+		if err != nil {
+			grpclog.Infof("Failed to read the HTTP rule of %s.%s: %v", desc.ServiceName, m.GetName(), err)
+		}
+		if b := httpBindings(rule); b != nil {
+			mi.Bindings = b
+		}
+		info.Methods = append(info.Methods, mi)
+	}
+	return info
+}
+
+// serviceDescriptor returns the descriptor of the service described by desc, from the file
+// descriptor registered under the name in desc.Metadata.
+func serviceDescriptor(desc grpc.ServiceDesc) (*descriptor.ServiceDescriptorProto, error) {
+	filename, ok := desc.Metadata.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected service metadata %T; want a file name", desc.Metadata)
+	}
+	gz := proto.FileDescriptor(filename)
+	if gz == nil {
+		return nil, fmt.Errorf("file descriptor %q not registered", filename)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var fd descriptor.FileDescriptorProto
+	if err := proto.Unmarshal(b, &fd); err != nil {
+		return nil, err
+	}
+	for _, sd := range fd.GetService() {
+		name := sd.GetName()
+		if fd.GetPackage() != "" {
+			name = fd.GetPackage() + "." + name
+		}
+		if name == desc.ServiceName {
+			return sd, nil
+		}
+	}
+	return nil, fmt.Errorf("service %s not found in %q", desc.ServiceName, filename)
+}
+
+// httpRule returns the google.api.http option of m, or nil if it has none.
+func httpRule(m *descriptor.MethodDescriptorProto) (*annotations.HttpRule, error) {
+	if m.Options == nil || !proto.HasExtension(m.Options, annotations.E_Http) {
+		return nil, nil
+	}
+	ext, err := proto.GetExtension(m.Options, annotations.E_Http)
+	if err != nil {
+		return nil, err
+	}
+	rule, ok := ext.(*annotations.HttpRule)
+	if !ok {
+		return nil, fmt.Errorf("extension is %T; want an HttpRule", ext)
+	}
+	return rule, nil
+}
+
+// httpBindings returns the bindings of rule, followed by its additional bindings.
+func httpBindings(rule *annotations.HttpRule) []HTTPBinding {
+	if rule == nil {
+		return nil
+	}
+	var method, path string
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		method, path = "GET", p.Get
+	case *annotations.HttpRule_Put:
+		method, path = "PUT", p.Put
+	case *annotations.HttpRule_Post:
+		method, path = "POST", p.Post
+	case *annotations.HttpRule_Delete:
+		method, path = "DELETE", p.Delete
+	case *annotations.HttpRule_Patch:
+		method, path = "PATCH", p.Patch
+	case *annotations.HttpRule_Custom:
+		method, path = strings.ToUpper(p.Custom.GetKind()), p.Custom.GetPath()
+	}
+	var bindings []HTTPBinding
+	if method != "" {
+		bindings = append(bindings, HTTPBinding{
+			Method:       method,
+			Path:         path,
+			Body:         rule.GetBody(),
+			ResponseBody: rule.GetResponseBody(),
+		})
+	}
+	for _, additional := range rule.GetAdditionalBindings() {
+		bindings = append(bindings, httpBindings(additional)...)
+	}
+	return bindings
+}
@@ -0,0 +1,201 @@
+package runtime_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+)
+
+// httpRuleOptions returns method options with rule as their google.api.http option.
+func httpRuleOptions(t *testing.T, rule *annotations.HttpRule) *descriptor.MethodOptions {
+	opts := &descriptor.MethodOptions{}
+	if err := proto.SetExtension(opts, annotations.E_Http, rule); err != nil {
+		t.Fatalf("proto.SetExtension(opts, annotations.E_Http, %v) failed with %v; want success", rule, err)
+	}
+	return opts
+}
+
+// registerEchoServiceFile registers a file descriptor like the one of a generated
+// echo service with HTTP bindings, and returns its name.
+func registerEchoServiceFile(t *testing.T) string {
+	const filename = "runtime/internal/service_info_test.proto"
+	if proto.FileDescriptor(filename) != nil {
+		return filename
+	}
+
+	fd := &descriptor.FileDescriptorProto{
+		Name:    proto.String(filename),
+		Package: proto.String("grpc.gateway.runtime.internal"),
+		Service: []*descriptor.ServiceDescriptorProto{{
+			Name: proto.String("EchoService"),
+			Method: []*descriptor.MethodDescriptorProto{
+				{
+					Name: proto.String("Echo"),
+					Options: httpRuleOptions(t, &annotations.HttpRule{
+						Pattern: &annotations.HttpRule_Post{Post: "/v1/example/echo/{id}"},
+						AdditionalBindings: []*annotations.HttpRule{
+							{Pattern: &annotations.HttpRule_Get{Get: "/v1/example/echo/{id}/{num}"}},
+						},
+					}),
+				},
+				{
+					Name: proto.String("EchoBody"),
+					Options: httpRuleOptions(t, &annotations.HttpRule{
+						Pattern: &annotations.HttpRule_Post{Post: "/v1/example/echo_body"},
+						Body:    "*",
+					}),
+				},
+				{
+					Name:            proto.String("EchoStream"),
+					ServerStreaming: proto.Bool(true),
+				},
+			},
+		}},
+	}
+	b, err := proto.Marshal(fd)
+	if err != nil {
+		t.Fatalf("proto.Marshal(fd) failed with %v; want success", err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(b)
+	zw.Close()
+	proto.RegisterFile(filename, buf.Bytes())
+	return filename
+}
+
+func TestServiceInfoEndpoint(t *testing.T) {
+	services := []grpc.ServiceDesc{
+		{
+			ServiceName: "grpc.gateway.runtime.internal.EchoService",
+			Metadata:    registerEchoServiceFile(t),
+		},
+		{
+			ServiceName: "example.Unregistered",
+			Methods:     []grpc.MethodDesc{{MethodName: "Get"}},
+			Streams:     []grpc.StreamDesc{{StreamName: "Watch", ServerStreams: true}},
+			Metadata:    "example/unregistered.proto",
+		},
+	}
+	mux := runtime.NewServeMux(runtime.WithServiceInfoEndpoint("/services", services))
+
+	r := httptest.NewRequest("GET", "http://example.com/services", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	if got, want := w.Code, http.StatusOK; got != want {
+		t.Fatalf("w.Code = %d; want %d", got, want)
+	}
+	if got, want := w.Header().Get("Content-Type"), "application/json"; got != want {
+		t.Errorf("Content-Type = %q; want %q", got, want)
+	}
+
+	var body struct {
+		Services []struct {
+			Name    string `json:"name"`
+			Methods []struct {
+				Name            string `json:"name"`
+				ClientStreaming bool   `json:"clientStreaming"`
+				ServerStreaming bool   `json:"serverStreaming"`
+				Bindings        []struct {
+					Method string `json:"method"`
+					Path   string `json:"path"`
+					Body   string `json:"body"`
+				} `json:"bindings"`
+			} `json:"methods"`
+		} `json:"services"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal(%q, &body) failed with %v; want success", w.Body.String(), err)
+	}
+	if got, want := len(body.Services), 2; got != want {
+		t.Fatalf("len(services) = %d; want %d", got, want)
+	}
+
+	echo := body.Services[0]
+	if got, want := echo.Name, "grpc.gateway.runtime.internal.EchoService"; got != want {
+		t.Errorf("services[0].name = %q; want %q", got, want)
+	}
+	var methods []string
+	for _, m := range echo.Methods {
+		methods = append(methods, m.Name)
+	}
+	if got, want := methods, []string{"Echo", "EchoBody", "EchoStream"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("services[0].methods = %q; want %q", got, want)
+	}
+	if b := echo.Methods[0].Bindings; len(b) != 2 || b[0].Method != "POST" || b[0].Path != "/v1/example/echo/{id}" || b[1].Method != "GET" || b[1].Path != "/v1/example/echo/{id}/{num}" {
+		t.Errorf("Echo bindings = %+v; want POST /v1/example/echo/{id} and GET /v1/example/echo/{id}/{num}", b)
+	}
+	if b := echo.Methods[1].Bindings; len(b) != 1 || b[0].Method != "POST" || b[0].Path != "/v1/example/echo_body" || b[0].Body != "*" {
+		t.Errorf("EchoBody bindings = %+v; want POST /v1/example/echo_body with body *", b)
+	}
+	if m := echo.Methods[2]; !m.ServerStreaming || m.ClientStreaming || m.Bindings == nil || len(m.Bindings) != 0 {
+		t.Errorf("EchoStream = %+v; want server-streaming method without bindings", m)
+	}
+
+	unregistered := body.Services[1]
+	if got, want := len(unregistered.Methods), 2; got != want {
+		t.Fatalf("len(services[1].methods) = %d; want %d", got, want)
+	}
+	if m := unregistered.Methods[1]; m.Name != "Watch" || !m.ServerStreaming || m.Bindings == nil {
+		t.Errorf("services[1].methods[1] = %+v; want server-streaming Watch without bindings", m)
+	}
+}
+
+func TestServiceInfoEndpointOnlyServesGet(t *testing.T) {
+	mux := runtime.NewServeMux(runtime.WithServiceInfoEndpoint("/services", nil))
+
+	r := httptest.NewRequest("POST", "http://example.com/services", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	if got, want := w.Code, http.StatusNotFound; got != want {
+		t.Errorf("w.Code = %d; want %d", got, want)
+	}
+}
+
+func TestHTTPRulesFromService(t *testing.T) {
+	sd := &descriptor.ServiceDescriptorProto{
+		Name: proto.String("EchoService"),
+		Method: []*descriptor.MethodDescriptorProto{
+			{
+				Name: proto.String("Echo"),
+				Options: httpRuleOptions(t, &annotations.HttpRule{
+					Pattern: &annotations.HttpRule_Get{Get: "/v1/echo/{id}"},
+					AdditionalBindings: []*annotations.HttpRule{{
+						Pattern: &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Kind: "head", Path: "/v1/echo/{id}"}},
+					}},
+				}),
+			},
+			{Name: proto.String("Unbound")},
+			{
+				Name: proto.String("Update"),
+				Options: httpRuleOptions(t, &annotations.HttpRule{
+					Pattern:      &annotations.HttpRule_Patch{Patch: "/v1/echo/{item.id}"},
+					Body:         "item",
+					ResponseBody: "item",
+				}),
+			},
+		},
+	}
+
+	got := runtime.HTTPRulesFromService(sd)
+	want := []runtime.HTTPRule{
+		{RPC: "Echo", HTTPBinding: runtime.HTTPBinding{Method: "GET", Path: "/v1/echo/{id}"}},
+		{RPC: "Echo", HTTPBinding: runtime.HTTPBinding{Method: "HEAD", Path: "/v1/echo/{id}"}},
+		{RPC: "Update", HTTPBinding: runtime.HTTPBinding{Method: "PATCH", Path: "/v1/echo/{item.id}", Body: "item", ResponseBody: "item"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("runtime.HTTPRulesFromService(sd) = %+v; want %+v", got, want)
+	}
+}
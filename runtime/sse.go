@@ -0,0 +1,193 @@
+package runtime
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/grpclog"
+)
+
+const (
+	sseContentType = "text/event-stream"
+	lastEventID    = "Last-Event-ID"
+)
+
+// SSEEventIDFunc returns the ID of the server-sent event carrying msg.
+// If it returns an empty string, the event has no "id:" line.
+type SSEEventIDFunc func(msg proto.Message) string
+
+// WithServerSentEvents returns a ServeMuxOption that makes server-streaming methods respond in
+// the server-sent events format to requests accepting "text/event-stream".
+//
+// Each message of the stream is sent as one event, whose data is the chunk which would have
+// been sent otherwise. If idFunc is not nil, the event also has an "id:" line with the ID returned
+// by idFunc. Stream errors are sent as an "error" event.
+//
+// The "Last-Event-ID" header which clients send when they reconnect is passed to the gRPC server
+// as "last-event-id" metadata, so that it can resume the stream after the given event.
+func WithServerSentEvents(idFunc SSEEventIDFunc) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.serverSentEvents = true
+		serveMux.sseEventID = idFunc
+	}
+}
+
+// WithSSEKeepAlive returns a ServeMuxOption that sends a ": ping" comment on the server-streaming
+// responses sent as server-sent events, when no event was sent for interval, measured by the clock
+// of the ServeMux, so that proxies do not close the streams whose messages are rare. Clients
+// ignore comments. The comments are only sent after the first event, and are flushed right away.
+func WithSSEKeepAlive(interval time.Duration) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.sseKeepAlive = interval
+	}
+}
+
+// sseKeepAlive is the http.ResponseWriter of a stream of server-sent events, which sends a
+// heartbeat comment when nothing was written during an interval.
+type sseKeepAlive struct {
+	http.ResponseWriter
+	f        http.Flusher
+	clock    Clock
+	interval time.Duration
+
+	mu      sync.Mutex
+	timer   Timer
+	wrote   bool
+	stopped bool
+}
+
+func newSSEKeepAlive(w http.ResponseWriter, f http.Flusher, clock Clock, interval time.Duration) *sseKeepAlive {
+	return &sseKeepAlive{ResponseWriter: w, f: f, clock: clock, interval: interval}
+}
+
+// Write writes p to the stream, and starts the keep-alive with the first write.
+func (k *sseKeepAlive) Write(p []byte) (int, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.timer == nil && !k.stopped {
+		k.timer = k.clock.AfterFunc(k.interval, k.tick)
+	}
+	k.wrote = true
+	return k.ResponseWriter.Write(p)
+}
+
+// Flush flushes the stream.
+func (k *sseKeepAlive) Flush() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.f.Flush()
+}
+
+// tick sends a heartbeat comment if nothing was written since the previous tick.
+func (k *sseKeepAlive) tick() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.stopped {
+		return
+	}
+	if !k.wrote {
+		if _, err := io.WriteString(k.ResponseWriter, heartbeatComment); err != nil {
+			grpclog.Infof("Failed to send keep-alive: %v", err)
+			k.stopped = true
+			return
+		}
+		k.f.Flush()
+	}
+	k.wrote = false
+	k.timer = k.clock.AfterFunc(k.interval, k.tick)
+}
+
+// stop stops the keep-alive. Nothing is written to the stream once it returns.
+func (k *sseKeepAlive) stop() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.stopped = true
+	if k.timer != nil {
+		k.timer.Stop()
+	}
+}
+
+// acceptsServerSentEvents returns true if the response to req must be sent as server-sent events.
+func (s *ServeMux) acceptsServerSentEvents(req *http.Request) bool {
+	if !s.serverSentEvents {
+		return false
+	}
+	for _, accept := range req.Header[acceptHeader] {
+		if strings.Contains(accept, sseContentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeServerSentEvent writes one event to w. The event and id fields are omitted if empty.
+func writeServerSentEvent(w io.Writer, event, id string, data []byte) error {
+	var buf bytes.Buffer
+	if event != "" {
+		buf.WriteString("event: " + event + "\n")
+	}
+	if id != "" {
+		// An ID cannot span several lines.
+		id = strings.NewReplacer("\r", "", "\n", "").Replace(id)
+		buf.WriteString("id: " + id + "\n")
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// heartbeatComment is the server-sent event comment sent by the heartbeat endpoint.
+const heartbeatComment = ": ping\n\n"
+
+// WithHeartbeatSSEEndpoint returns a ServeMuxOption that serves server-sent events at path, for
+// GET requests, which only send a ": ping" comment every interval, measured by the clock of the
+// ServeMux, until the client disconnects. It lets dashboards check the health of connections
+// through proxies and keeps them warm, independently of any RPC. The endpoint is served before
+// any registered pattern.
+func WithHeartbeatSSEEndpoint(path string, interval time.Duration) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.handleBuiltin(path, func(w http.ResponseWriter, r *http.Request) {
+			serveMux.serveHeartbeats(w, r, interval)
+		})
+	}
+}
+
+// serveHeartbeats sends a heartbeat comment to w every interval until r is done.
+func (s *ServeMux) serveHeartbeats(w http.ResponseWriter, r *http.Request, interval time.Duration) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		grpclog.Infof("Flush not supported in %T", w)
+		http.Error(w, "unexpected type of web server", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", sseContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	f.Flush()
+
+	tick := make(chan struct{}, 1)
+	for {
+		t := s.clock.AfterFunc(interval, func() { tick <- struct{}{} })
+		select {
+		case <-r.Context().Done():
+			t.Stop()
+			return
+		case <-tick:
+		}
+		if _, err := io.WriteString(w, heartbeatComment); err != nil {
+			grpclog.Infof("Failed to send heartbeat: %v", err)
+			return
+		}
+		f.Flush()
+	}
+}
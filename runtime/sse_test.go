@@ -0,0 +1,105 @@
+package runtime_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+)
+
+func TestWithHeartbeatSSEEndpoint(t *testing.T) {
+	clock := &schedulingClock{fakeClock: newFakeClock(), scheduled: make(chan time.Duration, 1)}
+	mux := runtime.NewServeMux(
+		runtime.WithClock(clock),
+		runtime.WithHeartbeatSSEEndpoint("/heartbeat", 15*time.Second),
+	)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/heartbeat")
+	if err != nil {
+		t.Fatalf("http.Get(%q) failed with %v; want success", srv.URL+"/heartbeat", err)
+	}
+	defer resp.Body.Close()
+	if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Fatalf("resp.StatusCode = %d; want %d", got, want)
+	}
+	if got, want := resp.Header.Get("Content-Type"), "text/event-stream"; got != want {
+		t.Errorf("Content-Type = %q; want %q", got, want)
+	}
+
+	body := bufio.NewReader(resp.Body)
+	for i := 0; i < 3; i++ {
+		if got, want := <-clock.scheduled, 15*time.Second; got != want {
+			t.Errorf("heartbeat interval = %v; want %v", got, want)
+		}
+		clock.Advance(15 * time.Second)
+		for _, want := range []string{": ping\n", "\n"} {
+			line, err := body.ReadString('\n')
+			if err != nil {
+				t.Fatalf("body.ReadString('\\n') failed with %v; want success", err)
+			}
+			if line != want {
+				t.Errorf("heartbeat line = %q; want %q", line, want)
+			}
+		}
+	}
+}
+
+func TestWithSSEKeepAlive(t *testing.T) {
+	clock := newFakeClock()
+	mux := runtime.NewServeMux(
+		runtime.WithClock(clock),
+		runtime.WithServerSentEvents(nil),
+		runtime.WithSSEKeepAlive(15*time.Second),
+	)
+	ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+	req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp := httptest.NewRecorder()
+
+	// The second call to recv blocks until the test ends the stream.
+	waiting := make(chan struct{})
+	end := make(chan struct{})
+	var calls int
+	recv := func() (proto.Message, error) {
+		calls++
+		if calls == 1 {
+			return &pb.SimpleMessage{Id: "one"}, nil
+		}
+		close(waiting)
+		<-end
+		return nil, io.EOF
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runtime.ForwardResponseStream(ctx, mux, &runtime.JSONPb{}, resp, req, recv)
+	}()
+	<-waiting
+
+	event := "data: {\"result\":{\"id\":\"one\"}}\n\n"
+	// The stream is not idle during the first interval, since the event was sent.
+	clock.Advance(15 * time.Second)
+	if got, want := resp.Body.String(), event; got != want {
+		t.Errorf("resp.Body = %q; want %q", got, want)
+	}
+	clock.Advance(15 * time.Second)
+	if got, want := resp.Body.String(), event+": ping\n\n"; got != want {
+		t.Errorf("resp.Body = %q; want %q", got, want)
+	}
+
+	close(end)
+	<-done
+	clock.Advance(30 * time.Second)
+	if got, want := resp.Body.String(), event+": ping\n\n"; got != want {
+		t.Errorf("resp.Body = %q after the end of the stream; want %q", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// DefaultStrictDecodingParameter is the query parameter read by WithStrictDecodingParameter
+// when no name is given.
+const DefaultStrictDecodingParameter = "_strict"
+
+type strictDecodingOptions struct {
+	param   string
+	trusted func(*http.Request) bool
+}
+
+type strictDecodingKey struct{}
+
+// unknownFieldsMarshaler is implemented by the marshalers whose handling of unknown fields
+// can be chosen per request.
+type unknownFieldsMarshaler interface {
+	// withUnknownFields returns a copy of the marshaler which ignores unknown fields if allow
+	// is true, and fails on them otherwise.
+	withUnknownFields(allow bool) Marshaler
+}
+
+// WithStrictDecodingParameter returns a ServeMuxOption that lets requests choose how unknown
+// fields in their body are handled with the query parameter param, or
+// DefaultStrictDecodingParameter if param is empty: "?_strict=true" rejects unknown fields and
+// "?_strict=false" ignores them, whatever DisallowUnknownFields set.
+//
+// The parameter is only honored for requests for which trusted returns true, and it is removed
+// from the query of all requests, so that it is not parsed into the request message.
+// Only JSONPb inbound marshalers support it; other marshalers decode as usual.
+func WithStrictDecodingParameter(param string, trusted func(r *http.Request) bool) ServeMuxOption {
+	if param == "" {
+		param = DefaultStrictDecodingParameter
+	}
+	return func(serveMux *ServeMux) {
+		serveMux.strictDecoding = &strictDecodingOptions{param: param, trusted: trusted}
+	}
+}
+
+// apply returns r without the parameter, and with the requested strictness in its context
+// if r is trusted.
+func (o *strictDecodingOptions) apply(r *http.Request) *http.Request {
+	query, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		return r
+	}
+	values, ok := query[o.param]
+	if !ok {
+		return r
+	}
+	query.Del(o.param)
+	if r.Form != nil {
+		r.Form.Del(o.param)
+	}
+
+	ctx := r.Context()
+	if strict, err := strconv.ParseBool(values[0]); err == nil && o.trusted(r) {
+		ctx = context.WithValue(ctx, strictDecodingKey{}, strict)
+	}
+	r2 := r.WithContext(ctx)
+	u := *r.URL
+	u.RawQuery = query.Encode()
+	r2.URL = &u
+	return r2
+}
+
+// withRequestStrictness returns inbound with the handling of unknown fields requested by r,
+// if any.
+func withRequestStrictness(inbound Marshaler, r *http.Request) Marshaler {
+	strict, ok := r.Context().Value(strictDecodingKey{}).(bool)
+	if !ok {
+		return inbound
+	}
+	if m, ok := inbound.(unknownFieldsMarshaler); ok {
+		return m.withUnknownFields(!strict)
+	}
+	return inbound
+}
+
+func (j *JSONPb) withUnknownFields(allow bool) Marshaler {
+	return j.withOptions().withUnknownFields(allow)
+}
+
+func (j *JSONPbWithOptions) withUnknownFields(allow bool) Marshaler {
+	c := *j
+	c.opts.allowUnknown = &allow
+	return &c
+}
@@ -0,0 +1,96 @@
+package runtime_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+)
+
+func TestStrictDecodingParameter(t *testing.T) {
+	trusted := func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "trusted"
+	}
+	decode := func(mux *runtime.ServeMux, query string, trustedReq bool) (string, error) {
+		var decodeErr error
+		var gotQuery string
+		pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+		mux.Handle("POST", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			inbound, _ := runtime.MarshalerForRequest(mux, r)
+			var msg pb.SimpleMessage
+			decodeErr = inbound.NewDecoder(r.Body).Decode(&msg)
+			gotQuery = r.URL.RawQuery
+		})
+		r := httptest.NewRequest("POST", "http://example.com/foo"+query, strings.NewReader(`{"id": "foo", "unknown": 1}`))
+		if trustedReq {
+			r.Header.Set("Authorization", "trusted")
+		}
+		mux.ServeHTTP(httptest.NewRecorder(), r)
+		return gotQuery, decodeErr
+	}
+	// The default depends on whether DisallowUnknownFields was called.
+	_, defaultErr := decode(runtime.NewServeMux(), "", true)
+
+	for _, spec := range []struct {
+		name      string
+		param     string
+		query     string
+		untrusted bool
+
+		wantErr     bool
+		wantDefault bool
+		wantQuery   string
+	}{
+		{
+			name:    "strict",
+			query:   "?_strict=true",
+			wantErr: true,
+		},
+		{
+			name:  "lenient",
+			query: "?_strict=false&num=1",
+			// Other parameters are kept.
+			wantQuery: "num=1",
+		},
+		{
+			name:    "custom parameter",
+			param:   "strict_decoding",
+			query:   "?strict_decoding=1",
+			wantErr: true,
+		},
+		{
+			name:        "untrusted",
+			query:       "?_strict=true",
+			untrusted:   true,
+			wantDefault: true,
+		},
+		{
+			name:        "no parameter",
+			wantDefault: true,
+		},
+		{
+			name:        "invalid value",
+			query:       "?_strict=maybe",
+			wantDefault: true,
+		},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(runtime.WithStrictDecodingParameter(spec.param, trusted))
+			query, err := decode(mux, spec.query, !spec.untrusted)
+			wantErr := spec.wantErr
+			if spec.wantDefault {
+				wantErr = defaultErr != nil
+			}
+			if got := err != nil; got != wantErr {
+				t.Errorf("dec.Decode(&msg) failed = %t (%v); want %t", got, err, wantErr)
+			}
+			if query != spec.wantQuery {
+				t.Errorf("r.URL.RawQuery = %q; want %q", query, spec.wantQuery)
+			}
+		})
+	}
+}
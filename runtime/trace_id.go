@@ -0,0 +1,74 @@
+package runtime
+
+import (
+	"context"
+
+	"github.com/grpc-ecosystem/grpc-gateway/internal"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+)
+
+// TraceIDField is the field of error bodies which holds the trace ID with WithTraceIDExtractor.
+const TraceIDField = "trace_id"
+
+// TraceIDExtractorFunc returns the ID of the trace of the request with ctx, e.g. of its
+// OpenTelemetry span, or an empty string if it has none.
+type TraceIDExtractorFunc func(ctx context.Context) string
+
+// WithTraceIDExtractor returns a ServeMuxOption that adds the trace ID returned by fn for the
+// context of failed requests to the TraceIDField of their error bodies, so that support can find
+// the traces of the errors reported by clients. It is added at the top level of the bodies shaped
+// by WithErrorBodyTemplate. Error bodies are kept as is if fn returns an empty string, or if the
+// marshaler cannot marshal the error with the trace ID, like ProtoMarshaller.
+//
+// It applies to DefaultHTTPError and DefaultHTTPProtoErrorHandler.
+func WithTraceIDExtractor(fn TraceIDExtractorFunc) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.traceIDExtractor = fn
+	}
+}
+
+// marshalErrorBody marshals body, the body of an error response, with the trace ID of ctx if s
+// has a trace ID extractor.
+func (s *ServeMux) marshalErrorBody(ctx context.Context, marshaler Marshaler, body interface{}) ([]byte, error) {
+	if s.traceIDExtractor == nil {
+		return marshaler.Marshal(body)
+	}
+	id := s.traceIDExtractor(ctx)
+	obj := errorBodyObject(body)
+	if id == "" || obj == nil {
+		return marshaler.Marshal(body)
+	}
+	obj[TraceIDField] = id
+	if buf, err := marshaler.Marshal(obj); err == nil {
+		return buf, nil
+	}
+	return marshaler.Marshal(body)
+}
+
+// errorBodyObject returns the fields of body, the body of an error response, as an object, or
+// nil if body is not an error body.
+func errorBodyObject(body interface{}) map[string]interface{} {
+	obj := make(map[string]interface{})
+	switch b := body.(type) {
+	case map[string]interface{}:
+		for k, v := range b {
+			obj[k] = v
+		}
+	case *internal.Error:
+		obj["error"] = b.Error
+		obj["code"] = b.Code
+		obj["message"] = b.Message
+		if len(b.Details) > 0 {
+			obj["details"] = b.Details
+		}
+	case *spb.Status:
+		obj["code"] = b.Code
+		obj["message"] = b.Message
+		if len(b.Details) > 0 {
+			obj["details"] = b.Details
+		}
+	default:
+		return nil
+	}
+	return obj
+}
@@ -0,0 +1,121 @@
+package runtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type traceIDKey struct{}
+
+func traceIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
+
+func TestWithTraceIDExtractor(t *testing.T) {
+	st, _ := status.New(codes.NotFound, "not found").WithDetails(&errdetails.ResourceInfo{ResourceName: "books/1"})
+	for _, spec := range []struct {
+		name    string
+		opts    []runtime.ServeMuxOption
+		traceID string
+		// wantTraceID is the trace ID expected at the top level of the body, if any.
+		wantTraceID string
+	}{
+		{
+			name:        "trace ID",
+			opts:        []runtime.ServeMuxOption{runtime.WithTraceIDExtractor(traceIDFromContext)},
+			traceID:     "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name: "body template",
+			opts: []runtime.ServeMuxOption{
+				runtime.WithTraceIDExtractor(traceIDFromContext),
+				runtime.WithErrorBodyTemplate(runtime.ErrorBodyTemplate{Key: "error"}),
+			},
+			traceID:     "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{
+			name: "no trace",
+			opts: []runtime.ServeMuxOption{runtime.WithTraceIDExtractor(traceIDFromContext)},
+		},
+		{
+			name:    "no extractor",
+			traceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+	} {
+		for _, handler := range []struct {
+			name string
+			fn   runtime.ProtoErrorHandlerFunc
+		}{
+			{name: "DefaultHTTPError", fn: runtime.DefaultHTTPError},
+			{name: "DefaultHTTPProtoErrorHandler", fn: runtime.DefaultHTTPProtoErrorHandler},
+		} {
+			t.Run(spec.name+"/"+handler.name, func(t *testing.T) {
+				ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+				if spec.traceID != "" {
+					ctx = context.WithValue(ctx, traceIDKey{}, spec.traceID)
+				}
+				w := httptest.NewRecorder()
+				req, _ := http.NewRequest("", "", nil)
+				mux := runtime.NewServeMux(spec.opts...)
+				handler.fn(ctx, mux, &runtime.JSONPb{}, w, req, st.Err())
+
+				if got, want := w.Code, http.StatusNotFound; got != want {
+					t.Errorf("w.Code = %d; want %d", got, want)
+				}
+				var body map[string]interface{}
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+					t.Fatalf("json.Unmarshal(%q, &body) failed with %v; want success", w.Body, err)
+				}
+				id, ok := body["trace_id"]
+				if spec.wantTraceID == "" {
+					if ok {
+						t.Errorf("body[%q] = %v; want no trace ID", "trace_id", id)
+					}
+				} else if id != spec.wantTraceID {
+					t.Errorf("body[%q] = %v; want %q", "trace_id", id, spec.wantTraceID)
+				}
+				errObj := body
+				if inner, ok := body["error"].(map[string]interface{}); ok {
+					errObj = inner
+				}
+				if got, want := errObj["message"], "not found"; got != want {
+					t.Errorf("message = %v; want %q; body=%s", got, want, w.Body)
+				}
+				if details, _ := errObj["details"].([]interface{}); len(details) != 1 {
+					t.Errorf("details = %v; want one detail; body=%s", errObj["details"], w.Body)
+				}
+			})
+		}
+	}
+}
+
+func TestWithTraceIDExtractorProtoMarshaller(t *testing.T) {
+	ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{})
+	ctx = context.WithValue(ctx, traceIDKey{}, "4bf92f3577b34da6a3ce929d0e0e4736")
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("", "", nil)
+	mux := runtime.NewServeMux(runtime.WithTraceIDExtractor(traceIDFromContext))
+	runtime.DefaultHTTPProtoErrorHandler(ctx, mux, &runtime.ProtoMarshaller{}, w, req, status.Error(codes.NotFound, "not found"))
+
+	// The marshaler cannot marshal the trace ID, so the error is kept as is.
+	var got spb.Status
+	if err := proto.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("proto.Unmarshal(%q, &got) failed with %v; want success", w.Body, err)
+	}
+	if want := status.New(codes.NotFound, "not found").Proto(); !proto.Equal(&got, want) {
+		t.Errorf("got = %v; want %v", &got, want)
+	}
+}
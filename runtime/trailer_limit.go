@@ -0,0 +1,55 @@
+package runtime
+
+import (
+	"sort"
+
+	"google.golang.org/grpc/grpclog"
+	"google.golang.org/grpc/metadata"
+)
+
+// WithMaxTrailerSize returns a ServeMuxOption that limits the trailers forwarded from the gRPC
+// trailer metadata to n bytes, counting the names, with MetadataTrailerPrefix, and the values of
+// the trailers. The trailers are kept in the order of their names as long as they fit in the
+// limit; the others are dropped and a warning is logged, so that a backend returning large
+// trailer sets does not bloat the responses. The limit of 0, the default, means no limit.
+func WithMaxTrailerSize(n int) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		serveMux.maxTrailerSize = n
+	}
+}
+
+// limitTrailers returns md with the trailers which fit in the limit set by WithMaxTrailerSize.
+func (s *ServeMux) limitTrailers(md ServerMetadata) ServerMetadata {
+	if s.maxTrailerSize <= 0 || len(md.TrailerMD) == 0 {
+		return md
+	}
+
+	keys := make([]string, 0, len(md.TrailerMD))
+	for k := range md.TrailerMD {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	size := 0
+	kept := make(metadata.MD, len(md.TrailerMD))
+	var dropped []string
+	for _, k := range keys {
+		n := len(MetadataTrailerPrefix) + len(k)
+		for _, v := range md.TrailerMD[k] {
+			n += len(v)
+		}
+		if size+n > s.maxTrailerSize {
+			dropped = append(dropped, k)
+			continue
+		}
+		size += n
+		kept[k] = md.TrailerMD[k]
+	}
+	if len(dropped) == 0 {
+		return md
+	}
+
+	grpclog.Warningf("Dropped trailers %v beyond the limit of %d bytes", dropped, s.maxTrailerSize)
+	md.TrailerMD = kept
+	return md
+}
@@ -0,0 +1,72 @@
+package runtime_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestWithMaxTrailerSize(t *testing.T) {
+	// With the prefix, the trailers take 23, 43 and 15 bytes.
+	md := metadata.Pairs(
+		"a", strings.Repeat("x", 9),
+		"b", strings.Repeat("y", 29),
+		"c", "z",
+	)
+	for _, tt := range []struct {
+		name  string
+		limit int
+		want  []string
+	}{
+		{
+			name: "no limit",
+			want: []string{"Grpc-Trailer-A", "Grpc-Trailer-B", "Grpc-Trailer-C"},
+		},
+		{
+			name:  "oversized trailers dropped",
+			limit: 40,
+			want:  []string{"Grpc-Trailer-A", "Grpc-Trailer-C"},
+		},
+		{
+			name:  "all trailers fit",
+			limit: 81,
+			want:  []string{"Grpc-Trailer-A", "Grpc-Trailer-B", "Grpc-Trailer-C"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := runtime.NewServeMux(runtime.WithMaxTrailerSize(tt.limit))
+			ctx := runtime.NewServerMetadataContext(context.Background(), runtime.ServerMetadata{TrailerMD: md})
+			req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+			resp := httptest.NewRecorder()
+			runtime.ForwardResponseMessage(ctx, mux, &runtime.JSONPb{}, resp, req, &pb.SimpleMessage{Id: "foo"})
+
+			w := resp.Result()
+			if _, err := ioutil.ReadAll(w.Body); err != nil {
+				t.Fatalf("Failed to read response body with %v", err)
+			}
+			w.Body.Close()
+
+			announced := append([]string(nil), w.Header["Trailer"]...)
+			sort.Strings(announced)
+			if !reflect.DeepEqual(announced, tt.want) {
+				t.Errorf("Trailer header = %q; want %q", announced, tt.want)
+			}
+			var got []string
+			for k := range w.Trailer {
+				got = append(got, k)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("trailers = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package runtime
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// unmarshalFallback is a fallback registered by WithUnmarshalFallback.
+type unmarshalFallback struct {
+	primary  Marshaler
+	fallback Marshaler
+}
+
+// WithUnmarshalFallback returns a ServeMuxOption that retries the unmarshaling of request bodies
+// with fallback when the inbound marshaler returned by MarshalerForRequest is primary and fails,
+// e.g. to accept form bodies on routes which expect JSON. The error of primary is returned if
+// fallback fails too. Fallbacks can be chained, by registering a fallback for fallback.
+//
+// Bodies have to be buffered in memory to be read again by fallback. For client streams, only the
+// first message is retried, and the rest of the stream is then decoded by the first marshaler
+// which succeeded. primary and fallback are compared with ==, so they are typically pointers.
+func WithUnmarshalFallback(primary, fallback Marshaler) ServeMuxOption {
+	return func(mux *ServeMux) {
+		mux.unmarshalFallbacks = append(mux.unmarshalFallbacks, unmarshalFallback{primary: primary, fallback: fallback})
+	}
+}
+
+// withUnmarshalFallback returns inbound, derived from the selected marshaler, retried with the
+// chain of fallbacks registered for selected, if any.
+func (s *ServeMux) withUnmarshalFallback(selected, inbound Marshaler) Marshaler {
+	var fallbacks []Marshaler
+	cur := selected
+	// Each fallback is used once at most, which also stops cyclic chains.
+	for range s.unmarshalFallbacks {
+		next, ok := s.unmarshalFallbackFor(cur)
+		if !ok {
+			break
+		}
+		fallbacks = append(fallbacks, next)
+		cur = next
+	}
+	if len(fallbacks) == 0 {
+		return inbound
+	}
+	return &fallbackMarshaler{Marshaler: inbound, fallbacks: fallbacks}
+}
+
+// unmarshalFallbackFor returns the fallback registered for m. The last registered one wins.
+func (s *ServeMux) unmarshalFallbackFor(m Marshaler) (Marshaler, bool) {
+	for i := len(s.unmarshalFallbacks) - 1; i >= 0; i-- {
+		if f := s.unmarshalFallbacks[i]; f.primary == m {
+			return f.fallback, true
+		}
+	}
+	return nil, false
+}
+
+// fallbackMarshaler is an inbound Marshaler which retries failed unmarshalings with fallbacks.
+type fallbackMarshaler struct {
+	Marshaler
+	fallbacks []Marshaler
+}
+
+// Unmarshal unmarshals data with the wrapped Marshaler, or else with the first fallback which
+// succeeds.
+func (m *fallbackMarshaler) Unmarshal(data []byte, v interface{}) error {
+	err := m.Marshaler.Unmarshal(data, v)
+	if err == nil {
+		return nil
+	}
+	for _, fallback := range m.fallbacks {
+		resetMessage(v)
+		if fallback.Unmarshal(data, v) == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// NewDecoder returns a Decoder which buffers r to decode it again with the fallbacks.
+func (m *fallbackMarshaler) NewDecoder(r io.Reader) Decoder {
+	return &fallbackDecoder{m: m, r: r}
+}
+
+type fallbackDecoder struct {
+	m *fallbackMarshaler
+	r io.Reader
+	// dec is the decoder of the marshaler which decoded the first message.
+	dec Decoder
+}
+
+// Decode decodes v. The first call reads the whole body, and decodes it with the first
+// marshaler which succeeds.
+func (d *fallbackDecoder) Decode(v interface{}) error {
+	if d.dec != nil {
+		return d.dec.Decode(v)
+	}
+	data, err := ioutil.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+	d.dec = d.m.Marshaler.NewDecoder(bytes.NewReader(data))
+	err = d.dec.Decode(v)
+	if err == nil || err == io.EOF {
+		return err
+	}
+	for _, fallback := range d.m.fallbacks {
+		resetMessage(v)
+		dec := fallback.NewDecoder(bytes.NewReader(data))
+		if dec.Decode(v) == nil {
+			d.dec = dec
+			return nil
+		}
+	}
+	return err
+}
+
+// resetMessage clears what a failed unmarshaling may have set in v.
+func resetMessage(v interface{}) {
+	if msg, ok := v.(proto.Message); ok {
+		msg.Reset()
+	}
+}
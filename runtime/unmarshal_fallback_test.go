@@ -0,0 +1,95 @@
+package runtime_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	pb "github.com/grpc-ecosystem/grpc-gateway/runtime/internal/examplepb"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+)
+
+// formMarshaler unmarshals form bodies, like query parameters.
+type formMarshaler struct {
+	runtime.JSONPb
+}
+
+func (*formMarshaler) Unmarshal(data []byte, v interface{}) error {
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	return runtime.PopulateQueryParameters(v.(proto.Message), values, utilities.NewDoubleArray(nil))
+}
+
+func (m *formMarshaler) NewDecoder(r io.Reader) runtime.Decoder {
+	return runtime.DecoderFunc(func(v interface{}) error {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		return m.Unmarshal(data, v)
+	})
+}
+
+func TestWithUnmarshalFallback(t *testing.T) {
+	pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpLitPush), 0}, []string{"foo"}, ""))
+	json := &runtime.JSONPb{}
+	for _, spec := range []struct {
+		name     string
+		body     string
+		fallback bool
+		// decoder is true if the body is decoded with a Decoder rather than unmarshaled.
+		decoder bool
+
+		wantStatus int
+		wantID     string
+	}{
+		{name: "primary", body: `{"id":"foo"}`, fallback: true, wantStatus: http.StatusOK, wantID: "foo"},
+		{name: "fallback", body: "id=foo", fallback: true, wantStatus: http.StatusOK, wantID: "foo"},
+		{name: "fallback with decoder", body: "id=foo", fallback: true, decoder: true, wantStatus: http.StatusOK, wantID: "foo"},
+		{name: "both fail", body: "id=%zz", fallback: true, wantStatus: http.StatusBadRequest},
+		{name: "no fallback", body: "id=foo", wantStatus: http.StatusBadRequest},
+	} {
+		t.Run(spec.name, func(t *testing.T) {
+			opts := []runtime.ServeMuxOption{runtime.WithMarshalerOption(runtime.MIMEWildcard, json)}
+			if spec.fallback {
+				opts = append(opts, runtime.WithUnmarshalFallback(json, &formMarshaler{}))
+			}
+			mux := runtime.NewServeMux(opts...)
+			var got pb.SimpleMessage
+			mux.Handle("POST", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+				inbound, _ := runtime.MarshalerForRequest(mux, r)
+				var err error
+				if spec.decoder {
+					err = inbound.NewDecoder(r.Body).Decode(&got)
+				} else {
+					var data []byte
+					if data, err = ioutil.ReadAll(r.Body); err == nil {
+						err = inbound.Unmarshal(data, &got)
+					}
+				}
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+				}
+			})
+
+			r := httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader(spec.body))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+
+			if gotStatus, want := w.Code, spec.wantStatus; gotStatus != want {
+				t.Fatalf("w.Code = %d; want %d", gotStatus, want)
+			}
+			if spec.wantStatus == http.StatusOK && got.Id != spec.wantID {
+				t.Errorf("got.Id = %q; want %q", got.Id, spec.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package runtime
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"google.golang.org/grpc/grpclog"
+)
+
+// VersionInfo describes the build of a gateway, as served by the endpoint registered with
+// WithVersionEndpoint. Its fields are typically set at link time, with -ldflags "-X ...".
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+}
+
+// WithVersionEndpoint returns a ServeMuxOption that serves info as JSON at path, for GET requests,
+// so that operators can query the version of a running gateway. The endpoint is served before
+// any registered pattern.
+func WithVersionEndpoint(path string, info VersionInfo) ServeMuxOption {
+	return func(serveMux *ServeMux) {
+		body, err := json.Marshal(info)
+		if err != nil {
+			grpclog.Errorf("Failed to marshal version info: %v", err)
+			return
+		}
+		serveMux.handleBuiltin(path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(body)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package runtime_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
+)
+
+func TestVersionEndpoint(t *testing.T) {
+	info := runtime.VersionInfo{
+		Version:   "v1.2.3",
+		Commit:    "0123abc",
+		BuildTime: "2020-06-01T12:00:00Z",
+	}
+	mux := runtime.NewServeMux(runtime.WithVersionEndpoint("/version", info))
+	// The endpoint takes precedence over the registered patterns.
+	pat := runtime.MustPattern(runtime.NewPattern(1, []int{int(utilities.OpPush), 0}, nil, ""))
+	mux.Handle("GET", pat, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	r := httptest.NewRequest("GET", "http://example.com/version", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+
+	if got, want := w.Code, http.StatusOK; got != want {
+		t.Fatalf("w.Code = %d; want %d", got, want)
+	}
+	if got, want := w.Header().Get("Content-Type"), "application/json"; got != want {
+		t.Errorf("Content-Type = %q; want %q", got, want)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("json.Unmarshal(%q, &body) failed with %v; want success", w.Body.String(), err)
+	}
+	for key, want := range map[string]string{
+		"version":   info.Version,
+		"commit":    info.Commit,
+		"buildTime": info.BuildTime,
+	} {
+		if got := body[key]; got != want {
+			t.Errorf("body[%q] = %q; want %q", key, got, want)
+		}
+	}
+}